@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+// checkpointMode selects how a scan uses the checkpoint
+type checkpointMode int
+
+const (
+	checkpointNone   checkpointMode = iota // Scheduled scans: the checkpoint is neither read nor written
+	checkpointRecord                       // Record progress for a later --resume, discarding an older checkpoint
+	checkpointResume                       // Skip files an interrupted scan finished, and keep recording
+)
+
+// checkpointPath returns where scans record their progress: next to the cache
+// database, in a file named after the config file and profile so profiles
+// sharing cache.path keep separate checkpoints
+func checkpointPath(cfg *config.Config) string {
+	source := "env"
+	if !*envConfig {
+		source = *configPath
+		if abs, err := filepath.Abs(source); err == nil {
+			source = abs
+		}
+	}
+	sum := sha256.Sum256([]byte(source + "\x00" + *profile))
+	return filepath.Join(filepath.Dir(cfg.Cache.Path), "scan-checkpoint-"+hex.EncodeToString(sum[:6])+".txt")
+}
+
+// loadScanCheckpoint returns the video paths recorded in the checkpoint at
+// path. A missing file is an empty checkpoint.
+func loadScanCheckpoint(path string) (map[string]bool, error) {
+	done := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		if line := lines.Text(); line != "" {
+			done[line] = true
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scan checkpoint: %w", err)
+	}
+	return done, nil
+}
+
+// scanCheckpoint appends the path of each video a scan has finished, one per
+// line, so an interrupted scan can be resumed with --resume. Methods are safe
+// for concurrent use and on a nil *scanCheckpoint.
+type scanCheckpoint struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// openScanCheckpoint opens the checkpoint at path for appending. Unless
+// resuming, any previous checkpoint is discarded first.
+func openScanCheckpoint(path string, resume bool) (*scanCheckpoint, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scan checkpoint directory: %w", err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan checkpoint: %w", err)
+	}
+	return &scanCheckpoint{path: path, file: f}, nil
+}
+
+// Add records videoPath as finished
+func (c *scanCheckpoint) Add(videoPath string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return
+	}
+	if _, err := fmt.Fprintln(c.file, videoPath); err != nil {
+		slog.Warn("failed to update scan checkpoint", "path", c.path, "error", err)
+	}
+}
+
+// Close stops recording and keeps the checkpoint for a later --resume
+func (c *scanCheckpoint) Close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file != nil {
+		c.file.Close()
+		c.file = nil
+	}
+}
+
+// Clear stops recording and removes the checkpoint once a scan has completed
+func (c *scanCheckpoint) Clear() {
+	c.Close()
+	if c == nil {
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove scan checkpoint", "path", c.path, "error", err)
+	}
+}
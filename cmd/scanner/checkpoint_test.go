@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+func TestScanCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "scan-checkpoint.txt")
+
+	// An interrupted scan keeps what it recorded
+	checkpoint, err := openScanCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("openScanCheckpoint failed: %v", err)
+	}
+	checkpoint.Add("/movies/Heat.1995.mkv")
+	checkpoint.Add("/movies/Alien.1979.mkv")
+	checkpoint.Close()
+
+	// Resuming appends to it
+	checkpoint, err = openScanCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("openScanCheckpoint(resume) failed: %v", err)
+	}
+	checkpoint.Add("/movies/Ran.1985.mkv")
+	checkpoint.Close()
+
+	done, err := loadScanCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadScanCheckpoint failed: %v", err)
+	}
+	for _, p := range []string{"/movies/Heat.1995.mkv", "/movies/Alien.1979.mkv", "/movies/Ran.1985.mkv"} {
+		if !done[p] {
+			t.Errorf("expected %s in checkpoint, got %v", p, done)
+		}
+	}
+
+	// A fresh scan starts over, and completing it removes the file
+	checkpoint, err = openScanCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("openScanCheckpoint failed: %v", err)
+	}
+	if done, _ := loadScanCheckpoint(path); len(done) != 0 {
+		t.Errorf("fresh scan kept old entries: %v", done)
+	}
+	checkpoint.Clear()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after Clear: %v", err)
+	}
+
+	// A missing checkpoint and a nil one are both harmless
+	if done, err := loadScanCheckpoint(path); err != nil || len(done) != 0 {
+		t.Errorf("loadScanCheckpoint(missing) = %v, %v", done, err)
+	}
+	var none *scanCheckpoint
+	none.Add("/movies/Heat.1995.mkv")
+	none.Clear()
+}
+
+func TestCheckpointPath(t *testing.T) {
+	defer func(path, name string) { *configPath, *profile = path, name }(*configPath, *profile)
+
+	cfg := &config.Config{}
+	cfg.Cache.Path = "/data/cache.db"
+
+	paths := make(map[string]bool)
+	for _, tc := range []struct{ config, profile string }{
+		{"/etc/movievault/config.yaml", ""},
+		{"/etc/movievault/config.yaml", "kids"},
+		{"/etc/movievault/other.yaml", ""},
+	} {
+		*configPath, *profile = tc.config, tc.profile
+		path := checkpointPath(cfg)
+		if filepath.Dir(path) != "/data" {
+			t.Errorf("checkpointPath(%s, %q) = %s, want it next to the cache", tc.config, tc.profile, path)
+		}
+		if paths[path] {
+			t.Errorf("checkpointPath(%s, %q) = %s, shared with another config", tc.config, tc.profile, path)
+		}
+		paths[path] = true
+
+		if again := checkpointPath(cfg); again != path {
+			t.Errorf("checkpointPath is not stable: %s, then %s", path, again)
+		}
+	}
+}
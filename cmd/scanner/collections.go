@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// collectionSlug returns the URL slug for a collection name, or "" when the
+// movie is not part of one. Collections have no year, so the slug is the name alone.
+func collectionSlug(name string) string {
+	if name == "" {
+		return ""
+	}
+	return scanner.GenerateSlug(name, 0)
+}
+
+// movieCollection groups the movies sharing a collection slug
+type movieCollection struct {
+	Name    string
+	Slug    string
+	Members []string // Movie slugs, ordered by release year then slug
+}
+
+// groupCollections builds the collection list from parsed MDX movies, sorted
+// by collection name. Movies without a collection are ignored.
+func groupCollections(movies []*writer.Movie) []movieCollection {
+	type member struct {
+		slug string
+		year int
+	}
+	names := make(map[string]string)
+	members := make(map[string][]member)
+	for _, movie := range movies {
+		slug := movie.CollectionSlug
+		if slug == "" {
+			slug = collectionSlug(movie.Collection)
+		}
+		if slug == "" {
+			continue
+		}
+		if _, ok := names[slug]; !ok {
+			names[slug] = movie.Collection
+		}
+		members[slug] = append(members[slug], member{slug: movie.Slug, year: movie.ReleaseYear})
+	}
+
+	collections := make([]movieCollection, 0, len(members))
+	for slug, list := range members {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].year != list[j].year {
+				return list[i].year < list[j].year
+			}
+			return list[i].slug < list[j].slug
+		})
+		collection := movieCollection{Name: names[slug], Slug: slug}
+		for _, m := range list {
+			collection.Members = append(collection.Members, m.slug)
+		}
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Slug < collections[j].Slug
+	})
+	return collections
+}
+
+// runCollectionsReport prints each collection found in the MDX directory with
+// its member slugs. Returns the process exit code.
+func runCollectionsReport(mdxDir string, out io.Writer) int {
+	mdxFiles, err := filepath.Glob(filepath.Join(mdxDir, "*.mdx"))
+	if err != nil {
+		fmt.Fprintf(out, "Error: failed to list MDX files: %v\n", err)
+		return 1
+	}
+
+	movies := make([]*writer.Movie, 0, len(mdxFiles))
+	for _, mdxPath := range mdxFiles {
+		movie, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			fmt.Fprintf(out, "Warning: Failed to parse %s: %v\n", mdxPath, err)
+			continue
+		}
+		movies = append(movies, movie)
+	}
+
+	collections := groupCollections(movies)
+	for _, collection := range collections {
+		fmt.Fprintf(out, "%s (%s) - %d movies\n", collection.Name, collection.Slug, len(collection.Members))
+		for _, slug := range collection.Members {
+			fmt.Fprintf(out, "  %s\n", slug)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d collections across %d movies\n", len(collections), len(movies))
+	return 0
+}
+
+// collectionFetcher looks up a TMDB collection and its member movies
+type collectionFetcher interface {
+	GetCollection(collectionID int) (*metadata.TMDBCollection, error)
+}
+
+// runMissingFromCollection prints the members of a TMDB collection that have
+// no MDX in mdxDir, matched by TMDB ID, with links to acquire them. Members
+// are listed by release date; unreleased ones without a date come last.
+// Returns the process exit code.
+func runMissingFromCollection(fetcher collectionFetcher, mdxDir string, collectionID int, out io.Writer) int {
+	collection, err := fetcher.GetCollection(collectionID)
+	if err != nil {
+		fmt.Fprintf(out, "Error: failed to fetch collection %d: %v\n", collectionID, err)
+		return 1
+	}
+
+	mdxFiles, err := filepath.Glob(filepath.Join(mdxDir, "*.mdx"))
+	if err != nil {
+		fmt.Fprintf(out, "Error: failed to list MDX files: %v\n", err)
+		return 1
+	}
+	owned := make(map[int]bool, len(mdxFiles))
+	for _, mdxPath := range mdxFiles {
+		movie, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			fmt.Fprintf(out, "Warning: Failed to parse %s: %v\n", mdxPath, err)
+			continue
+		}
+		if movie.TMDBID > 0 && !movie.Unmatched {
+			owned[movie.TMDBID] = true
+		}
+	}
+
+	var missing []metadata.TMDBMovie
+	for _, part := range collection.Parts {
+		if !owned[part.ID] {
+			missing = append(missing, part)
+		}
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		a, b := missing[i].ReleaseDate, missing[j].ReleaseDate
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+
+	fmt.Fprintf(out, "%s: %d of %d movies in the library\n", collection.Name, len(collection.Parts)-len(missing), len(collection.Parts))
+	if len(missing) == 0 {
+		return 0
+	}
+	fmt.Fprintf(out, "\nMissing:\n")
+	for _, part := range missing {
+		year := "TBA"
+		if len(part.ReleaseDate) >= 4 {
+			year = part.ReleaseDate[:4]
+		}
+		fmt.Fprintf(out, "  %s (%s) https://www.themoviedb.org/movie/%d\n", part.Title, year, part.ID)
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestCollectionSlug(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"", ""},
+		{"Alien Collection", "alien-collection"},
+		{"The Lord of the Rings: Collection", "the-lord-of-the-rings-collection"},
+		{"2001 Collection", "2001-collection"},
+	}
+
+	for _, tc := range testCases {
+		if got := collectionSlug(tc.name); got != tc.expected {
+			t.Errorf("collectionSlug(%q) = %q, want %q", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestRunCollectionsReport(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	movies := []*writer.Movie{
+		{Title: "Aliens", Slug: "aliens-1986", ReleaseYear: 1986, Collection: "Alien Collection", CollectionSlug: "alien-collection"},
+		{Title: "Alien", Slug: "alien-1979", ReleaseYear: 1979, Collection: "Alien Collection", CollectionSlug: "alien-collection"},
+		{Title: "Heat", Slug: "heat-1995", ReleaseYear: 1995},
+		{Title: "Toy Story", Slug: "toy-story-1995", ReleaseYear: 1995, Collection: "Toy Story Collection"},
+	}
+	for _, movie := range movies {
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile failed: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := runCollectionsReport(dir, &out); code != 0 {
+		t.Fatalf("runCollectionsReport exit code = %d, output:\n%s", code, out.String())
+	}
+
+	expected := `Alien Collection (alien-collection) - 2 movies
+  alien-1979
+  aliens-1986
+Toy Story Collection (toy-story-collection) - 1 movies
+  toy-story-1995
+
+2 collections across 4 movies
+`
+	if out.String() != expected {
+		t.Errorf("report =\n%s\nwant\n%s", out.String(), expected)
+	}
+}
+
+// fakeCollectionFetcher returns a fixed collection
+type fakeCollectionFetcher struct {
+	collection *metadata.TMDBCollection
+}
+
+func (f *fakeCollectionFetcher) GetCollection(collectionID int) (*metadata.TMDBCollection, error) {
+	if f.collection == nil || f.collection.ID != collectionID {
+		return nil, fmt.Errorf("TMDB API error (status 404): collection %d not found", collectionID)
+	}
+	return f.collection, nil
+}
+
+func TestRunMissingFromCollection(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	for _, movie := range []*writer.Movie{
+		{Title: "Alien", Slug: "alien-1979", ReleaseYear: 1979, TMDBID: 348},
+		{Title: "Aliens", Slug: "aliens-1986", ReleaseYear: 1986, TMDBID: 679},
+		{Title: "Alien 3", Slug: "alien-3-1992", ReleaseYear: 1992, TMDBID: 8077, Unmatched: true},
+	} {
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile failed: %v", err)
+		}
+	}
+	fetcher := &fakeCollectionFetcher{collection: &metadata.TMDBCollection{
+		ID:   8091,
+		Name: "Alien Collection",
+		Parts: []metadata.TMDBMovie{
+			{ID: 679, Title: "Aliens", ReleaseDate: "1986-07-18"},
+			{ID: 999, Title: "Alien: Sequel", ReleaseDate: ""},
+			{ID: 8078, Title: "Alien Resurrection", ReleaseDate: "1997-11-12"},
+			{ID: 348, Title: "Alien", ReleaseDate: "1979-05-25"},
+			{ID: 8077, Title: "Alien³", ReleaseDate: "1992-05-22"},
+		},
+	}}
+
+	var out bytes.Buffer
+	if code := runMissingFromCollection(fetcher, dir, 8091, &out); code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out.String())
+	}
+	expected := `Alien Collection: 2 of 5 movies in the library
+
+Missing:
+  Alien³ (1992) https://www.themoviedb.org/movie/8077
+  Alien Resurrection (1997) https://www.themoviedb.org/movie/8078
+  Alien: Sequel (TBA) https://www.themoviedb.org/movie/999
+`
+	if out.String() != expected {
+		t.Errorf("report =\n%s\nwant\n%s", out.String(), expected)
+	}
+
+	out.Reset()
+	if code := runMissingFromCollection(fetcher, dir, 1, &out); code != 1 {
+		t.Errorf("exit code for an unknown collection = %d, want 1", code)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// defaultVerifyThreshold is used by --verify when match_confidence_threshold is disabled
+const defaultVerifyThreshold = 0.5
+
+// matchConfidence scores a resolved movie against the title and year parsed
+// from its filename. The match is low confidence when the title similarity is
+// below threshold, or when both years are known and more than one year apart.
+func matchConfidence(fileTitle string, fileYear int, movie *writer.Movie, threshold float64) (score float64, low bool) {
+	score = scanner.TitleSimilarity(fileTitle, movie.Title)
+	yearOff := fileYear > 0 && movie.ReleaseYear > 0 && (fileYear-movie.ReleaseYear > 1 || movie.ReleaseYear-fileYear > 1)
+	return score, score < threshold || yearOff
+}
+
+// checkMatchConfidence warns about a TMDB search result that looks like the
+// wrong movie for file, and tags it when tag_low_confidence is enabled
+func checkMatchConfidence(cfg *config.Config, file scanner.FileInfo, movie *writer.Movie) {
+	threshold := cfg.Options.MatchConfidenceThreshold
+	if threshold <= 0 {
+		return
+	}
+	score, low := matchConfidence(file.Title, file.Year, movie, threshold)
+	if !low {
+		return
+	}
+	slog.Warn("low confidence match, check the result",
+		"file", file.FileName,
+		"filename_title", file.Title,
+		"filename_year", file.Year,
+		"matched_title", movie.Title,
+		"matched_year", movie.ReleaseYear,
+		"tmdb_id", movie.TMDBID,
+		"similarity", fmt.Sprintf("%.2f", score),
+	)
+	if cfg.Options.TagLowConfidence {
+		movie.LowConfidence = true
+	}
+}
+
+// runVerify lists MDX files whose movie looks unlike the video filename, or
+// that were tagged lowConfidence when written, for manual review.
+// Returns exit code: 0 on success, 1 on error
+func runVerify(mdxDir string, threshold float64, out io.Writer) int {
+	if threshold <= 0 {
+		threshold = defaultVerifyThreshold
+	}
+
+	mdxFiles, err := filepath.Glob(filepath.Join(mdxDir, "*.mdx"))
+	if err != nil {
+		fmt.Fprintf(out, "Error: failed to list MDX files: %v\n", err)
+		return 1
+	}
+
+	flagged := 0
+	for _, mdxPath := range mdxFiles {
+		movie, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			fmt.Fprintf(out, "Warning: Failed to parse %s: %v\n", mdxPath, err)
+			continue
+		}
+		if movie.Unmatched || movie.FileName == "" {
+			continue
+		}
+
+		fileTitle, fileYear := scanner.ExtractTitleAndYear(movie.FileName)
+		score, low := matchConfidence(fileTitle, fileYear, movie, threshold)
+		if !low && !movie.LowConfidence {
+			continue
+		}
+		flagged++
+		fmt.Fprintf(out, "%s\n  file:    %s (%s, %d)\n  matched: %s (%d), tmdb %d, similarity %.2f\n",
+			movie.Slug, movie.FileName, fileTitle, fileYear, movie.Title, movie.ReleaseYear, movie.TMDBID, score)
+	}
+
+	fmt.Fprintf(out, "\n%d of %d movies need review (similarity threshold %.2f)\n", flagged, len(mdxFiles), threshold)
+	return 0
+}
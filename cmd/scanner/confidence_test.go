@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestMatchConfidence(t *testing.T) {
+	testCases := []struct {
+		fileTitle string
+		fileYear  int
+		movie     writer.Movie
+		low       bool
+	}{
+		{"The Matrix", 1999, writer.Movie{Title: "The Matrix", ReleaseYear: 1999}, false},
+		{"Frozen", 2013, writer.Movie{Title: "Frozen", ReleaseYear: 2014}, false}, // festival vs. release year
+		{"Frozen", 2013, writer.Movie{Title: "Frozen", ReleaseYear: 2010}, true},
+		{"Frozen", 0, writer.Movie{Title: "Frozen", ReleaseYear: 2010}, false},
+		{"Heat", 1995, writer.Movie{Title: "Hot Pursuit", ReleaseYear: 1995}, true},
+	}
+
+	for _, tc := range testCases {
+		if _, low := matchConfidence(tc.fileTitle, tc.fileYear, &tc.movie, 0.5); low != tc.low {
+			t.Errorf("matchConfidence(%q, %d, %q %d) low = %v, want %v",
+				tc.fileTitle, tc.fileYear, tc.movie.Title, tc.movie.ReleaseYear, low, tc.low)
+		}
+	}
+}
+
+func TestRunVerify(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	movies := []*writer.Movie{
+		{Title: "The Matrix", Slug: "the-matrix-1999", ReleaseYear: 1999, FileName: "The.Matrix.1999.1080p.mkv"},
+		{Title: "Frozen", Slug: "frozen-2010", ReleaseYear: 2010, FileName: "Frozen.2013.mkv"},
+		{Title: "Hot Pursuit", Slug: "hot-pursuit-1995", ReleaseYear: 1995, FileName: "Heat.1995.mkv"},
+		{Title: "Alien", Slug: "alien-1979", ReleaseYear: 1979, FileName: "Alien.1979.mkv", LowConfidence: true},
+		{Title: "Obscure", Slug: "obscure-2003", ReleaseYear: 2003, FileName: "Something.Else.mkv", Unmatched: true},
+	}
+	for _, movie := range movies {
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile failed: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := runVerify(dir, 0, &out); code != 0 {
+		t.Fatalf("runVerify exit code = %d, output:\n%s", code, out.String())
+	}
+	report := out.String()
+	for _, slug := range []string{"frozen-2010", "hot-pursuit-1995", "alien-1979"} {
+		if !strings.Contains(report, slug+"\n") {
+			t.Errorf("report does not list %s:\n%s", slug, report)
+		}
+	}
+	for _, slug := range []string{"the-matrix-1999", "obscure-2003"} {
+		if strings.Contains(report, slug) {
+			t.Errorf("report lists %s:\n%s", slug, report)
+		}
+	}
+	if !strings.Contains(report, "3 of 5 movies need review") {
+		t.Errorf("unexpected summary:\n%s", report)
+	}
+}
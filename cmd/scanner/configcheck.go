@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/metadata"
+)
+
+// runConfigCheck loads and validates the configuration without scanning, then
+// prints an OK/error report. Missing scan directories are errors; unknown keys
+// and a missing website directory are warnings. With checkAPI, the TMDB
+// credentials are also verified against the live API.
+// Returns exit code: 0 if the config is usable, 1 otherwise
+func runConfigCheck(path, profile string, checkAPI bool, out io.Writer) int {
+	// Everything worth knowing is in the report; silence Load's own log output
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	resolved, err := config.ResolveProfile(path, profile)
+	if err != nil {
+		fmt.Fprintf(out, "  ✗ %v\n\nConfig check FAILED\n", err)
+		return 1
+	}
+	if resolved != path {
+		// A standalone <profile>.yaml is checked as a regular config file
+		path, profile = resolved, ""
+	}
+	if profile != "" {
+		fmt.Fprintf(out, "Checking config: %s (profile %s)\n\n", path, profile)
+	} else {
+		fmt.Fprintf(out, "Checking config: %s\n\n", path)
+	}
+
+	cfg, err := config.LoadProfile(path, profile)
+	if err != nil {
+		fmt.Fprintf(out, "  ✗ %v\n\nConfig check FAILED\n", err)
+		return 1
+	}
+	fmt.Fprintln(out, "  ✓ config parsed and validated")
+
+	errorCount := 0
+	warningCount := 0
+
+	if data, err := os.ReadFile(path); err == nil {
+		// Check the document Load decoded: shared settings with the profile merged in
+		merged, _ := config.ApplyProfile([]byte(os.ExpandEnv(string(data))), profile)
+		for _, field := range config.FindUnknownFields(merged) {
+			fmt.Fprintf(out, "  ! unknown key %q on line %d (ignored)\n", field.Key, field.Line)
+			warningCount++
+		}
+	}
+
+	for _, dir := range cfg.Scanner.Directories {
+		info, err := os.Stat(dir)
+		switch {
+		case err != nil:
+			fmt.Fprintf(out, "  ✗ scan directory not accessible: %s (%v)\n", dir, err)
+			errorCount++
+		case !info.IsDir():
+			fmt.Fprintf(out, "  ✗ scan directory is not a directory: %s\n", dir)
+			errorCount++
+		default:
+			fmt.Fprintf(out, "  ✓ scan directory: %s\n", dir)
+		}
+	}
+
+	if cfg.Output.AutoBuild {
+		if _, err := os.Stat(cfg.Output.WebsiteDir); err != nil {
+			fmt.Fprintf(out, "  ! auto_build is enabled but website_dir is not accessible: %s\n", cfg.Output.WebsiteDir)
+			warningCount++
+		}
+	}
+
+	if checkAPI {
+		tmdbClient := metadata.NewClientWithConfig(metadata.ClientConfig{
+			APIKey:                cfg.TMDB.APIKey,
+			AccessToken:           cfg.TMDB.AccessToken,
+			Language:              cfg.TMDB.Language,
+			MaxAttempts:           1,
+			InitialBackoffMs:      cfg.Retry.InitialBackoffMs,
+			UserAgent:             "movieVault/" + version,
+			Headers:               cfg.TMDB.Headers,
+			RequestTimeoutSeconds: cfg.TMDB.RequestTimeoutSeconds,
+			APIBaseURL:            cfg.TMDB.APIBaseURL,
+			ImageBaseURL:          cfg.TMDB.ImageBaseURL,
+		})
+		defer tmdbClient.Close()
+
+		if err := tmdbClient.CheckCredentials(); err != nil {
+			fmt.Fprintf(out, "  ✗ TMDB credentials: %v\n", err)
+			errorCount++
+		} else {
+			fmt.Fprintln(out, "  ✓ TMDB credentials accepted")
+		}
+	}
+
+	fmt.Fprintln(out)
+	if errorCount > 0 {
+		fmt.Fprintf(out, "Config check FAILED: %d error(s), %d warning(s)\n", errorCount, warningCount)
+		return 1
+	}
+	fmt.Fprintf(out, "Config check OK: %d warning(s)\n", warningCount)
+	return 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunConfigCheck(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	mediaDir := filepath.Join(dir, "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig := func(name, apiKey, scanDir, extra string) string {
+		path := filepath.Join(dir, name)
+		data := fmt.Sprintf(`tmdb:
+  api_key: %q
+  api_base_url: %q
+scanner:
+  directories:
+    - %q
+output:
+  mdx_dir: %q
+  covers_dir: %q
+%s`, apiKey, server.URL, scanDir, filepath.Join(dir, "movies"), filepath.Join(dir, "covers"), extra)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		checkAPI bool
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "valid",
+			path:     writeConfig("valid.yaml", "good-key", mediaDir, ""),
+			checkAPI: true,
+			wantCode: 0,
+			wantOut:  []string{"✓ config parsed and validated", "✓ scan directory: " + mediaDir, "✓ TMDB credentials accepted", "Config check OK: 0 warning(s)"},
+		},
+		{
+			name:     "unknown key is a warning",
+			path:     writeConfig("unknown.yaml", "good-key", mediaDir, "colour_scheme: dark\n"),
+			wantCode: 0,
+			wantOut:  []string{`! unknown key "colour_scheme"`, "Config check OK: 1 warning(s)"},
+		},
+		{
+			name:     "missing scan directory",
+			path:     writeConfig("missing-dir.yaml", "good-key", filepath.Join(dir, "nope"), ""),
+			wantCode: 1,
+			wantOut:  []string{"✗ scan directory not accessible", "Config check FAILED: 1 error(s)"},
+		},
+		{
+			name:     "rejected credentials",
+			path:     writeConfig("bad-key.yaml", "bad-key", mediaDir, ""),
+			checkAPI: true,
+			wantCode: 1,
+			wantOut:  []string{"✗ TMDB credentials: TMDB rejected the credentials (status 401)", "Config check FAILED: 1 error(s)"},
+		},
+		{
+			name:     "invalid config",
+			path:     writeConfig("no-key.yaml", "", mediaDir, ""),
+			wantCode: 1,
+			wantOut:  []string{"✗ ", "Config check FAILED\n"},
+		},
+		{
+			name:     "missing file",
+			path:     filepath.Join(dir, "absent.yaml"),
+			wantCode: 1,
+			wantOut:  []string{"Config check FAILED"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if code := runConfigCheck(tc.path, "", tc.checkAPI, &out); code != tc.wantCode {
+				t.Errorf("runConfigCheck() = %d, want %d; output:\n%s", code, tc.wantCode, out.String())
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// coverGroup is a set of byte-identical image files in the covers directory
+type coverGroup struct {
+	Paths  []string // Sorted; the first path is kept when hardlinking
+	Size   int64    // Size of one copy in bytes
+	Wasted int64    // Bytes used by copies that are not already hardlinks of each other
+}
+
+// findDuplicateCovers hashes every file under coversDir and returns the groups
+// of identical files, largest waste first. Files that are already hardlinks of
+// each other are grouped but not counted as wasted space.
+func findDuplicateCovers(coversDir string) ([]coverGroup, error) {
+	byHash := make(map[string][]string)
+	err := filepath.WalkDir(coversDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		sum, err := hashFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		byHash[sum] = append(byHash[sum], path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []coverGroup
+	for _, paths := range byHash {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+
+		var distinct []os.FileInfo
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			linked := false
+			for _, seen := range distinct {
+				if os.SameFile(seen, info) {
+					linked = true
+					break
+				}
+			}
+			if !linked {
+				distinct = append(distinct, info)
+			}
+		}
+
+		size := distinct[0].Size()
+		groups = append(groups, coverGroup{
+			Paths:  paths,
+			Size:   size,
+			Wasted: size * int64(len(distinct)-1),
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted != groups[j].Wasted {
+			return groups[i].Wasted > groups[j].Wasted
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups, nil
+}
+
+// hashFile returns the hex SHA-256 of the file at path
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// runDedupeCovers reports identical images in coversDir and, unless dryRun is
+// set, replaces every duplicate with a hardlink to the first copy so the bytes
+// are stored once. Cover paths in MDX files are unchanged.
+// Returns exit code: 0 on success, 1 if scanning or any link failed
+func runDedupeCovers(coversDir string, dryRun bool, out io.Writer) int {
+	groups, err := findDuplicateCovers(coversDir)
+	if err != nil {
+		slog.Error("failed to scan covers directory", "dir", coversDir, "error", err)
+		return 1
+	}
+
+	var wasted int64
+	for _, group := range groups {
+		wasted += group.Wasted
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "No duplicate covers found.")
+		return 0
+	}
+
+	fmt.Fprintf(out, "Found %d group(s) of identical images, %s reclaimable:\n\n", len(groups), writer.FormatFileSize(wasted))
+	for _, group := range groups {
+		fmt.Fprintf(out, "%d copies of %s (%s reclaimable)\n", len(group.Paths), writer.FormatFileSize(group.Size), writer.FormatFileSize(group.Wasted))
+		for _, path := range group.Paths {
+			fmt.Fprintf(out, "  %s\n", path)
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintln(out, "\nDRY RUN MODE - No actual changes will be made")
+		return 0
+	}
+
+	var linked, failed int
+	for _, group := range groups {
+		if group.Wasted == 0 {
+			continue
+		}
+		keep := group.Paths[0]
+		for _, path := range group.Paths[1:] {
+			if err := replaceWithHardlink(keep, path); err != nil {
+				slog.Error("failed to hardlink cover", "file", path, "target", keep, "error", err)
+				failed++
+				continue
+			}
+			linked++
+		}
+	}
+
+	slog.Info("cover deduplication complete", "linked", linked, "failed", failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// replaceWithHardlink atomically replaces path with a hardlink to target.
+// Paths that already share target's inode are left alone.
+func replaceWithHardlink(target, path string) error {
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && os.SameFile(targetInfo, info) {
+		return nil
+	}
+
+	tmp := path + ".link"
+	os.Remove(tmp)
+	if err := os.Link(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// existingImage returns the path of a non-empty copy of an image that is
+// already on disk: the file in covers_dir, or the file the website serves for
+// sitePath from its public directory (e.g. covers committed to the site repo).
+// Returns "" when the image still has to be downloaded.
+func existingImage(cfg *config.Config, absPath, sitePath string) string {
+	candidates := []string{absPath}
+	if cfg.Output.WebsiteDir != "" && sitePath != "" {
+		candidates = append(candidates, filepath.Join(cfg.Output.WebsiteDir, "public", filepath.FromSlash(sitePath)))
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			return path
+		}
+	}
+	return ""
+}
+
+// staleArtwork reports whether the images already stored under movie's slug
+// may belong to another movie: the MDX about to be replaced names a different
+// TMDB entry (or IMDb entry, when neither has a TMDB ID). This happens when a
+// rescan resolves the slug differently, e.g. after a blocklist fix. Images
+// without an MDX are assumed to match.
+func staleArtwork(mdxWriter *writer.MDXWriter, movie *writer.Movie) bool {
+	if !mdxWriter.MDXExists(movie.Slug) {
+		return false
+	}
+	existing, err := writer.ReadMDXFile(mdxWriter.GetMDXPath(movie.Slug))
+	if err != nil || existing.Unmatched {
+		return true
+	}
+	var stale bool
+	if existing.TMDBID != 0 || movie.TMDBID != 0 {
+		stale = existing.TMDBID != movie.TMDBID
+	} else {
+		stale = existing.IMDbID != movie.IMDbID
+	}
+	if stale {
+		slog.Info("existing images belong to another movie, downloading again",
+			"slug", movie.Slug,
+			"existing_tmdb_id", existing.TMDBID,
+			"new_tmdb_id", movie.TMDBID,
+		)
+	}
+	return stale
+}
+
+// skipExistingImage reports whether an image download can be skipped because
+// the image is already present. --force-refresh, options.overwrite_images and
+// replace (see staleArtwork) always download.
+func skipExistingImage(cfg *config.Config, replace bool, fileName, title, imageType, absPath, sitePath string) bool {
+	if replace || cfg.Options.OverwriteImages {
+		return false
+	}
+	path := existingImage(cfg, absPath, sitePath)
+	if path == "" {
+		return false
+	}
+	slog.Debug("image download skipped",
+		"file", fileName,
+		"movie", title,
+		"image_type", imageType,
+		"reason", "already_present",
+		"path", path,
+	)
+	return true
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestRunDedupeCovers(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"home-video-2019.jpg":         "fallback",
+		"home-video-2020.jpg":         "fallback",
+		"ho/home-video-2021.jpg":      "fallback",
+		"heat-1995.jpg":               "heat poster",
+		"heat-1995-backdrop.jpg":      "heat backdrop",
+		"inception-2010-backdrop.jpg": "heat backdrop",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	groups, err := findDuplicateCovers(dir)
+	if err != nil {
+		t.Fatalf("findDuplicateCovers failed: %v", err)
+	}
+	if len(groups) != 2 || len(groups[0].Paths) != 3 || groups[0].Wasted != 2*int64(len("fallback")) {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+
+	var out bytes.Buffer
+	if code := runDedupeCovers(dir, false, &out); code != 0 {
+		t.Fatalf("runDedupeCovers() = %d, output:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "2 group(s)") {
+		t.Errorf("report missing group count:\n%s", out.String())
+	}
+
+	// Every copy is still readable and now shares one inode
+	first, _ := os.Stat(filepath.Join(dir, "ho", "home-video-2021.jpg"))
+	for _, name := range []string{"home-video-2019.jpg", "home-video-2020.jpg"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || !os.SameFile(first, info) {
+			t.Errorf("%s not hardlinked to the kept copy (err %v)", name, err)
+		}
+	}
+
+	// A second pass finds nothing left to reclaim
+	groups, err = findDuplicateCovers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range groups {
+		if group.Wasted != 0 {
+			t.Errorf("expected no wasted space after linking, got %+v", group)
+		}
+	}
+}
+
+func TestSkipExistingImage(t *testing.T) {
+	dir := t.TempDir()
+	coversDir := filepath.Join(dir, "covers")
+	publicCovers := filepath.Join(dir, "website", "public", "covers")
+	for path, content := range map[string]string{
+		filepath.Join(coversDir, "heat-1995.jpg"):         "poster",
+		filepath.Join(coversDir, "alien-1979.jpg"):        "",
+		filepath.Join(publicCovers, "inception-2010.jpg"): "poster",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Output.WebsiteDir = filepath.Join(dir, "website")
+
+	testCases := []struct {
+		slug      string
+		overwrite bool
+		replace   bool
+		want      bool
+	}{
+		{"heat-1995", false, false, true},
+		{"heat-1995", false, true, false},
+		{"heat-1995", true, false, false},
+		{"alien-1979", false, false, false}, // empty file is re-downloaded
+		{"inception-2010", false, false, true},
+		{"the-matrix-1999", false, false, false},
+	}
+
+	for _, tc := range testCases {
+		cfg.Options.OverwriteImages = tc.overwrite
+		absPath := filepath.Join(coversDir, tc.slug+".jpg")
+		if got := skipExistingImage(cfg, tc.replace, tc.slug+".mkv", tc.slug, "cover", absPath, "/covers/"+tc.slug+".jpg"); got != tc.want {
+			t.Errorf("skipExistingImage(%s, overwrite=%v, replace=%v) = %v, want %v", tc.slug, tc.overwrite, tc.replace, got, tc.want)
+		}
+	}
+}
+
+func TestStaleArtwork(t *testing.T) {
+	mdxWriter := writer.NewMDXWriter(t.TempDir(), t.TempDir())
+	for _, existing := range []*writer.Movie{
+		{Title: "Heat", Slug: "heat-1995", TMDBID: 949},
+		{Title: "Alien", Slug: "alien-1979", IMDbID: "tt0078748"},
+		{Title: "Unknown", Slug: "unknown", Unmatched: true},
+	} {
+		if err := mdxWriter.WriteMDXFile(existing); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name  string
+		movie writer.Movie
+		want  bool
+	}{
+		{"same tmdb id", writer.Movie{Slug: "heat-1995", TMDBID: 949}, false},
+		{"tmdb id drift", writer.Movie{Slug: "heat-1995", TMDBID: 1234}, true},
+		{"same imdb id without tmdb id", writer.Movie{Slug: "alien-1979", IMDbID: "tt0078748"}, false},
+		{"now matched on tmdb", writer.Movie{Slug: "alien-1979", TMDBID: 348}, true},
+		{"replaces unmatched placeholder", writer.Movie{Slug: "unknown", TMDBID: 603}, true},
+		{"no existing mdx", writer.Movie{Slug: "the-matrix-1999", TMDBID: 603}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := staleArtwork(mdxWriter, &tc.movie); got != tc.want {
+				t.Errorf("staleArtwork(%s) = %v, want %v", tc.movie.Slug, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// Actions for --dedupe-action
+const (
+	dedupeActionReport = "report" // Print the duplicate report only (default)
+	dedupeActionTrash  = "trash"  // Move removable copies to the trash directory
+	dedupeActionDelete = "delete" // Permanently delete removable copies
+)
+
+// validDedupeAction reports whether action is a supported --dedupe-action value
+func validDedupeAction(action string) bool {
+	switch action {
+	case dedupeActionReport, dedupeActionTrash, dedupeActionDelete:
+		return true
+	}
+	return false
+}
+
+// dedupeOptions controls how runDedupeAction acts on removable copies
+type dedupeOptions struct {
+	Action    string // dedupeActionTrash or dedupeActionDelete
+	TrashDir  string // Destination for dedupeActionTrash
+	DryRun    bool   // Print what would happen without touching any files
+	AssumeYes bool   // Skip the confirmation prompt
+}
+
+// runDedupeAction trashes or deletes the video file of every removable copy in
+// duplicates, along with its MDX file, cover, and backdrop. Copies without a
+// file path (e.g. imported by TMDB ID) are skipped. Unless opts.AssumeYes is
+// set, the user must confirm on in before anything is changed.
+// Returns exit code: 0 on success or when aborted, 1 if any copy failed
+func runDedupeAction(duplicates []scanner.DuplicateSet, mdxWriter *writer.MDXWriter, opts dedupeOptions, in io.Reader, out io.Writer) int {
+	removable := removableCopies(duplicates)
+	if len(removable) == 0 {
+		fmt.Fprintln(out, "No removable copies to act on.")
+		return 0
+	}
+
+	description := "permanently delete"
+	if opts.Action == dedupeActionTrash {
+		description = "move to " + opts.TrashDir
+	}
+
+	if opts.DryRun {
+		fmt.Fprintln(out, "\nDRY RUN MODE - No actual changes will be made")
+		for _, movie := range removable {
+			fmt.Fprintf(out, "Would %s: %s (and remove %s.mdx)\n", description, movie.FilePath, movie.Slug)
+		}
+		return 0
+	}
+
+	if !opts.AssumeYes {
+		prompt := fmt.Sprintf("About to %s %d removable copies and remove their MDX files and images. Continue?", description, len(removable))
+		if !confirmAction(in, out, prompt) {
+			fmt.Fprintln(out, "Aborted, no files were changed.")
+			return 0
+		}
+	}
+
+	var done, failed int
+	for _, movie := range removable {
+		if err := dedupeCopy(movie, mdxWriter, opts); err != nil {
+			slog.Error("dedupe action failed", "action", opts.Action, "file", movie.FilePath, "error", err)
+			failed++
+			continue
+		}
+		done++
+	}
+
+	slog.Info("dedupe complete", "action", opts.Action, "succeeded", done, "failed", failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// removableCopies returns the copies marked removable across all sets that
+// are safe to remove. The result is computed once over every set, since the
+// TMDB ID and file hash sets can contain the same copy. A copy is skipped when:
+//   - it has no video file (e.g. imported by TMDB ID)
+//   - it is kept (recommended or within the top N) in any set
+//   - its video file is also referenced by a kept copy
+//   - no kept copy in its set is the same disc, so it is another part of a
+//     multi-disc movie rather than a duplicate
+//
+// Each MDX file and video file is returned at most once.
+func removableCopies(duplicates []scanner.DuplicateSet) []scanner.DuplicateMovie {
+	keptMDX := make(map[string]bool)
+	keptFiles := make(map[string]bool)
+	for _, set := range duplicates {
+		for _, movie := range set.Movies {
+			if movie.Status == scanner.DuplicateStatusRemovable {
+				continue
+			}
+			keptMDX[movie.MDXPath] = true
+			if movie.FilePath != "" {
+				keptFiles[movie.FilePath] = true
+			}
+		}
+	}
+
+	var removable []scanner.DuplicateMovie
+	seenMDX := make(map[string]bool)
+	seenFiles := make(map[string]bool)
+	for _, set := range duplicates {
+		keptDiscs := make(map[int]bool)
+		for _, movie := range set.Movies {
+			if movie.Status != scanner.DuplicateStatusRemovable {
+				keptDiscs[movie.DiscNumber] = true
+			}
+		}
+
+		for _, movie := range set.Movies {
+			if movie.Status != scanner.DuplicateStatusRemovable {
+				continue
+			}
+			switch {
+			case movie.FilePath == "":
+				slog.Warn("skipping removable copy without a video file", "slug", movie.Slug)
+			case keptMDX[movie.MDXPath]:
+				slog.Debug("skipping copy kept in another duplicate set", "slug", movie.Slug)
+			case keptFiles[movie.FilePath]:
+				slog.Warn("skipping removable copy whose video file a kept copy uses", "slug", movie.Slug, "file", movie.FilePath)
+			case !keptDiscs[movie.DiscNumber]:
+				slog.Warn("skipping removable copy that is a different disc of the kept copy", "slug", movie.Slug, "disc", movie.DiscNumber)
+			case seenMDX[movie.MDXPath] || seenFiles[movie.FilePath]:
+				// Already removed through another set
+			default:
+				seenMDX[movie.MDXPath] = true
+				seenFiles[movie.FilePath] = true
+				removable = append(removable, movie)
+			}
+		}
+	}
+	return removable
+}
+
+// dedupeCopy trashes or deletes one copy's video file, then removes its MDX
+// file, JSON sidecar, and downloaded images so the site no longer lists it
+func dedupeCopy(movie scanner.DuplicateMovie, mdxWriter *writer.MDXWriter, opts dedupeOptions) error {
+	switch opts.Action {
+	case dedupeActionTrash:
+		dest, err := moveToTrash(movie.FilePath, opts.TrashDir)
+		if err != nil {
+			return fmt.Errorf("failed to move to trash: %w", err)
+		}
+		slog.Info("moved duplicate to trash", "file", movie.FilePath, "dest", dest)
+	case dedupeActionDelete:
+		if err := os.Remove(movie.FilePath); err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+		slog.Info("deleted duplicate", "file", movie.FilePath)
+	default:
+		return fmt.Errorf("unsupported dedupe action %q", opts.Action)
+	}
+
+	for _, path := range []string{
+		movie.MDXPath,
+		mdxWriter.GetJSONPath(movie.Slug),
+		mdxWriter.GetAbsoluteCoverPath(movie.Slug),
+		mdxWriter.GetAbsoluteBackdropPath(movie.Slug),
+	} {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+		slog.Info("removed duplicate output", "slug", movie.Slug, "path", path)
+	}
+	return nil
+}
+
+// moveToTrash moves path into trashDir, adding a numeric suffix if a file with
+// the same name is already there. Falls back to copy-and-delete when trashDir
+// is on a different filesystem. Returns the destination path.
+func moveToTrash(path, trashDir string) (string, error) {
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		return "", err
+	}
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	dest := filepath.Join(trashDir, base)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(dest); os.IsNotExist(err) {
+			break
+		}
+		dest = filepath.Join(trashDir, strings.TrimSuffix(base, ext)+"."+strconv.Itoa(i)+ext)
+	}
+
+	err := os.Rename(path, dest)
+	if err == nil {
+		return dest, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return "", err
+	}
+
+	if err := copyFile(path, dest); err != nil {
+		os.Remove(dest)
+		return "", err
+	}
+	return dest, os.Remove(path)
+}
+
+// confirmAction prints prompt and reads a yes/no answer from in. Anything other
+// than "y" or "yes" (including EOF) declines.
+func confirmAction(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
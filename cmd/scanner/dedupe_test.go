@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// setupDedupeLibrary writes a 2160p and a 720p copy of the same movie with
+// MDX files and covers, and returns the duplicate sets found for them
+func setupDedupeLibrary(t *testing.T, dir string) (*writer.MDXWriter, []scanner.DuplicateSet) {
+	t.Helper()
+	mdxWriter := writer.NewMDXWriter(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"))
+
+	for _, m := range []struct{ slug, file string }{
+		{"inception-2010", "Inception.2010.2160p.BluRay.mkv"},
+		{"inception-2010-alt", "Inception.2010.720p.WEB-DL.mkv"},
+	} {
+		videoPath := filepath.Join(dir, "media", m.file)
+		coverPath := mdxWriter.GetAbsoluteCoverPath(m.slug)
+		for _, path := range []string{videoPath, coverPath} {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		movie := &writer.Movie{
+			Title: "Inception", Slug: m.slug, ReleaseYear: 2010, TMDBID: 27205,
+			FilePath: videoPath, FileName: m.file,
+		}
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates, err := scanner.NewDuplicateFinder(filepath.Join(dir, "movies")).FindDuplicates()
+	if err != nil || len(duplicates) != 1 {
+		t.Fatalf("expected one duplicate set, got %d (err %v)", len(duplicates), err)
+	}
+	return mdxWriter, duplicates
+}
+
+func TestRunDedupeAction(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        dedupeOptions
+		input       string
+		wantRemoved bool
+		wantTrashed bool
+	}{
+		{"dry run changes nothing", dedupeOptions{Action: dedupeActionDelete, DryRun: true}, "", false, false},
+		{"declined prompt changes nothing", dedupeOptions{Action: dedupeActionDelete}, "n\n", false, false},
+		{"confirmed delete", dedupeOptions{Action: dedupeActionDelete}, "y\n", true, false},
+		{"trash with --yes", dedupeOptions{Action: dedupeActionTrash, AssumeYes: true}, "", true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mdxWriter, duplicates := setupDedupeLibrary(t, dir)
+			tc.opts.TrashDir = filepath.Join(dir, "trash")
+
+			var out bytes.Buffer
+			if code := runDedupeAction(duplicates, mdxWriter, tc.opts, strings.NewReader(tc.input), &out); code != 0 {
+				t.Fatalf("runDedupeAction() = %d, output:\n%s", code, out.String())
+			}
+
+			// The recommended copy is never touched
+			if _, err := os.Stat(filepath.Join(dir, "media", "Inception.2010.2160p.BluRay.mkv")); err != nil {
+				t.Errorf("recommended copy missing: %v", err)
+			}
+			if !mdxWriter.MDXExists("inception-2010") {
+				t.Error("recommended MDX missing")
+			}
+
+			_, videoErr := os.Stat(filepath.Join(dir, "media", "Inception.2010.720p.WEB-DL.mkv"))
+			_, coverErr := os.Stat(mdxWriter.GetAbsoluteCoverPath("inception-2010-alt"))
+			removed := os.IsNotExist(videoErr) && os.IsNotExist(coverErr) && !mdxWriter.MDXExists("inception-2010-alt")
+			if removed != tc.wantRemoved {
+				t.Errorf("removable copy removed = %v, want %v", removed, tc.wantRemoved)
+			}
+
+			_, trashErr := os.Stat(filepath.Join(tc.opts.TrashDir, "Inception.2010.720p.WEB-DL.mkv"))
+			if trashed := trashErr == nil; trashed != tc.wantTrashed {
+				t.Errorf("removable copy trashed = %v, want %v", trashed, tc.wantTrashed)
+			}
+		})
+	}
+}
+
+func TestMoveToTrash_NameCollision(t *testing.T) {
+	dir := t.TempDir()
+	trashDir := filepath.Join(dir, "trash")
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(trashDir, "Heat.mkv"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "Heat.mkv")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, err := moveToTrash(src, trashDir)
+	if err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+	if want := filepath.Join(trashDir, "Heat.1.mkv"); dest != want {
+		t.Errorf("moveToTrash() = %q, want %q", dest, want)
+	}
+}
+
+func TestRemovableCopies_Safety(t *testing.T) {
+	copyOf := func(slug, file, status string, disc int) scanner.DuplicateMovie {
+		return scanner.DuplicateMovie{
+			Slug: slug, MDXPath: "/movies/" + slug + ".mdx", FilePath: file,
+			Status: status, DiscNumber: disc,
+		}
+	}
+	const (
+		rec = scanner.DuplicateStatusRecommended
+		rem = scanner.DuplicateStatusRemovable
+	)
+
+	testCases := []struct {
+		name string
+		sets []scanner.DuplicateSet
+		want []string // slugs
+	}{
+		{
+			"plain duplicate",
+			[]scanner.DuplicateSet{{Movies: []scanner.DuplicateMovie{
+				copyOf("heat-1995", "/media/Heat.2160p.mkv", rec, 0),
+				copyOf("heat-1995-alt", "/media/Heat.720p.mkv", rem, 0),
+			}}},
+			[]string{"heat-1995-alt"},
+		},
+		{
+			"other disc is not a duplicate",
+			[]scanner.DuplicateSet{{Movies: []scanner.DuplicateMovie{
+				copyOf("heat-1995-cd1", "/media/Heat.cd1.mkv", rec, 1),
+				copyOf("heat-1995-cd2", "/media/Heat.cd2.mkv", rem, 2),
+			}}},
+			nil,
+		},
+		{
+			"video file shared with the kept copy",
+			[]scanner.DuplicateSet{{Movies: []scanner.DuplicateMovie{
+				copyOf("heat-1995", "/media/Heat.mkv", rec, 0),
+				copyOf("heat-1995-alt", "/media/Heat.mkv", rem, 0),
+			}}},
+			nil,
+		},
+		{
+			"kept in another set",
+			[]scanner.DuplicateSet{
+				{Movies: []scanner.DuplicateMovie{
+					copyOf("heat-1995", "/media/Heat.2160p.mkv", rec, 0),
+					copyOf("heat-1995-alt", "/media/Heat.720p.mkv", rem, 0),
+				}},
+				{KeyType: "file_hash", Movies: []scanner.DuplicateMovie{
+					copyOf("heat-1995-alt", "/media/Heat.720p.mkv", rec, 0),
+					copyOf("heat-copy", "/backup/Heat.720p.mkv", rem, 0),
+				}},
+			},
+			[]string{"heat-copy"},
+		},
+		{
+			"removable in two sets is returned once",
+			[]scanner.DuplicateSet{
+				{Movies: []scanner.DuplicateMovie{
+					copyOf("heat-1995", "/media/Heat.2160p.mkv", rec, 0),
+					copyOf("heat-1995-alt", "/media/Heat.720p.mkv", rem, 0),
+				}},
+				{KeyType: "file_hash", Movies: []scanner.DuplicateMovie{
+					copyOf("heat-copy", "/backup/Heat.720p.mkv", rec, 0),
+					copyOf("heat-1995-alt", "/media/Heat.720p.mkv", rem, 0),
+				}},
+			},
+			[]string{"heat-1995-alt"},
+		},
+		{
+			"no video file",
+			[]scanner.DuplicateSet{{Movies: []scanner.DuplicateMovie{
+				copyOf("heat-1995", "/media/Heat.mkv", rec, 0),
+				copyOf("heat-1995-alt", "", rem, 0),
+			}}},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		var got []string
+		for _, movie := range removableCopies(tc.sets) {
+			got = append(got, movie.Slug)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: removableCopies = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRunDedupeAction_KeepsOtherDiscs(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"))
+	for _, m := range []struct{ slug, file string }{
+		{"heat-1995-cd1", "Heat.1995.1080p.BluRay.cd1.mkv"},
+		{"heat-1995-cd2", "Heat.1995.720p.cd2.mkv"},
+	} {
+		videoPath := filepath.Join(dir, "media", m.file)
+		if err := os.MkdirAll(filepath.Dir(videoPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(videoPath, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		movie := &writer.Movie{Title: "Heat", Slug: m.slug, ReleaseYear: 1995, TMDBID: 949, FilePath: videoPath, FileName: m.file}
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates, err := scanner.NewDuplicateFinder(filepath.Join(dir, "movies")).FindDuplicates()
+	if err != nil || len(duplicates) != 1 {
+		t.Fatalf("expected the discs to share a duplicate set, got %d (err %v)", len(duplicates), err)
+	}
+	var out bytes.Buffer
+	opts := dedupeOptions{Action: dedupeActionDelete, AssumeYes: true}
+	if code := runDedupeAction(duplicates, mdxWriter, opts, strings.NewReader(""), &out); code != 0 {
+		t.Fatalf("runDedupeAction() = %d, output:\n%s", code, out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "media", "Heat.1995.720p.cd2.mkv")); err != nil {
+		t.Errorf("second disc was removed: %v", err)
+	}
+}
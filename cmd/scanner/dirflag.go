@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+// stringList is a repeatable string flag (e.g. --dir a --dir b)
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// applyDirOverrides replaces scanner.directories with the --dir paths for
+// this run, or appends them when appendDirs is set. Each path must be an
+// existing directory; relative paths are resolved against the working directory.
+func applyDirOverrides(cfg *config.Config, dirs []string, appendDirs bool) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	resolved := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("invalid --dir %q: %w", dir, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return fmt.Errorf("invalid --dir %q: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("invalid --dir %q: not a directory", dir)
+		}
+		resolved = append(resolved, abs)
+	}
+
+	if !appendDirs {
+		cfg.Scanner.Directories = resolved
+		return nil
+	}
+
+	seen := make(map[string]bool, len(cfg.Scanner.Directories))
+	for _, dir := range cfg.Scanner.Directories {
+		seen[filepath.Clean(dir)] = true
+	}
+	for _, dir := range resolved {
+		if !seen[dir] {
+			seen[dir] = true
+			cfg.Scanner.Directories = append(cfg.Scanner.Directories, dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+func TestApplyDirOverrides(t *testing.T) {
+	root := t.TempDir()
+	adhoc := filepath.Join(root, "adhoc")
+	if err := os.Mkdir(adhoc, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	file := filepath.Join(root, "movie.mkv")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		dirs       []string
+		appendDirs bool
+		expected   []string
+		wantErr    string
+	}{
+		{"no override", nil, false, []string{"/movies"}, ""},
+		{"replace", []string{adhoc}, false, []string{adhoc}, ""},
+		{"append", []string{adhoc}, true, []string{"/movies", adhoc}, ""},
+		{"append skips configured", []string{adhoc, adhoc + "/"}, true, []string{"/movies", adhoc}, ""},
+		{"missing", []string{filepath.Join(root, "missing")}, false, nil, "invalid --dir"},
+		{"file", []string{file}, false, nil, "not a directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Scanner.Directories = []string{"/movies"}
+			err := applyDirOverrides(cfg, tc.dirs, tc.appendDirs)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg.Scanner.Directories, tc.expected) {
+				t.Errorf("Directories = %v, want %v", cfg.Scanner.Directories, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// csvHeader lists the columns written by --export-csv
+var csvHeader = []string{
+	"title", "year", "rating", "runtime", "genres", "director",
+	"tmdb_id", "imdb_id", "file_path", "file_size", "resolution", "source",
+}
+
+// runExportCSV writes one CSV row per movie in the MDX library (use "-" for stdout)
+// Returns exit code: 0 on success, 1 on error
+func runExportCSV(outputPath string) int {
+	// Load configuration to get MDX directory
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	mdxFiles, err := filepath.Glob(filepath.Join(cfg.Output.MDXDir, "*.mdx"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to list MDX files: %v\n", err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if outputPath != "-" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create CSV file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	rows, err := writeMoviesCSV(mdxFiles, out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if outputPath != "-" {
+		fmt.Printf("Exported %d movies to %s\n", rows, outputPath)
+	}
+	return 0
+}
+
+// writeMoviesCSV writes the header and one row per readable MDX file to out,
+// returning the number of movies written
+func writeMoviesCSV(mdxFiles []string, out io.Writer) (int, error) {
+	csvWriter := csv.NewWriter(out)
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	rows := 0
+	for _, mdxPath := range mdxFiles {
+		movie, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			// Log warning but continue exporting other files
+			fmt.Fprintf(os.Stderr, "Warning: Failed to parse %s: %v\n", mdxPath, err)
+			continue
+		}
+		if err := csvWriter.Write(movieCSVRecord(movie)); err != nil {
+			return rows, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+		rows++
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return rows, fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return rows, nil
+}
+
+// movieCSVRecord converts a movie into a CSV row matching csvHeader
+func movieCSVRecord(movie *writer.Movie) []string {
+	resolution, source := scanner.ExtractQualityInfo(movie.FileName)
+	return []string{
+		movie.Title,
+		strconv.Itoa(movie.ReleaseYear),
+		strconv.FormatFloat(movie.Rating, 'f', 1, 64),
+		strconv.Itoa(movie.Runtime),
+		strings.Join(movie.Genres, "|"),
+		movie.Director,
+		strconv.Itoa(movie.TMDBID),
+		movie.IMDbID,
+		movie.FilePath,
+		strconv.FormatInt(movie.FileSize, 10),
+		resolution,
+		source,
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestWriteMoviesCSV(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	movies := []*writer.Movie{
+		{
+			Title: "Crouching Tiger, Hidden Dragon", Slug: "crouching-tiger-hidden-dragon-2000", ReleaseYear: 2000,
+			Rating: 7.9, Runtime: 120, Genres: []string{"Action", "Drama"}, Director: "Ang Lee", TMDBID: 146,
+			IMDbID: "tt0190332", FileName: "Crouching.Tiger.2000.1080p.BluRay.mkv",
+			FilePath: "/media/Crouching Tiger, Hidden Dragon (2000)/movie.mkv", FileSize: 1024,
+		},
+		{Title: "Line One\nLine \"Two\"", Slug: "line-one-2001", ReleaseYear: 2001},
+	}
+	for _, movie := range movies {
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile failed: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	rows, err := writeMoviesCSV([]string{
+		mdxWriter.GetMDXPath("crouching-tiger-hidden-dragon-2000"),
+		mdxWriter.GetMDXPath("line-one-2001"),
+	}, &out)
+	if err != nil || rows != 2 {
+		t.Fatalf("writeMoviesCSV() = %d, %v, want 2 rows", rows, err)
+	}
+
+	// Titles with commas, quotes and newlines are quoted rather than split
+	if !strings.Contains(out.String(), `"Crouching Tiger, Hidden Dragon",2000,`) {
+		t.Errorf("comma in title not quoted:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "\"Line One\nLine \"\"Two\"\"\",2001,") {
+		t.Errorf("newline and quotes in title not quoted:\n%s", out.String())
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want header and 2 rows", len(records))
+	}
+	if strings.Join(records[0], ",") != "title,year,rating,runtime,genres,director,tmdb_id,imdb_id,file_path,file_size,resolution,source" {
+		t.Errorf("header = %v", records[0])
+	}
+	expected := []string{
+		"Crouching Tiger, Hidden Dragon", "2000", "7.9", "120", "Action|Drama", "Ang Lee",
+		"146", "tt0190332", "/media/Crouching Tiger, Hidden Dragon (2000)/movie.mkv", "1024", "1080p", "BluRay",
+	}
+	if strings.Join(records[1], "\x00") != strings.Join(expected, "\x00") {
+		t.Errorf("row = %q, want %q", records[1], expected)
+	}
+	if records[2][0] != "Line One\nLine \"Two\"" {
+		t.Errorf("title = %q, want the newline and quotes kept", records[2][0])
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// postProcessHook runs the user's options.post_process_command once per
+// successfully processed movie. Commands run asynchronously with a timeout so
+// a slow hook never blocks the scan pipeline.
+type postProcessHook struct {
+	args    []string
+	timeout time.Duration
+	wg      sync.WaitGroup
+}
+
+// newPostProcessHook creates a hook for command, or returns nil when no command
+// is configured. The command is split on whitespace (no shell interpretation);
+// use a wrapper script for pipes or redirection.
+func newPostProcessHook(command string, timeoutSec int) *postProcessHook {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	return &postProcessHook{
+		args:    args,
+		timeout: time.Duration(timeoutSec) * time.Second,
+	}
+}
+
+// Run starts the hook for movie in the background. The slug, title, MDX path,
+// and cover path are appended as arguments and also exported as MOVIEVAULT_*
+// environment variables. coverPath is empty when covers are not downloaded.
+// Safe to call on a nil hook.
+func (h *postProcessHook) Run(movie *writer.Movie, mdxPath, coverPath string) {
+	if h == nil {
+		return
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		defer cancel()
+
+		args := append(append([]string{}, h.args[1:]...), movie.Slug, movie.Title, mdxPath, coverPath)
+		cmd := exec.CommandContext(ctx, h.args[0], args...)
+		cmd.Env = append(os.Environ(),
+			"MOVIEVAULT_SLUG="+movie.Slug,
+			"MOVIEVAULT_TITLE="+movie.Title,
+			"MOVIEVAULT_MDX_PATH="+mdxPath,
+			"MOVIEVAULT_COVER_PATH="+coverPath,
+		)
+
+		output, err := cmd.CombinedOutput()
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			slog.Warn("post-process command timed out",
+				"slug", movie.Slug,
+				"timeout", h.timeout,
+			)
+		case err != nil:
+			slog.Warn("post-process command failed",
+				"slug", movie.Slug,
+				"error", err,
+				"output", strings.TrimSpace(string(output)),
+			)
+		default:
+			slog.Debug("post-process command completed", "slug", movie.Slug)
+		}
+	}()
+}
+
+// Wait blocks until all started hooks have finished. Safe to call on a nil hook.
+func (h *postProcessHook) Wait() {
+	if h == nil {
+		return
+	}
+	h.wg.Wait()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestPostProcessHook_PassesMovieDetails(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	outPath := filepath.Join(dir, "out.txt")
+	body := "#!/bin/sh\necho \"$1|$2|$3|$4|$MOVIEVAULT_SLUG\" > " + outPath + "\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	hook := newPostProcessHook(script, 5)
+	hook.Run(&writer.Movie{Slug: "inception-2010", Title: "Inception"}, "/mdx/inception-2010.mdx", "/covers/inception-2010.jpg")
+	hook.Wait()
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	expected := "inception-2010|Inception|/mdx/inception-2010.mdx|/covers/inception-2010.jpg|inception-2010"
+	if got := strings.TrimSpace(string(data)); got != expected {
+		t.Errorf("hook output = %q, want %q", got, expected)
+	}
+}
+
+func TestPostProcessHook_EmptyCommand(t *testing.T) {
+	hook := newPostProcessHook("   ", 5)
+	if hook != nil {
+		t.Fatal("expected nil hook for empty command")
+	}
+	// Nil hooks are no-ops
+	hook.Run(&writer.Movie{Slug: "x"}, "", "")
+	hook.Wait()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/metadata/omdb"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// runImportIDs creates MDX files for the TMDB IDs listed in path, without any
+// video files on disk (file info is left empty). IDs that already have an MDX
+// file are skipped, so the same list can be imported repeatedly.
+// Returns exit code: 0 on success, 1 if the list could not be read or any ID failed
+func runImportIDs(cfg *config.Config, tmdbClient metadata.MovieProvider, omdbClient *omdb.Client, mdxWriter *writer.MDXWriter, path string, dryRun bool) int {
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Error("failed to open import list", "path", path, "error", err)
+		return 1
+	}
+	defer f.Close()
+
+	ids, err := parseImportIDs(f)
+	if err != nil {
+		slog.Error("failed to read import list", "path", path, "error", err)
+		return 1
+	}
+
+	existingIDs := existingTMDBIDs(cfg.Output.MDXDir)
+
+	slog.Info("importing TMDB IDs", "count", len(ids), "existing", len(existingIDs))
+
+	var imported, skipped, failed int
+	for _, id := range ids {
+		if existingIDs[id] {
+			slog.Debug("skipping: mdx already exists for tmdb id", "tmdb_id", id)
+			skipped++
+			continue
+		}
+
+		movie, err := tmdbClient.GetMovieByID(id)
+		if err != nil {
+			slog.Error("failed to fetch movie", "tmdb_id", id, "error", err)
+			failed++
+			continue
+		}
+
+		movie.Slug = scanner.GenerateSlug(movie.Title, movie.ReleaseYear)
+		if mdxWriter.MDXExists(movie.Slug) {
+			slog.Info("skipping: mdx already exists for slug", "tmdb_id", id, "slug", movie.Slug)
+			skipped++
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("Would import: %s (%d) -> %s.mdx\n", movie.Title, movie.ReleaseYear, movie.Slug)
+			imported++
+			continue
+		}
+
+		enrichRatings(omdbClient, movie, "")
+		downloadImportImages(cfg, tmdbClient, mdxWriter, movie)
+
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			slog.Error("failed to write MDX file", "slug", movie.Slug, "error", err)
+			failed++
+			continue
+		}
+
+		existingIDs[id] = true
+		slog.Info("movie imported", "tmdb_id", id, "slug", movie.Slug)
+		imported++
+	}
+
+	slog.Info("import complete",
+		"imported", imported,
+		"skipped", skipped,
+		"failed", failed,
+	)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseImportIDs reads one TMDB ID per line. Blank lines and "#" comments are
+// ignored, and anything after the ID on a line (e.g. a title) is ignored too.
+// Duplicate IDs are returned once, in first-seen order.
+func parseImportIDs(r io.Reader) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+
+	lineScanner := bufio.NewScanner(r)
+	lineNum := 0
+	for lineScanner.Scan() {
+		lineNum++
+		line := lineScanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id <= 0 {
+			slog.Warn("skipping invalid TMDB ID", "line", lineNum, "value", fields[0])
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if err := lineScanner.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// existingTMDBIDs returns the TMDB IDs referenced by MDX files in mdxDir
+func existingTMDBIDs(mdxDir string) map[int]bool {
+	ids := make(map[int]bool)
+
+	mdxFiles, err := filepath.Glob(filepath.Join(mdxDir, "*.mdx"))
+	if err != nil {
+		return ids
+	}
+
+	for _, mdxPath := range mdxFiles {
+		existing, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			slog.Debug("failed to read MDX file", "path", mdxPath, "error", err)
+			continue
+		}
+		if existing.TMDBID > 0 {
+			ids[existing.TMDBID] = true
+		}
+	}
+	return ids
+}
+
+// downloadImportImages downloads TMDB cover and backdrop images for an imported
+// movie, setting the image fields only for images that were saved
+func downloadImportImages(cfg *config.Config, tmdbClient metadata.MovieProvider, mdxWriter *writer.MDXWriter, movie *writer.Movie) {
+	if !cfg.Options.DownloadCovers && !cfg.Options.DownloadBackdrops {
+		return
+	}
+
+	details, err := tmdbClient.GetMovieDetails(movie.TMDBID)
+	if err != nil {
+		slog.Warn("failed to fetch image paths", "slug", movie.Slug, "error", err)
+		return
+	}
+
+	if cfg.Options.DownloadCovers && details.PosterPath != "" {
+		if err := tmdbClient.DownloadImage(details.PosterPath, mdxWriter.GetAbsoluteCoverPath(movie.Slug), "poster"); err != nil {
+			slog.Warn("image download failed", "slug", movie.Slug, "image_type", "cover", "error", err)
+		} else {
+			movie.CoverImage = mdxWriter.GetCoverPath(movie.Slug)
+		}
+	}
+
+	if cfg.Options.DownloadBackdrops && details.BackdropPath != "" {
+		if err := tmdbClient.DownloadImage(details.BackdropPath, mdxWriter.GetAbsoluteBackdropPath(movie.Slug), "backdrop"); err != nil {
+			slog.Warn("image download failed", "slug", movie.Slug, "image_type", "backdrop", "error", err)
+		} else {
+			movie.BackdropImage = mdxWriter.GetBackdropPath(movie.Slug)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestParseImportIDs(t *testing.T) {
+	input := `# My wishlist
+27205 Inception
+603   # The Matrix
+
+not-an-id
+-5
+27205
+  949
+`
+
+	ids, err := parseImportIDs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseImportIDs failed: %v", err)
+	}
+
+	expected := []int{27205, 603, 949}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("parseImportIDs() = %v, want %v", ids, expected)
+	}
+}
+
+func TestRunImportIDs_FakeProvider(t *testing.T) {
+	dir := t.TempDir()
+	mdxDir := filepath.Join(dir, "movies")
+	coversDir := filepath.Join(dir, "covers")
+
+	listPath := filepath.Join(dir, "ids.txt")
+	if err := os.WriteFile(listPath, []byte("27205\n603\n999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Output.MDXDir = mdxDir
+	cfg.Options.DownloadCovers = true
+
+	provider := newFakeProvider(
+		&writer.Movie{Title: "Inception", ReleaseYear: 2010, TMDBID: 27205},
+		&writer.Movie{Title: "The Matrix", ReleaseYear: 1999, TMDBID: 603},
+	)
+	mdxWriter := writer.NewMDXWriter(mdxDir, coversDir)
+
+	// ID 999 is unknown to the provider, so the import reports failure
+	if code := runImportIDs(cfg, provider, nil, mdxWriter, listPath, false); code != 1 {
+		t.Errorf("runImportIDs() = %d, want 1", code)
+	}
+
+	for _, slug := range []string{"inception-2010", "the-matrix-1999"} {
+		if !mdxWriter.MDXExists(slug) {
+			t.Errorf("expected MDX for %s", slug)
+		}
+		if _, err := os.Stat(mdxWriter.GetAbsoluteCoverPath(slug)); err != nil {
+			t.Errorf("expected cover for %s: %v", slug, err)
+		}
+	}
+
+	// Re-importing skips the IDs that now have MDX files
+	provider.downloads = nil
+	if code := runImportIDs(cfg, provider, nil, mdxWriter, listPath, false); code != 1 {
+		t.Errorf("second runImportIDs() = %d, want 1", code)
+	}
+	if len(provider.downloads) != 0 {
+		t.Errorf("expected no downloads on re-import, got %v", provider.downloads)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -22,27 +23,68 @@ import (
 	"github.com/marco/movieVault/internal/metadata"
 	"github.com/marco/movieVault/internal/metadata/cache"
 	"github.com/marco/movieVault/internal/metadata/nfo"
+	"github.com/marco/movieVault/internal/metadata/omdb"
+	"github.com/marco/movieVault/internal/retry"
 	"github.com/marco/movieVault/internal/scanner"
 	"github.com/marco/movieVault/internal/writer"
 )
 
+// version is the scanner release version, reported in the TMDB User-Agent.
+// Override at build time with -ldflags "-X main.version=1.2.3".
+var version = "dev"
+
 var (
 	configPath       = flag.String("config", "./config/config.yaml", "Path to configuration file")
+	profile          = flag.String("profile", "", "Config profile: an entry under profiles: in the config file, or <name>.yaml next to it")
+	envConfig        = flag.Bool("env-config", false, "Read configuration from MOVIEVAULT_* environment variables only, ignoring --config")
 	forceRefresh     = flag.Bool("force-refresh", false, "Re-fetch all metadata from TMDB even for existing MDX files")
 	noBuild          = flag.Bool("no-build", false, "Skip Astro build step")
 	dryRun           = flag.Bool("dry-run", false, "Show what would be done without actually doing it")
 	verbose          = flag.Bool("verbose", false, "Show detailed logging")
+	quiet            = flag.Bool("quiet", false, "Only log warnings and errors; the scan summary is still printed (e.g. for cron)")
 	clearCache       = flag.Bool("clear-cache", false, "Clear the metadata cache and exit")
 	cacheStats       = flag.Bool("cache-stats", false, "Show cache statistics and exit")
 	testParser       = flag.Bool("test-parser", false, "Test title extraction without running full scan")
+	parserFormat     = flag.String("format", "text", "Output format for --test-parser: text, or json for one JSON object per line (NDJSON)")
+	parserFromFile   = flag.String("from-file", "", "Read --test-parser filenames from this file, one per line, instead of arguments or stdin")
+	parserTrace      = flag.Bool("trace", false, "Show the name after each --test-parser stripping step")
 	watchMode        = flag.Bool("watch", false, "Watch directories for new files and process automatically")
 	findDuplicates   = flag.Bool("find-duplicates", false, "Find duplicate movies in the library and exit")
 	detailed         = flag.Bool("detailed", false, "Show detailed quality breakdown in duplicate report (use with --find-duplicates)")
+	keepTop          = flag.Int("keep-top", 1, "Number of best copies to keep per duplicate set; the rest are marked removable (use with --find-duplicates)")
+	dedupeAction     = flag.String("dedupe-action", "report", "What to do with removable duplicate copies: report, trash, or delete (use with --find-duplicates)")
+	dedupeCovers     = flag.Bool("dedupe-covers", false, "Report identical images in the covers directory and hardlink them to one copy (report only with --dry-run), then exit")
+	trashDir         = flag.String("trash-dir", "./trash", "Directory removable copies are moved to with --dedupe-action=trash")
+	assumeYes        = flag.Bool("yes", false, "Skip the confirmation prompt for --dedupe-action")
 	workers          = flag.Int("workers", 0, "Number of concurrent workers (overrides config, default: 5)")
 	scheduleEnabled  = flag.Bool("schedule", false, "Enable scheduled scanning (overrides config)")
+	skipInitialScan  = flag.Bool("skip-initial-scan", false, "Skip the startup scan in watch and schedule modes (overrides schedule_on_startup)")
 	scheduleInterval = flag.Int("schedule-interval", 0, "Minutes between scans (overrides config, 0 = use config)")
+	since            = flag.String("since", "", "Only process files modified after this time (RFC3339, YYYY-MM-DD, or duration like 24h)")
+	exportCSV        = flag.String("export-csv", "", "Export the movie library to a CSV file at this path (\"-\" for stdout) and exit")
+	noOverwrite      = flag.Bool("no-overwrite", false, "Never overwrite existing MDX files, even with --force-refresh (overrides config)")
+	progress         = flag.Bool("progress", false, "Show a live progress bar with ETA when stdout is a terminal")
+	importIDs        = flag.String("import-ids", "", "Create MDX files for the TMDB IDs listed in this file (one per line) and exit")
+	configCheck      = flag.Bool("config-check", false, "Validate the configuration file, print a report, and exit (0 = OK, 1 = errors)")
+	checkAPI         = flag.Bool("check-api", false, "Also verify TMDB credentials against the live API (use with --config-check)")
+	regenerate       = flag.Bool("regenerate", false, "Rewrite all MDX files from cached TMDB data without network calls and exit")
+	migrateSlugs     = flag.Bool("migrate-slugs", false, "Rename MDX files, covers, and backdrops to slugs generated by the current rules and exit")
+	reprocess        = flag.String("reprocess", "", "Re-resolve and rewrite the MDX for one video file path or existing slug and exit")
+	verify           = flag.Bool("verify", false, "List movies whose matched title or year looks wrong for their video file and exit")
+	dirAppend        = flag.Bool("dir-append", false, "Add the --dir paths to scanner.directories instead of replacing them")
+	resume           = flag.Bool("resume", false, "Skip files an interrupted scan already finished (recorded next to the cache database) and process the rest")
+	collections      = flag.Bool("collections", false, "List each movie collection (franchise) with its member slugs and exit")
+	warmCache        = flag.String("warm-cache", "", "Pre-fetch TMDB metadata into the cache for the \"title[,year]\" lines in this file and exit")
+	missingFromColl  = flag.Int("missing-from-collection", 0, "List the movies of this TMDB collection ID that have no MDX in the library, with TMDB links, and exit")
 )
 
+// scanDirs holds the repeatable --dir flag
+var scanDirs stringList
+
+func init() {
+	flag.Var(&scanDirs, "dir", "Scan this directory instead of scanner.directories for this run (repeatable)")
+}
+
 func main() {
 	flag.Parse()
 
@@ -52,16 +94,53 @@ func main() {
 		os.Exit(exitCode)
 	}
 
+	// Handle --config-check flag
+	if *configCheck {
+		os.Exit(runConfigCheck(*configPath, *profile, *checkAPI, os.Stdout))
+	}
+
 	// Handle --find-duplicates flag (US-024)
 	if *findDuplicates {
 		exitCode := runFindDuplicates()
 		os.Exit(exitCode)
 	}
 
+	// Handle --export-csv flag
+	if *exportCSV != "" {
+		exitCode := runExportCSV(*exportCSV)
+		os.Exit(exitCode)
+	}
+
+	// Handle --verify flag
+	if *verify {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(runVerify(cfg.Output.MDXDir, cfg.Options.MatchConfidenceThreshold, os.Stdout))
+	}
+
+	// Handle --collections flag
+	if *collections {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(runCollectionsReport(cfg.Output.MDXDir, os.Stdout))
+	}
+
 	// Setup structured logger
+	if *verbose && *quiet {
+		fmt.Fprintln(os.Stderr, "Error: --verbose and --quiet are mutually exclusive")
+		os.Exit(1)
+	}
 	logLevel := slog.LevelInfo
 	if *verbose {
 		logLevel = slog.LevelDebug
+	} else if *quiet {
+		logLevel = slog.LevelWarn
 	}
 
 	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
@@ -70,16 +149,27 @@ func main() {
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
+	// Parse --since before loading config so typos fail fast
+	var sinceTime time.Time
+	if *since != "" {
+		var err error
+		sinceTime, err = parseSince(*since, time.Now())
+		if err != nil {
+			slog.Error("invalid --since value", "value", *since, "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load configuration
-	cfg, err := config.Load(*configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		slog.Error("failed to load config", "path", *configPath, "error", err)
+		slog.Error("failed to load config", "path", *configPath, "profile", *profile, "error", err)
 		os.Exit(1)
 	}
 
 	// Apply CLI flag overrides
 	if *workers > 0 {
-		cfg.Scanner.ConcurrentWorkers = *workers
+		cfg.Scanner.SetConcurrentWorkers(*workers)
 	}
 	if *scheduleEnabled {
 		cfg.Scanner.ScheduleEnabled = true
@@ -87,9 +177,29 @@ func main() {
 	if *scheduleInterval > 0 {
 		cfg.Scanner.ScheduleInterval = *scheduleInterval
 	}
+	if *noOverwrite {
+		cfg.Output.NeverOverwrite = true
+	}
+	if *skipInitialScan {
+		skipStartup := false
+		cfg.Scanner.ScheduleOnStartup = &skipStartup
+	}
+
+	// Teach the filename parser about user-configured release groups and quality tags
+	scanner.ConfigureTokens(cfg.Scanner.ExtraReleaseGroups, cfg.Scanner.ExtraQualityTokens)
+	scanner.ConfigureReservedSlugs(cfg.Output.ReservedSlugs)
+	if cfg.Output.StripArticlesInSlug {
+		scanner.ConfigureSlugArticles(cfg.Output.Articles)
+	}
+
+	// Handle --dedupe-covers flag
+	if *dedupeCovers {
+		os.Exit(runDedupeCovers(cfg.Output.CoversDir, *dryRun, os.Stdout))
+	}
 
 	slog.Info("configuration loaded",
 		"path", *configPath,
+		"profile", *profile,
 		"directories", len(cfg.Scanner.Directories),
 		"extensions", len(cfg.Scanner.Extensions),
 		"nfo_enabled", cfg.Options.UseNFO,
@@ -179,11 +289,25 @@ func main() {
 		slog.Info("cache initialized", "path", cfg.Cache.Path, "ttl_days", cfg.Cache.TTLDays)
 	}
 
+	// Probe video files with ffprobe for runtime matching and technical fields
+	if cfg.Options.UseFFprobe {
+		mediaProbes = newMediaProber(tmdbCache)
+	}
+
 	// Create TMDB client with retry and cache configuration
 	var retryLogFunc metadata.RetryLogFunc
 	var cacheLogFunc metadata.CacheLogFunc
 	if *verbose {
 		retryLogFunc = func(attempt int, maxAttempts int, backoff time.Duration, err error) {
+			var exhausted *retry.ExhaustedError
+			if errors.As(err, &exhausted) {
+				slog.Debug("tmdb request retries exhausted",
+					"attempts", exhausted.Attempts,
+					"rate_limited", exhausted.RateLimited,
+					"error", exhausted.Err.Error(),
+				)
+				return
+			}
 			slog.Debug("retrying tmdb request",
 				"attempt", attempt,
 				"max_attempts", maxAttempts,
@@ -206,22 +330,114 @@ func main() {
 			}
 		}
 	}
+	// The client reads 0 as "use the default"; a configured 0 disables 429 retries
+	rateLimitRetries := *cfg.Retry.MaxRateLimitRetries
+	if rateLimitRetries == 0 {
+		rateLimitRetries = -1
+	}
 	tmdbClient := metadata.NewClientWithConfig(metadata.ClientConfig{
-		APIKey:           cfg.TMDB.APIKey,
-		Language:         cfg.TMDB.Language,
-		RateLimitDelayMs: cfg.Options.RateLimitDelay,
-		MaxAttempts:      cfg.Retry.MaxAttempts,
-		InitialBackoffMs: cfg.Retry.InitialBackoffMs,
-		RetryLogFunc:     retryLogFunc,
-		Cache:            tmdbCache,
-		CacheTTLDays:     cfg.Cache.TTLDays,
-		CacheLogFunc:     cacheLogFunc,
-		ForceRefresh:     *forceRefresh,
+		APIKey:                cfg.TMDB.APIKey,
+		AccessToken:           cfg.TMDB.AccessToken,
+		Language:              cfg.TMDB.Language,
+		RateLimitDelayMs:      cfg.Options.RateLimitDelay,
+		RequestsPerSecond:     cfg.TMDB.RequestsPerSecond,
+		MaxAttempts:           cfg.Retry.MaxAttempts,
+		MaxRateLimitRetries:   rateLimitRetries,
+		InitialBackoffMs:      cfg.Retry.InitialBackoffMs,
+		MaxBackoffMs:          cfg.Retry.MaxBackoffMs,
+		RetryLogFunc:          retryLogFunc,
+		Cache:                 tmdbCache,
+		CacheTTLDays:          cfg.Cache.TTLDays,
+		CacheLogFunc:          cacheLogFunc,
+		ForceRefresh:          *forceRefresh,
+		TitlePreference:       cfg.Options.TitlePreference,
+		UserAgent:             "movieVault/" + version,
+		Headers:               cfg.TMDB.Headers,
+		FetchKeywords:         cfg.Options.FetchKeywords,
+		IncludeFinancials:     cfg.Options.IncludeFinancials,
+		RequireCredits:        cfg.Options.RequireCredits,
+		MinVoteCount:          cfg.Options.MinVoteCount,
+		Blocklist:             cfg.Options.TMDBBlocklist,
+		MinFreeSpaceMB:        cfg.Options.MinFreeSpaceMB,
+		FSWriteRetries:        cfg.Options.FSWriteRetries,
+		RequestTimeoutSeconds: cfg.TMDB.RequestTimeoutSeconds,
+		APIBaseURL:            cfg.TMDB.APIBaseURL,
+		ImageBaseURL:          cfg.TMDB.ImageBaseURL,
+		ImageTimeoutSeconds:   cfg.TMDB.ImageTimeoutSeconds,
+		ImageDownloadRateKbps: cfg.Options.ImageDownloadRateKbps,
 	})
 	defer tmdbClient.Close()
 
+	// Create optional OMDb client for IMDb / Rotten Tomatoes ratings
+	var omdbClient *omdb.Client
+	if cfg.OMDb.APIKey != "" {
+		omdbClient = omdb.NewClient(omdb.ClientConfig{
+			APIKey:           cfg.OMDb.APIKey,
+			MaxAttempts:      cfg.Retry.MaxAttempts,
+			InitialBackoffMs: cfg.Retry.InitialBackoffMs,
+			Cache:            tmdbCache,
+			CacheTTLDays:     cfg.Cache.TTLDays,
+		})
+		slog.Info("omdb ratings enrichment enabled")
+	}
+
 	// Create MDX writer
-	mdxWriter := writer.NewMDXWriter(cfg.Output.MDXDir, cfg.Output.CoversDir)
+	var sortArticles []string
+	if cfg.Output.SortTitle {
+		sortArticles = cfg.Output.Articles
+	}
+	var jsonDir string
+	if cfg.Output.WriteJSONSidecar {
+		jsonDir = cfg.Output.JSONSidecarDir
+	}
+	mdxWriter := writer.NewMDXWriterWithOptions(cfg.Output.MDXDir, cfg.Output.CoversDir, writer.MDXWriterOptions{
+		NeverOverwrite: cfg.Output.NeverOverwrite,
+		DateFormat:     cfg.Output.DateFormat,
+		CoversLayout:   cfg.Output.CoversLayout,
+		StoreImageURLs: cfg.Options.StoreImageURLs,
+		JSONDir:        jsonDir,
+		MinFreeSpaceMB: cfg.Options.MinFreeSpaceMB,
+		FSWriteRetries: cfg.Options.FSWriteRetries,
+		SortArticles:   sortArticles,
+	})
+
+	// Handle --regenerate flag
+	if *regenerate {
+		if tmdbCache == nil {
+			fmt.Println("Cache is disabled in configuration; --regenerate requires cached TMDB data.")
+			os.Exit(1)
+		}
+		os.Exit(runRegenerate(cfg, tmdbClient, mdxWriter, *dryRun))
+	}
+
+	// Handle --migrate-slugs flag
+	if *migrateSlugs {
+		os.Exit(runMigrateSlugs(cfg, mdxWriter, *dryRun))
+	}
+
+	// Handle --import-ids flag
+	if *importIDs != "" {
+		os.Exit(runImportIDs(cfg, tmdbClient, omdbClient, mdxWriter, *importIDs, *dryRun))
+	}
+
+	// Handle --warm-cache flag
+	if *warmCache != "" {
+		if tmdbCache == nil {
+			slog.Error("--warm-cache requires cache.enabled: true")
+			os.Exit(1)
+		}
+		os.Exit(runWarmCache(tmdbClient, *warmCache))
+	}
+
+	// Handle --missing-from-collection flag
+	if *missingFromColl > 0 {
+		os.Exit(runMissingFromCollection(tmdbClient, cfg.Output.MDXDir, *missingFromColl, os.Stdout))
+	}
+
+	// Handle --reprocess flag
+	if *reprocess != "" {
+		os.Exit(runReprocess(cfg, tmdbClient, omdbClient, mdxWriter, *reprocess, *dryRun))
+	}
 
 	// Set up context for lifecycle management
 	ctx, cancel := context.WithCancel(context.Background())
@@ -236,14 +452,23 @@ func main() {
 		cancel()
 	}()
 
+	// Let the watcher and full scans skip files the other one just processed
+	if *watchMode || cfg.Scanner.WatchMode {
+		recentFiles = newRecentSet(time.Duration(cfg.Scanner.RecentWindow) * time.Second)
+	}
+
 	// Run initial scan (unless both watch and schedule are enabled, in which case schedule handles it)
+	checkpointing := checkpointRecord
+	if *resume {
+		checkpointing = checkpointResume
+	}
 	var scanResults *ScanResults
 	if !(*watchMode || cfg.Scanner.WatchMode) && !cfg.Scanner.ScheduleEnabled {
 		// Traditional mode: run scan once and exit
-		scanResults = runScan(ctx, cfg, tmdbClient, mdxWriter, *forceRefresh, *dryRun, *verbose)
-	} else if !cfg.Scanner.ScheduleEnabled {
+		scanResults = runScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, *forceRefresh, *dryRun, *verbose, sinceTime, *progress, checkpointing)
+	} else if !cfg.Scanner.ScheduleEnabled && !*skipInitialScan {
 		// Watch mode only: run initial scan before starting watcher
-		scanResults = runScan(ctx, cfg, tmdbClient, mdxWriter, *forceRefresh, *dryRun, *verbose)
+		scanResults = runScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, *forceRefresh, *dryRun, *verbose, sinceTime, *progress, checkpointing)
 	}
 	// If schedule is enabled (with or without watch), scheduler handles the initial scan
 
@@ -261,16 +486,30 @@ func main() {
 			slog.Info("starting watch mode")
 
 			// Create file handler that processes files using the existing pipeline
-			fileHandler := createFileHandler(cfg, tmdbClient, mdxWriter)
+			hook := newPostProcessHook(cfg.Options.PostProcessCommand, cfg.Options.PostProcessTimeout)
+
+			// Rebuild the site once a batch of new files has settled
+			var rebuild *buildDebouncer
+			if cfg.Output.AutoBuild && !*noBuild {
+				rebuild = newBuildDebouncer(time.Duration(cfg.Output.BuildDebounceSeconds)*time.Second, func() {
+					rebuildSite(cfg, "watch")
+				})
+			}
+			fileHandler := createFileHandler(cfg, tmdbClient, omdbClient, mdxWriter, hook, rebuild)
 
 			// Configure watcher
 			watcherCfg := scanner.WatcherConfig{
 				Directories:   cfg.Scanner.Directories,
 				Extensions:    cfg.Scanner.Extensions,
+				DirExtensions: cfg.DirectoryExtensions(),
 				MDXDir:        cfg.Output.MDXDir,
+				OutputDirs:    cfg.OutputDirs(),
 				ExcludeDirs:   cfg.Scanner.ExcludeDirs,
 				DebounceDelay: time.Duration(cfg.Scanner.WatchDebounce) * time.Second,
 				Recursive:     *cfg.Scanner.WatchRecursive,
+				SkipTVFolders: cfg.Scanner.SkipTVFolders,
+				MaxConcurrent: cfg.Scanner.ConcurrentWorkers,
+				IncludeBroken: cfg.Scanner.IncludeBrokenFiles,
 			}
 
 			watcher, err := scanner.NewWatcher(watcherCfg, fileHandler)
@@ -296,6 +535,7 @@ func main() {
 				if err := watcher.Stop(); err != nil {
 					slog.Error("error stopping watcher", "error", err)
 				}
+				rebuild.Stop()
 			}()
 		}
 
@@ -304,7 +544,7 @@ func main() {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				startScheduler(ctx, cfg, tmdbClient, mdxWriter, *verbose)
+				startScheduler(ctx, cfg, tmdbClient, omdbClient, mdxWriter, *verbose)
 			}()
 		}
 
@@ -349,24 +589,7 @@ func main() {
 
 		// Build Astro site if enabled and not disabled via flag
 		if cfg.Output.AutoBuild && !*noBuild && scanResults.SuccessCount > 0 {
-			slog.Info("building astro website")
-
-			// Sync content to Astro website (needed in Docker)
-			if err := syncContentToWebsite(cfg); err != nil {
-				slog.Error("failed to sync content to website", "error", err)
-				// Continue with build anyway - may work with existing files
-			}
-
-			websiteDir := cfg.Output.WebsiteDir
-			if websiteDir == "" {
-				websiteDir = "./website"
-			}
-			if err := buildAstroSite(websiteDir); err != nil {
-				slog.Error("failed to build astro site", "error", err, "website_dir", websiteDir)
-				slog.Info("manual build command", "command", fmt.Sprintf("cd %s && npm run build", websiteDir))
-			} else {
-				slog.Info("astro site built successfully")
-			}
+			rebuildSite(cfg, "scan")
 		}
 
 		if scanResults.ErrorCount > 0 {
@@ -576,22 +799,28 @@ func syncContentToWebsite(cfg *config.Config) error {
 		}
 	}
 
-	// Copy cover images
+	// Copy cover images, including shard subdirectories (output.covers_layout: sharded)
 	coverCount := 0
-	if entries, err := os.ReadDir(coversSrc); err == nil {
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-			src := filepath.Join(coversSrc, entry.Name())
-			dest := filepath.Join(coversDest, entry.Name())
-			if err := copyFile(src, dest); err != nil {
-				slog.Warn("failed to copy cover image", "file", entry.Name(), "error", err)
-			} else {
-				coverCount++
-			}
+	filepath.WalkDir(coversSrc, func(src string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
 		}
-	}
+		rel, err := filepath.Rel(coversSrc, src)
+		if err != nil {
+			return nil
+		}
+		dest := filepath.Join(coversDest, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			slog.Warn("failed to create cover directory", "dir", filepath.Dir(dest), "error", err)
+			return nil
+		}
+		if err := copyFile(src, dest); err != nil {
+			slog.Warn("failed to copy cover image", "file", rel, "error", err)
+		} else {
+			coverCount++
+		}
+		return nil
+	})
 
 	slog.Info("content synced to astro website",
 		"mdx_files", mdxCount,
@@ -636,9 +865,33 @@ func reloadNginx() error {
 	return nil
 }
 
-// mergeMovieData merges NFO data (priority) with TMDB data (fallback)
-func mergeMovieData(nfoMovie, tmdbMovie *writer.Movie) *writer.Movie {
+// mergeMovieData merges NFO data (priority) with TMDB data (fallback).
+// Locked NFOs are returned unchanged, even when fields are empty. When the NFO
+// and TMDB release years differ the conflict is logged, and preferTMDBYear
+// takes the TMDB year and date instead of the NFO's.
+func mergeMovieData(nfoMovie, tmdbMovie *writer.Movie, preferTMDBYear bool) *writer.Movie {
 	merged := nfoMovie
+	if merged.Locked {
+		return merged
+	}
+
+	if merged.ReleaseYear > 0 && tmdbMovie.ReleaseYear > 0 && merged.ReleaseYear != tmdbMovie.ReleaseYear {
+		using := "nfo"
+		if preferTMDBYear {
+			using = "tmdb"
+		}
+		slog.Info("NFO and TMDB release years differ",
+			"title", merged.Title,
+			"tmdb_id", tmdbMovie.TMDBID,
+			"nfo_year", merged.ReleaseYear,
+			"tmdb_year", tmdbMovie.ReleaseYear,
+			"using", using,
+		)
+		if preferTMDBYear {
+			merged.ReleaseYear = tmdbMovie.ReleaseYear
+			merged.ReleaseDate = tmdbMovie.ReleaseDate
+		}
+	}
 
 	// Fill missing fields from TMDB
 	if merged.Title == "" {
@@ -674,83 +927,234 @@ func mergeMovieData(nfoMovie, tmdbMovie *writer.Movie) *writer.Movie {
 	if merged.IMDbID == "" {
 		merged.IMDbID = tmdbMovie.IMDbID
 	}
+	if len(merged.Keywords) == 0 {
+		merged.Keywords = tmdbMovie.Keywords
+	}
+	if merged.Collection == "" {
+		merged.Collection = tmdbMovie.Collection
+	}
+	if len(merged.Studios) == 0 {
+		merged.Studios = tmdbMovie.Studios
+	}
+	if len(merged.Countries) == 0 {
+		merged.Countries = tmdbMovie.Countries
+	}
+	if merged.Budget == 0 {
+		merged.Budget = tmdbMovie.Budget
+	}
+	if merged.Revenue == 0 {
+		merged.Revenue = tmdbMovie.Revenue
+	}
+	if merged.PosterURL == "" {
+		merged.PosterURL = tmdbMovie.PosterURL
+	}
+	if merged.BackdropURL == "" {
+		merged.BackdropURL = tmdbMovie.BackdropURL
+	}
 
 	return merged
 }
 
+// parseSince converts a --since value into an absolute time.
+// Accepts RFC3339 timestamps, plain YYYY-MM-DD dates (local time), or a
+// Go duration (e.g. "24h", "90m") interpreted as relative to now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must be positive: %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("expected RFC3339 timestamp, YYYY-MM-DD date, or duration (e.g. 24h): %s", value)
+}
+
 // runTestParser tests title extraction on filenames without running a full scan (US-017)
 // Returns exit code: 0 if all extractions produced valid titles, 1 if any produced empty title
 func runTestParser() int {
+	if *parserFormat != "text" && *parserFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --format must be text or json (got %q)\n", *parserFormat)
+		return 1
+	}
+
 	filenames := flag.Args()
 
-	// If no arguments provided, read from stdin
-	if len(filenames) == 0 {
-		stdinReader := bufio.NewScanner(os.Stdin)
-		for stdinReader.Scan() {
-			line := stdinReader.Text()
-			if line != "" {
-				filenames = append(filenames, line)
-			}
+	if *parserFromFile != "" {
+		// Read filenames from a file, e.g. a regression corpus
+		f, err := os.Open(*parserFromFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to open --from-file: %v\n", err)
+			return 1
+		}
+		fromFile, err := readFilenames(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *parserFromFile, err)
+			return 1
 		}
-		if err := stdinReader.Err(); err != nil {
+		filenames = append(filenames, fromFile...)
+	} else if len(filenames) == 0 {
+		// If no arguments provided, read from stdin
+		fromStdin, err := readFilenames(os.Stdin)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
 			return 1
 		}
+		filenames = fromStdin
 	}
 
 	if len(filenames) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: scanner --test-parser <filename> [filename2] ...")
 		fmt.Fprintln(os.Stderr, "       echo 'filename.mkv' | scanner --test-parser")
+		fmt.Fprintln(os.Stderr, "       scanner --test-parser --from-file list.txt [--format=json]")
+		fmt.Fprintln(os.Stderr, "       scanner --test-parser --trace <filename>")
 		return 1
 	}
 
+	// Apply extra release groups and quality tokens when a config is available,
+	// so the parser output matches real scans
+	if _, err := os.Stat(*configPath); err == nil {
+		if cfg, err := config.LoadProfile(*configPath, *profile); err == nil {
+			scanner.ConfigureTokens(cfg.Scanner.ExtraReleaseGroups, cfg.Scanner.ExtraQualityTokens)
+			scanner.ConfigureReservedSlugs(cfg.Output.ReservedSlugs)
+			if cfg.Output.StripArticlesInSlug {
+				scanner.ConfigureSlugArticles(cfg.Output.Articles)
+			}
+		}
+	}
+
+	if hasEmptyTitle := printParserResults(os.Stdout, filenames, *parserFormat, *parserTrace); hasEmptyTitle {
+		return 1
+	}
+	return 0
+}
+
+// readFilenames returns the non-empty lines of r
+func readFilenames(r io.Reader) ([]string, error) {
+	var filenames []string
+	lineReader := bufio.NewScanner(r)
+	for lineReader.Scan() {
+		line := strings.TrimRight(lineReader.Text(), "\r")
+		if line != "" {
+			filenames = append(filenames, line)
+		}
+	}
+	return filenames, lineReader.Err()
+}
+
+// parserResult is one --test-parser --format=json line
+type parserResult struct {
+	Filename string              `json:"filename"`
+	Title    string              `json:"title"`
+	Year     int                 `json:"year"`
+	Slug     string              `json:"slug"`
+	Patterns []string            `json:"patterns"`
+	Trace    []scanner.ParseStep `json:"trace,omitempty"`
+}
+
+// printParserResults writes the parsed title, year, slug and matched pattern
+// categories for each filename as text blocks or NDJSON. Reports whether any
+// filename produced an empty title. With trace, the name after each stripping
+// step that changed it is shown too.
+func printParserResults(out io.Writer, filenames []string, format string, trace bool) bool {
 	hasEmptyTitle := false
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
 
 	for _, filename := range filenames {
-		title, year := scanner.ExtractTitleAndYear(filename)
+		title, year, steps := scanner.TraceTitleAndYear(filename)
 		slug := scanner.GenerateSlug(title, year)
+		if trace {
+			steps = changedParseSteps(filename, steps)
+		} else {
+			steps = nil
+		}
 
 		// Detect which patterns matched
 		patternsMatched := detectPatternsMatched(filename)
 
-		fmt.Printf("Filename: %s\n", filename)
-		fmt.Printf("  Title: %s\n", title)
+		if title == "" {
+			hasEmptyTitle = true
+		}
+
+		if format == "json" {
+			encoder.Encode(parserResult{
+				Filename: filename,
+				Title:    title,
+				Year:     year,
+				Slug:     slug,
+				Patterns: patternsMatched,
+				Trace:    steps,
+			})
+			continue
+		}
+
+		fmt.Fprintf(out, "Filename: %s\n", filename)
+		fmt.Fprintf(out, "  Title: %s\n", title)
 		if year > 0 {
-			fmt.Printf("  Year: %d\n", year)
+			fmt.Fprintf(out, "  Year: %d\n", year)
 		} else {
-			fmt.Printf("  Year: (not found)\n")
+			fmt.Fprintf(out, "  Year: (not found)\n")
 		}
-		fmt.Printf("  Slug: %s\n", slug)
+		fmt.Fprintf(out, "  Slug: %s\n", slug)
 		if len(patternsMatched) > 0 {
-			fmt.Printf("  Patterns matched: %s\n", patternsMatched)
+			fmt.Fprintf(out, "  Patterns matched: %s\n", strings.Join(patternsMatched, ", "))
 		} else {
-			fmt.Printf("  Patterns matched: (none)\n")
+			fmt.Fprintf(out, "  Patterns matched: (none)\n")
 		}
-		fmt.Println()
-
-		if title == "" {
-			hasEmptyTitle = true
+		if len(steps) > 0 {
+			fmt.Fprintf(out, "  Trace:\n")
+			for _, step := range steps {
+				fmt.Fprintf(out, "    %-16s %q\n", step.Step+":", step.Result)
+			}
 		}
+		fmt.Fprintln(out)
 	}
 
-	if hasEmptyTitle {
-		return 1
+	return hasEmptyTitle
+}
+
+// changedParseSteps drops the steps that left the name as it was, so the
+// trace only shows where something was removed
+func changedParseSteps(filename string, steps []scanner.ParseStep) []scanner.ParseStep {
+	var changed []scanner.ParseStep
+	previous := filename
+	for _, step := range steps {
+		if step.Result != previous {
+			changed = append(changed, step)
+		}
+		previous = step.Result
 	}
-	return 0
+	return changed
 }
 
 // runFindDuplicates scans MDX files and reports duplicate movies (US-024)
-// Returns exit code: count of duplicate sets found (0 if no duplicates)
+// Returns exit code: count of duplicate sets found (0 if no duplicates), or the
+// runDedupeAction exit code when --dedupe-action is trash or delete
 // US-025: Added quality comparison and --detailed flag support
 func runFindDuplicates() int {
 	// Load configuration to get MDX directory
-	cfg, err := config.Load(*configPath)
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
 		return 1
 	}
 
-	finder := scanner.NewDuplicateFinder(cfg.Output.MDXDir)
+	if *keepTop < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --keep-top must be at least 1 (got %d)\n", *keepTop)
+		return 1
+	}
+	if !validDedupeAction(*dedupeAction) {
+		fmt.Fprintf(os.Stderr, "Error: --dedupe-action must be report, trash, or delete (got %q)\n", *dedupeAction)
+		return 1
+	}
+
+	finder := scanner.NewDuplicateFinderWithOptions(cfg.Output.MDXDir, scanner.DuplicateFinderOptions{KeepTop: *keepTop})
 	duplicates, err := finder.FindDuplicates()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to find duplicates: %v\n", err)
@@ -760,13 +1164,25 @@ func runFindDuplicates() int {
 	// Print report with optional detailed mode (US-025)
 	scanner.PrintDuplicateReport(duplicates, *detailed)
 
+	if *dedupeAction != dedupeActionReport {
+		mdxWriter := writer.NewMDXWriterWithOptions(cfg.Output.MDXDir, cfg.Output.CoversDir, writer.MDXWriterOptions{
+			CoversLayout: cfg.Output.CoversLayout,
+		})
+		return runDedupeAction(duplicates, mdxWriter, dedupeOptions{
+			Action:    *dedupeAction,
+			TrashDir:  *trashDir,
+			DryRun:    *dryRun,
+			AssumeYes: *assumeYes,
+		}, os.Stdin, os.Stdout)
+	}
+
 	// Exit with count of duplicate sets
 	return len(duplicates)
 }
 
-// detectPatternsMatched returns a comma-separated list of pattern categories that matched
-func detectPatternsMatched(filename string) string {
-	var patterns []string
+// detectPatternsMatched returns the pattern categories that matched, in a fixed order
+func detectPatternsMatched(filename string) []string {
+	patterns := []string{}
 
 	// Remove extension for pattern matching (same as ExtractTitleAndYear)
 	name := filename
@@ -805,12 +1221,20 @@ func detectPatternsMatched(filename string) string {
 		patterns = append(patterns, "bracketed-group")
 	}
 
-	return strings.Join(patterns, ", ")
+	return patterns
 }
 
-// createFileHandler creates a handler function for processing new files in watch mode (US-022, US-027)
-func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWriter *writer.MDXWriter) scanner.FileHandler {
+// createFileHandler creates a handler function for processing new files in watch mode (US-022, US-027).
+// hook may be nil; callers that exit after processing should Wait on it.
+func createFileHandler(cfg *config.Config, tmdbClient metadata.MovieProvider, omdbClient *omdb.Client, mdxWriter *writer.MDXWriter, hook *postProcessHook, rebuild *buildDebouncer) scanner.FileHandler {
 	return func(file scanner.FileInfo) error {
+		rebuild.Begin()
+		defer rebuild.Done()
+
+		if !recentFiles.Claim(file.Path, recentSourceWatch) {
+			slog.Info("watch mode: skipping, file was just processed by a scan", "filename", file.FileName)
+			return nil
+		}
 		slog.Info("watch mode: processing file", "filename", file.FileName)
 
 		// Skip secondary discs when a disc-1 sibling exists in the same directory
@@ -821,25 +1245,30 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 			}
 		}
 
+		// Skip trailers, featurettes, and other bonus videos next to the main feature
+		if cfg.Scanner.ExtrasHandling == scanner.ExtrasSkip && scanner.IsExtra(file, cfg.Scanner.Extensions, cfg.Scanner.Directories) {
+			slog.Info("watch: skipping bonus video", "file", file.FileName)
+			return nil
+		}
+
 		// Fetch metadata from NFO or TMDB (same logic as main scan, US-027: verbose logging)
 		var movie *writer.Movie
 		var err error
 		var metadataSource string
 		var tmdbLookupMethod string
 
-		if cfg.Options.UseNFO {
-			nfoParser := nfo.NewParser()
+		useNFO, fallbackTMDB := cfg.MetadataSource(file.Path)
+		if useNFO {
+			nfoParser := nfo.NewParserWithOptions(nfo.ParserOptions{
+				PosterPreference: cfg.Options.NFOPosterPreference,
+				RatingSource:     cfg.Options.NFORatingSource,
+			})
 			movie, err = nfoParser.GetMovieFromNFO(file.Path)
 
 			if err != nil {
-				if cfg.Options.NFOFallbackTMDB {
-					slog.Debug("metadata lookup",
-						"file", file.FileName,
-						"nfo_status", "not_found_or_error",
-						"nfo_error", err.Error(),
-						"action", "fallback_to_tmdb",
-					)
-					movie, err = tmdbClient.GetFullMovieData(file.Title, file.Year)
+				if fallbackTMDB {
+					logNFOFallback(file, err)
+					movie, err = searchFileMovie(tmdbClient, file)
 					metadataSource = "TMDB"
 					tmdbLookupMethod = "search"
 				}
@@ -852,7 +1281,16 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 					"nfo_tmdb_id", movie.TMDBID,
 				)
 
-				if movie.TMDBID > 0 && cfg.Options.NFOFallbackTMDB {
+				// Search TMDB with the NFO's title/year; the filename may be mislabeled
+				searchTitle, searchYear := nfoSearchTitleYear(movie, file)
+
+				if movie.Locked {
+					slog.Debug("tmdb enrichment",
+						"file", file.FileName,
+						"method", "skipped",
+						"reason", "nfo_locked",
+					)
+				} else if movie.TMDBID > 0 && fallbackTMDB {
 					slog.Debug("tmdb enrichment",
 						"file", file.FileName,
 						"method", "direct_id_lookup",
@@ -867,29 +1305,32 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 								"reason", "direct_id_not_found",
 								"tmdb_id", movie.TMDBID,
 							)
-							tmdbMovie, tmdbErr = tmdbClient.GetFullMovieData(file.Title, file.Year)
+							tmdbMovie, tmdbErr = tmdbClient.GetFullMovieData(searchTitle, searchYear)
 							tmdbLookupMethod = "search (fallback from direct)"
 						}
 					} else {
 						tmdbLookupMethod = "direct ID"
 					}
 					if tmdbErr == nil && tmdbMovie != nil {
-						movie = mergeMovieData(movie, tmdbMovie)
+						movie = mergeMovieData(movie, tmdbMovie, cfg.Options.PreferTMDBYear)
 						metadataSource = "NFO+TMDB"
 					}
-				} else if cfg.Options.NFOFallbackTMDB && (movie.Title == "" || movie.ReleaseYear == 0) {
+				} else if fallbackTMDB && (movie.Title == "" || movie.ReleaseYear == 0) {
 					slog.Debug("tmdb enrichment",
 						"file", file.FileName,
 						"method", "search",
 						"reason", "nfo_incomplete",
 					)
-					tmdbMovie, tmdbErr := tmdbClient.GetFullMovieData(file.Title, file.Year)
+					tmdbMovie, tmdbErr := tmdbClient.GetFullMovieData(searchTitle, searchYear)
 					tmdbLookupMethod = "search"
 					if tmdbErr == nil && tmdbMovie != nil {
-						movie = mergeMovieData(movie, tmdbMovie)
+						movie = mergeMovieData(movie, tmdbMovie, cfg.Options.PreferTMDBYear)
 						metadataSource = "NFO+TMDB"
 					}
 				}
+
+				// Fields still missing after enrichment fall back to the filename
+				movie.Title, movie.ReleaseYear = nfoSearchTitleYear(movie, file)
 			}
 		} else {
 			slog.Debug("metadata lookup",
@@ -897,13 +1338,18 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 				"nfo_status", "disabled",
 				"action", "tmdb_search",
 			)
-			movie, err = tmdbClient.GetFullMovieData(file.Title, file.Year)
+			movie, err = searchFileMovie(tmdbClient, file)
 			metadataSource = "TMDB"
 			tmdbLookupMethod = "search"
 		}
 
 		if err != nil {
-			return fmt.Errorf("failed to fetch metadata: %w", err)
+			if !cfg.Options.WriteUnmatchedMDX || (mdxWriter.MDXExists(file.Slug) && !isUnmatchedPlaceholder(mdxWriter, file.Slug)) {
+				return fmt.Errorf("failed to fetch metadata: %w", err)
+			}
+			slog.Warn("watch: no metadata found, writing placeholder mdx", "file", file.FileName, "error", err)
+			movie = unmatchedMovie(file)
+			metadataSource = "unmatched"
 		}
 
 		// Log TMDB lookup method if used
@@ -915,13 +1361,56 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 		}
 
 		// Generate clean slug from metadata title
-		movie.Slug = scanner.GenerateSlug(movie.Title, movie.ReleaseYear)
+		movie.Slug = scanner.GenerateDiscSlug(movie.Title, movie.ReleaseYear, file.DiscNumber)
+
+		// Never-overwrite safety net: leave curated MDX (and its images) untouched
+		if cfg.Output.NeverOverwrite && mdxWriter.MDXExists(movie.Slug) {
+			slog.Info("watch: skipping, mdx exists and never_overwrite is enabled", "slug", movie.Slug, "file", file.FileName)
+			return nil
+		}
+
+		// Catch metadata drift: a search resolving the same slug to another TMDB entry
+		if strings.HasPrefix(tmdbLookupMethod, "search") {
+			if err := checkTMDBIDChange(cfg, mdxWriter, movie, file.FileName); err != nil {
+				return err
+			}
+		}
+
 		movie.FilePath = file.Path
 		movie.FileName = file.FileName
 		movie.FileSize = file.Size
+		// Watch mode sees local files through fsnotify, so they are read from disk
+		movie.FileHash = fileHash(cfg, scanner.FileFingerprint, file)
+		applyMediaInfo(movie, file)
+		movie.SourceDir = file.SourceDir
+		movie.Subtitles = file.Subtitles
+
+		if movie.Unmatched {
+			if err := mdxWriter.WriteMDXFile(movie); err != nil {
+				return fmt.Errorf("failed to write mdx file: %w", err)
+			}
+			rebuild.Changed()
+			slog.Info("watch mode: placeholder mdx written", "title", movie.Title, "slug", movie.Slug)
+			return nil
+		}
+
+		movie.Genres = writer.NormalizeGenres(movie.Genres, cfg.Options.GenreAliases, cfg.Options.MaxGenres)
+		movie.CollectionSlug = collectionSlug(movie.Collection)
+		if useNFO {
+			movie.NFOHash = scanner.NFOHash(file.Path)
+		}
+		if metadataSource == "TMDB" {
+			checkMatchConfidence(cfg, file, movie)
+		}
 
 		slog.Info("metadata fetched", "movie", movie.Title, "year", movie.ReleaseYear, "source", metadataSource)
 
+		// Optional OMDb ratings enrichment
+		enrichRatings(omdbClient, movie, file.FileName)
+
+		// Checked before the MDX is replaced, while it still names the old movie
+		replaceImages := *forceRefresh || staleArtwork(mdxWriter, movie)
+
 		// Download cover image (US-027: consistent verbose logging)
 		if cfg.Options.DownloadCovers {
 			coverPath := mdxWriter.GetAbsoluteCoverPath(movie.Slug)
@@ -929,7 +1418,8 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 
 			coverDownloaded := false
 			coverSource := ""
-			if cfg.Options.NFODownloadImages && movie.PosterURL != "" {
+			coverPresent := skipExistingImage(cfg, replaceImages, file.FileName, movie.Title, "cover", coverPath, movie.CoverImage)
+			if !coverPresent && cfg.Options.NFODownloadImages && movie.PosterURL != "" {
 				slog.Debug("image download attempt",
 					"file", file.FileName,
 					"movie", movie.Title,
@@ -949,7 +1439,7 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 					)
 				}
 			}
-			if !coverDownloaded {
+			if !coverPresent && !coverDownloaded {
 				slog.Debug("image download attempt",
 					"file", file.FileName,
 					"movie", movie.Title,
@@ -981,7 +1471,8 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 
 			backdropDownloaded := false
 			backdropSource := ""
-			if cfg.Options.NFODownloadImages && movie.BackdropURL != "" {
+			backdropPresent := skipExistingImage(cfg, replaceImages, file.FileName, movie.Title, "backdrop", backdropPath, movie.BackdropImage)
+			if !backdropPresent && cfg.Options.NFODownloadImages && movie.BackdropURL != "" {
 				slog.Debug("image download attempt",
 					"file", file.FileName,
 					"movie", movie.Title,
@@ -1001,7 +1492,7 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 					)
 				}
 			}
-			if !backdropDownloaded {
+			if !backdropPresent && !backdropDownloaded {
 				slog.Debug("image download attempt",
 					"file", file.FileName,
 					"movie", movie.Title,
@@ -1030,12 +1521,69 @@ func createFileHandler(cfg *config.Config, tmdbClient *metadata.Client, mdxWrite
 		if err := mdxWriter.WriteMDXFile(movie); err != nil {
 			return fmt.Errorf("failed to write mdx file: %w", err)
 		}
+		rebuild.Changed()
+		if cfg.Options.WriteUnmatchedMDX {
+			removeUnmatchedPlaceholder(mdxWriter, file.Slug, movie.Slug)
+		}
+
+		hookCoverPath := ""
+		if cfg.Options.DownloadCovers {
+			hookCoverPath = mdxWriter.GetAbsoluteCoverPath(movie.Slug)
+		}
+		hook.Run(movie, mdxWriter.GetMDXPath(movie.Slug), hookCoverPath)
 
 		slog.Info("watch mode: file processed successfully", "movie", movie.Title, "slug", movie.Slug)
 		return nil
 	}
 }
 
+// loadConfig loads the configuration from --config with the --profile
+// overrides, or from the environment alone when --env-config is set
+func loadConfig() (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if *envConfig {
+		cfg, err = config.LoadFromEnv()
+	} else {
+		cfg, err = loadConfigFile(*configPath, *profile, flagPassed("config"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDirOverrides(cfg, scanDirs, *dirAppend); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadConfigFile loads the config file at path. When the file does not exist
+// and explicit is false (--config left at its default), the configuration is
+// built from the environment instead; a missing file the user named is an error.
+func loadConfigFile(path, profile string, explicit bool) (*config.Config, error) {
+	cfg, err := config.LoadProfile(path, profile)
+	if explicit || !errors.Is(err, os.ErrNotExist) {
+		return cfg, err
+	}
+
+	slog.Info("config file not found, reading configuration from environment", "path", path, "prefix", config.EnvPrefix)
+	cfg, err = config.LoadFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("config file %s not found and environment configuration is incomplete: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// flagPassed reports whether the named flag was set on the command line
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
 // Helper function to repeat a string (not available in older Go versions)
 func repeat(s string, count int) string {
 	result := ""
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 6, 10, 15, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"0s", now, false},
+		{"-1h", time.Time{}, true},
+		{"2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local), false},
+		{"2024-06-01T08:00:00Z", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC), false},
+		{"2024-06-01T10:00:00+02:00", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC), false},
+		{"2024-13-01", time.Time{}, true},
+		{"7d", time.Time{}, true}, // Go durations have no day unit
+		{"yesterday", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tc := range testCases {
+		got, err := parseSince(tc.value, now)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseSince(%q) = %v, want an error", tc.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSince(%q) failed: %v", tc.value, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestLoadConfigFile_EnvFallback(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MOVIEVAULT_TMDB_API_KEY", "abc")
+	t.Setenv("MOVIEVAULT_SCAN_DIRS", "/movies")
+	t.Setenv("MOVIEVAULT_OUTPUT_MDX_DIR", filepath.Join(dir, "movies"))
+	t.Setenv("MOVIEVAULT_OUTPUT_COVERS_DIR", filepath.Join(dir, "covers"))
+	t.Setenv("MOVIEVAULT_CACHE_ENABLED", "false")
+	missing := filepath.Join(dir, "config.yaml")
+
+	// The default path falls back to the environment
+	cfg, err := loadConfigFile(missing, "", false)
+	if err != nil {
+		t.Fatalf("loadConfigFile(default) failed: %v", err)
+	}
+	if cfg.TMDB.APIKey != "abc" {
+		t.Errorf("APIKey = %q, want the environment value", cfg.TMDB.APIKey)
+	}
+
+	// A path passed with --config must exist
+	if _, err := loadConfigFile(missing, "", true); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("loadConfigFile(explicit) error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/metadata/cache"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// mediaProbeTTL is how long probe results stay cached. They are keyed by the
+// file fingerprint, so a changed file is probed again regardless.
+const mediaProbeTTL = 365 * 24 * time.Hour
+
+// mediaProbeMemoryLimit caps the in-memory results kept between the runtime
+// match and the technical fields of the same file
+const mediaProbeMemoryLimit = 256
+
+// mediaProbes runs ffprobe for the scan and watch pipelines when
+// options.use_ffprobe is enabled; nil otherwise
+var mediaProbes *mediaProber
+
+// mediaProber probes video files with ffprobe, caching results by file
+// fingerprint so unchanged files are not probed again. A nil *mediaProber
+// probes nothing.
+type mediaProber struct {
+	cache   cache.Cache // optional persistent cache
+	probe   func(path string) (*scanner.MediaInfo, error)
+	missing sync.Once
+
+	mu     sync.Mutex
+	recent map[string]*scanner.MediaInfo // fingerprint -> result
+}
+
+// newMediaProber creates a mediaProber backed by the metadata cache, which may be nil
+func newMediaProber(c cache.Cache) *mediaProber {
+	return &mediaProber{
+		cache:  c,
+		probe:  scanner.ProbeMediaInfo,
+		recent: make(map[string]*scanner.MediaInfo),
+	}
+}
+
+// Probe returns the technical information of file, or nil when ffprobe is
+// missing or fails. The first time ffprobe turns out to be missing a warning
+// is logged; failures on individual files are logged every time.
+func (p *mediaProber) Probe(file scanner.FileInfo) *scanner.MediaInfo {
+	if p == nil {
+		return nil
+	}
+
+	hash, err := scanner.FileFingerprint(file.Path)
+	if err != nil {
+		slog.Debug("failed to fingerprint video for probe cache", "file", file.FileName, "error", err)
+	} else if info := p.cached(hash); info != nil {
+		return info
+	}
+
+	info, err := p.probe(file.Path)
+	if err != nil {
+		if errors.Is(err, scanner.ErrFFprobeNotFound) {
+			p.missing.Do(func() {
+				slog.Warn("options.use_ffprobe is set but ffprobe is not installed; using filename tags instead")
+			})
+		} else {
+			slog.Warn("failed to probe video file", "file", file.FileName, "error", err)
+		}
+		return nil
+	}
+	if hash != "" {
+		p.store(hash, info)
+	}
+	return info
+}
+
+// cached returns a previous result for the fingerprint, or nil
+func (p *mediaProber) cached(hash string) *scanner.MediaInfo {
+	p.mu.Lock()
+	info := p.recent[hash]
+	p.mu.Unlock()
+	if info != nil || p.cache == nil {
+		return info
+	}
+
+	data, found := p.cache.Get("ffprobe:" + hash)
+	if !found {
+		return nil
+	}
+	var stored scanner.MediaInfo
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil
+	}
+	p.remember(hash, &stored)
+	return &stored
+}
+
+// store keeps a result in memory and in the persistent cache
+func (p *mediaProber) store(hash string, info *scanner.MediaInfo) {
+	p.remember(hash, info)
+	if p.cache == nil {
+		return
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	if err := p.cache.Set("ffprobe:"+hash, data, mediaProbeTTL); err != nil {
+		slog.Debug("failed to cache probe result", "error", err)
+	}
+}
+
+// remember keeps a result in memory, starting over when the limit is reached
+func (p *mediaProber) remember(hash string, info *scanner.MediaInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.recent) >= mediaProbeMemoryLimit {
+		p.recent = make(map[string]*scanner.MediaInfo)
+	}
+	p.recent[hash] = info
+}
+
+// applyMediaInfo sets the technical fields of movie from ffprobe, or from the
+// filename's release tags when ffprobe is unavailable. Does nothing unless
+// options.use_ffprobe is enabled.
+func applyMediaInfo(movie *writer.Movie, file scanner.FileInfo) {
+	if mediaProbes == nil {
+		return
+	}
+	info := mediaProbes.Probe(file)
+	if info == nil {
+		info = scanner.ExtractMediaInfo(file.FileName)
+	}
+	movie.Resolution = info.Resolution
+	movie.VideoCodec = info.VideoCodec
+	movie.AudioCodec = info.AudioCodec
+	movie.Channels = info.Channels
+	movie.Duration = int(info.Duration.Round(time.Second) / time.Second)
+}
+
+// hintedMatcher is implemented by providers that can use what the scanner
+// learned about a file, such as its runtime, to search for it
+type hintedMatcher interface {
+	GetFullMovieDataWithHints(title string, year int, hints metadata.SearchHints) (*writer.Movie, error)
+}
+
+// searchFileMovie looks up a file by its parsed title and year. With
+// options.use_ffprobe the video's duration picks among several search results;
+// when ffprobe is missing or fails, the regular search is used. A language
+// token in the filename is searched when the configured language finds nothing.
+func searchFileMovie(tmdbClient metadata.MovieProvider, file scanner.FileInfo) (*writer.Movie, error) {
+	matcher, ok := tmdbClient.(hintedMatcher)
+	if !ok {
+		return tmdbClient.GetFullMovieData(file.Title, file.Year)
+	}
+	hints := metadata.SearchHints{Language: file.Language}
+	if mediaProbes != nil {
+		if info := mediaProbes.Probe(file); info != nil && info.Duration > 0 {
+			hints.RuntimeMinutes = int(info.Duration.Round(time.Minute) / time.Minute)
+		}
+	}
+	return matcher.GetFullMovieDataWithHints(file.Title, file.Year, hints)
+}
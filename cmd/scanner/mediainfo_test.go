@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco/movieVault/internal/metadata/cache"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestMediaProber_CachesByFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	db, err := cache.NewSQLiteCache(filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(dir, "Dune.2021.mkv")
+	if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := scanner.FileInfo{Path: path, FileName: "Dune.2021.mkv"}
+
+	probes := 0
+	probe := func(string) (*scanner.MediaInfo, error) {
+		probes++
+		return &scanner.MediaInfo{Duration: 155 * time.Minute, VideoCodec: "hevc"}, nil
+	}
+	p := newMediaProber(db)
+	p.probe = probe
+	p.Probe(file)
+	p.Probe(file)
+	if probes != 1 {
+		t.Errorf("probes of an unchanged file = %d, want 1", probes)
+	}
+
+	// A new run is served from the persistent cache
+	p = newMediaProber(db)
+	p.probe = probe
+	if info := p.Probe(file); info == nil || info.VideoCodec != "hevc" {
+		t.Errorf("cached Probe = %+v, want hevc", info)
+	}
+	if probes != 1 {
+		t.Errorf("probes after restart = %d, want 1", probes)
+	}
+
+	// A changed file is probed again
+	if err := os.WriteFile(path, []byte("new video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.Probe(file)
+	if probes != 2 {
+		t.Errorf("probes after the file changed = %d, want 2", probes)
+	}
+}
+
+func TestApplyMediaInfo_FilenameFallback(t *testing.T) {
+	defer func() { mediaProbes = nil }()
+	mediaProbes = newMediaProber(nil)
+	mediaProbes.probe = func(string) (*scanner.MediaInfo, error) {
+		return nil, scanner.ErrFFprobeNotFound
+	}
+
+	movie := &writer.Movie{}
+	applyMediaInfo(movie, scanner.FileInfo{FileName: "Heat.1995.1080p.BluRay.x264.DTS.5.1-GRP.mkv"})
+	if movie.Resolution != "1080p" || movie.VideoCodec != "h264" || movie.AudioCodec != "dts" || movie.Channels != 6 || movie.Duration != 0 {
+		t.Errorf("fallback media info = %q %q %q %d %d", movie.Resolution, movie.VideoCodec, movie.AudioCodec, movie.Channels, movie.Duration)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// slugMigration is one planned MDX rename
+type slugMigration struct {
+	oldSlug string
+	newSlug string
+	movie   *writer.Movie
+}
+
+// runMigrateSlugs recomputes the slug of every MDX file with the current slug
+// rules and renames the MDX file and its cover and backdrop to match, updating
+// the slug, coverImage, and backdropImage frontmatter. Renames whose new slug
+// is already taken, or claimed by another file, are reported and skipped.
+// Returns exit code: 0 on success, 1 if any file collided or failed
+func runMigrateSlugs(cfg *config.Config, mdxWriter *writer.MDXWriter, dryRun bool) int {
+	mdxFiles, err := filepath.Glob(filepath.Join(cfg.Output.MDXDir, "*.mdx"))
+	if err != nil {
+		slog.Error("failed to list MDX files", "dir", cfg.Output.MDXDir, "error", err)
+		return 1
+	}
+
+	migrations, unchanged, failed := planSlugMigrations(mdxFiles)
+	migrations, collisions := rejectSlugCollisions(migrations, mdxFiles)
+
+	slog.Info("migrating slugs", "files", len(mdxFiles), "renames", len(migrations), "unchanged", unchanged, "collisions", len(collisions))
+
+	for _, c := range collisions {
+		slog.Error("slug collision, not renaming", "slug", c.oldSlug, "new_slug", c.newSlug)
+	}
+
+	if dryRun {
+		fmt.Println("\nDRY RUN MODE - No actual changes will be made")
+		for _, m := range migrations {
+			fmt.Printf("Would rename: %s -> %s\n", m.oldSlug, m.newSlug)
+		}
+		if len(collisions)+failed > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	var renamed int
+	for _, m := range migrations {
+		if err := migrateSlug(m, mdxWriter); err != nil {
+			slog.Error("failed to migrate slug", "slug", m.oldSlug, "new_slug", m.newSlug, "error", err)
+			failed++
+			continue
+		}
+		slog.Debug("slug migrated", "slug", m.oldSlug, "new_slug", m.newSlug)
+		renamed++
+	}
+
+	slog.Info("slug migration complete",
+		"renamed", renamed,
+		"unchanged", unchanged,
+		"collisions", len(collisions),
+		"failed", failed,
+	)
+
+	if len(collisions)+failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// planSlugMigrations reads each MDX file and returns the renames needed to
+// match the current slug rules, along with the number of files already up to
+// date and the number that could not be read
+func planSlugMigrations(mdxFiles []string) (migrations []slugMigration, unchanged, failed int) {
+	for _, mdxPath := range mdxFiles {
+		movie, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			slog.Error("failed to read MDX file", "path", mdxPath, "error", err)
+			failed++
+			continue
+		}
+
+		oldSlug := strings.TrimSuffix(filepath.Base(mdxPath), ".mdx")
+		newSlug := scanner.GenerateDiscSlug(movie.Title, movie.ReleaseYear, scanner.ExtractDiscNumber(movie.FileName))
+		if newSlug == "" || newSlug == oldSlug {
+			unchanged++
+			continue
+		}
+		migrations = append(migrations, slugMigration{oldSlug: oldSlug, newSlug: newSlug, movie: movie})
+	}
+	return migrations, unchanged, failed
+}
+
+// rejectSlugCollisions splits migrations into those safe to apply and those
+// whose new slug already has an MDX file or is the target of another rename
+func rejectSlugCollisions(migrations []slugMigration, mdxFiles []string) (safe, collisions []slugMigration) {
+	existing := make(map[string]bool, len(mdxFiles))
+	for _, mdxPath := range mdxFiles {
+		existing[strings.TrimSuffix(filepath.Base(mdxPath), ".mdx")] = true
+	}
+	claims := make(map[string]int)
+	for _, m := range migrations {
+		claims[m.newSlug]++
+	}
+
+	for _, m := range migrations {
+		if existing[m.newSlug] || claims[m.newSlug] > 1 {
+			collisions = append(collisions, m)
+			continue
+		}
+		safe = append(safe, m)
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i].oldSlug < collisions[j].oldSlug })
+	return safe, collisions
+}
+
+// migrateSlug moves the images of one movie, writes its MDX under the new
+// slug, and removes the old MDX file. Images are only moved when the
+// frontmatter points at the writer's local copy for the old slug.
+func migrateSlug(m slugMigration, mdxWriter *writer.MDXWriter) error {
+	movie := m.movie
+
+	if movie.CoverImage == mdxWriter.GetCoverPath(m.oldSlug) {
+		if err := moveImage(mdxWriter.GetAbsoluteCoverPath(m.oldSlug), mdxWriter.GetAbsoluteCoverPath(m.newSlug)); err != nil {
+			return fmt.Errorf("failed to move cover: %w", err)
+		}
+		movie.CoverImage = mdxWriter.GetCoverPath(m.newSlug)
+	}
+	if movie.BackdropImage == mdxWriter.GetBackdropPath(m.oldSlug) {
+		if err := moveImage(mdxWriter.GetAbsoluteBackdropPath(m.oldSlug), mdxWriter.GetAbsoluteBackdropPath(m.newSlug)); err != nil {
+			return fmt.Errorf("failed to move backdrop: %w", err)
+		}
+		movie.BackdropImage = mdxWriter.GetBackdropPath(m.newSlug)
+	}
+
+	movie.Slug = m.newSlug
+	if err := mdxWriter.WriteMDXFile(movie); err != nil {
+		return fmt.Errorf("failed to write MDX file: %w", err)
+	}
+	if err := mdxWriter.RemoveMDX(m.oldSlug); err != nil {
+		return fmt.Errorf("failed to remove old MDX file: %w", err)
+	}
+	return nil
+}
+
+// moveImage renames src to dest, creating dest's directory. A missing src is
+// not an error, since images may never have been downloaded.
+func moveImage(src, dest string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.Rename(src, dest)
+}
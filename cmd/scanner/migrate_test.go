@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestRunMigrateSlugs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Output.MDXDir = filepath.Join(dir, "movies")
+	mdxWriter := writer.NewMDXWriter(cfg.Output.MDXDir, filepath.Join(dir, "covers"))
+
+	// "heat" is outdated; both "alien" copies would become alien-1979
+	for _, m := range []struct{ slug, title string }{
+		{"heat", "Heat"},
+		{"alien-directors-cut", "Alien"},
+		{"alien-1979-old", "Alien"},
+		{"inception-2010", "Inception"},
+	} {
+		year := map[string]int{"Heat": 1995, "Alien": 1979, "Inception": 2010}[m.title]
+		movie := &writer.Movie{
+			Title: m.title, Slug: m.slug, ReleaseYear: year,
+			CoverImage: mdxWriter.GetCoverPath(m.slug), BackdropImage: "https://image.tmdb.org/t/p/original/b.jpg",
+		}
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(mdxWriter.GetAbsoluteCoverPath(m.slug)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(mdxWriter.GetAbsoluteCoverPath(m.slug), []byte("jpg"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dry run changes nothing but still reports the collision
+	if code := runMigrateSlugs(cfg, mdxWriter, true); code != 1 {
+		t.Errorf("dry run runMigrateSlugs() = %d, want 1", code)
+	}
+	if !mdxWriter.MDXExists("heat") || mdxWriter.MDXExists("heat-1995") {
+		t.Fatal("dry run renamed files")
+	}
+
+	if code := runMigrateSlugs(cfg, mdxWriter, false); code != 1 {
+		t.Errorf("runMigrateSlugs() = %d, want 1 for the alien collision", code)
+	}
+
+	if mdxWriter.MDXExists("heat") || !mdxWriter.MDXExists("heat-1995") {
+		t.Error("expected heat to be renamed to heat-1995")
+	}
+	movie, err := writer.ReadMDXFile(mdxWriter.GetMDXPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.Slug != "heat-1995" || movie.CoverImage != mdxWriter.GetCoverPath("heat-1995") {
+		t.Errorf("frontmatter not updated: slug %q cover %q", movie.Slug, movie.CoverImage)
+	}
+	if movie.BackdropImage != "https://image.tmdb.org/t/p/original/b.jpg" {
+		t.Errorf("remote backdrop changed to %q", movie.BackdropImage)
+	}
+	if _, err := os.Stat(mdxWriter.GetAbsoluteCoverPath("heat-1995")); err != nil {
+		t.Errorf("cover not moved: %v", err)
+	}
+
+	// Colliding copies are left alone
+	for _, slug := range []string{"alien-directors-cut", "alien-1979-old", "inception-2010"} {
+		if !mdxWriter.MDXExists(slug) {
+			t.Errorf("expected %s to be left in place", slug)
+		}
+	}
+}
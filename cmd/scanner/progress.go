@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth    = 30
+	progressMaxFileName = 40
+)
+
+// progressBar renders a single-line, self-overwriting progress bar with
+// percentage, ETA, and the file currently being processed.
+type progressBar struct {
+	out       io.Writer
+	total     int64
+	startTime time.Time
+}
+
+// newProgressBar creates a progress bar for total items written to out
+func newProgressBar(out io.Writer, total int64) *progressBar {
+	return &progressBar{
+		out:       out,
+		total:     total,
+		startTime: time.Now(),
+	}
+}
+
+// Render redraws the bar in place for the given progress
+func (p *progressBar) Render(current int64, currentFile string) {
+	fmt.Fprintf(p.out, "\r\033[K%s", p.format(current, currentFile, time.Since(p.startTime)))
+}
+
+// Finish draws the final state of the bar and moves to a new line
+func (p *progressBar) Finish(current int64) {
+	fmt.Fprintf(p.out, "\r\033[K%s\n", p.format(current, "", time.Since(p.startTime)))
+}
+
+// format builds the bar line; ETA is extrapolated from the average time per item so far
+func (p *progressBar) format(current int64, currentFile string, elapsed time.Duration) string {
+	if current > p.total {
+		current = p.total
+	}
+
+	fraction := 1.0
+	if p.total > 0 {
+		fraction = float64(current) / float64(p.total)
+	}
+
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	eta := "--"
+	if current > 0 && current < p.total {
+		remaining := time.Duration(float64(elapsed) / float64(current) * float64(p.total-current))
+		eta = remaining.Round(time.Second).String()
+	} else if current >= p.total {
+		eta = "0s"
+	}
+
+	line := fmt.Sprintf("[%s] %3.0f%% (%d/%d) ETA %s", bar, fraction*100, current, p.total, eta)
+	if currentFile != "" {
+		line += "  " + truncateFileName(currentFile, progressMaxFileName)
+	}
+	return line
+}
+
+// truncateFileName shortens name to at most max runes, marking the cut with "..."
+func truncateFileName(name string, max int) string {
+	runes := []rune(name)
+	if len(runes) <= max {
+		return name
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// isTerminal reports whether f is attached to an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// fakeProvider is an in-memory metadata.MovieProvider. Movies are looked up by
+// lowercase title (searches) or TMDB ID; downloads write a placeholder file.
+type fakeProvider struct {
+	mu        sync.Mutex
+	movies    map[int]*writer.Movie
+	details   map[int]*metadata.TMDBMovieDetails
+	searches  []string // titles passed to SearchMovie and GetFullMovieData
+	downloads []string // output paths written by the download methods
+}
+
+func newFakeProvider(movies ...*writer.Movie) *fakeProvider {
+	p := &fakeProvider{
+		movies:  make(map[int]*writer.Movie),
+		details: make(map[int]*metadata.TMDBMovieDetails),
+	}
+	for _, m := range movies {
+		p.movies[m.TMDBID] = m
+		p.details[m.TMDBID] = &metadata.TMDBMovieDetails{
+			ID:           m.TMDBID,
+			Title:        m.Title,
+			PosterPath:   fmt.Sprintf("/poster-%d.jpg", m.TMDBID),
+			BackdropPath: fmt.Sprintf("/backdrop-%d.jpg", m.TMDBID),
+		}
+	}
+	return p
+}
+
+func (p *fakeProvider) findByTitle(title string) *writer.Movie {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.searches = append(p.searches, title)
+	for _, m := range p.movies {
+		if m.Title == title {
+			return m
+		}
+	}
+	return nil
+}
+
+func (p *fakeProvider) SearchMovie(title string, year int) (*metadata.TMDBMovie, error) {
+	m := p.findByTitle(title)
+	if m == nil {
+		return nil, fmt.Errorf("no results found for '%s'", title)
+	}
+	details := p.details[m.TMDBID]
+	return &metadata.TMDBMovie{
+		ID:           m.TMDBID,
+		Title:        m.Title,
+		PosterPath:   details.PosterPath,
+		BackdropPath: details.BackdropPath,
+	}, nil
+}
+
+func (p *fakeProvider) GetMovieDetails(tmdbID int) (*metadata.TMDBMovieDetails, error) {
+	details, ok := p.details[tmdbID]
+	if !ok {
+		return nil, fmt.Errorf("TMDB API error (status 404): movie %d not found", tmdbID)
+	}
+	return details, nil
+}
+
+func (p *fakeProvider) GetMovieCredits(tmdbID int) (*metadata.TMDBCreditsResponse, error) {
+	if _, ok := p.movies[tmdbID]; !ok {
+		return nil, fmt.Errorf("TMDB API error (status 404): movie %d not found", tmdbID)
+	}
+	return &metadata.TMDBCreditsResponse{ID: tmdbID}, nil
+}
+
+func (p *fakeProvider) GetFullMovieData(title string, year int) (*writer.Movie, error) {
+	m := p.findByTitle(title)
+	if m == nil {
+		return nil, fmt.Errorf("no results found for '%s'", title)
+	}
+	movie := *m
+	return &movie, nil
+}
+
+func (p *fakeProvider) GetMovieByID(tmdbID int) (*writer.Movie, error) {
+	m, ok := p.movies[tmdbID]
+	if !ok {
+		return nil, fmt.Errorf("TMDB API error (status 404): movie %d not found", tmdbID)
+	}
+	movie := *m
+	return &movie, nil
+}
+
+func (p *fakeProvider) DownloadImage(imagePath string, outputPath string, imageType string) error {
+	return p.writePlaceholder(outputPath)
+}
+
+func (p *fakeProvider) DownloadImageFromURL(imageURL string, outputPath string) error {
+	return p.writePlaceholder(outputPath)
+}
+
+func (p *fakeProvider) writePlaceholder(outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, []byte("image"), 0644); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.downloads = append(p.downloads, outputPath)
+	p.mu.Unlock()
+	return nil
+}
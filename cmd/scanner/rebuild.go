@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+// buildDebouncer runs one site build once watch mode has written files and
+// then been quiet for a delay, so a batch of 20 new files triggers a single
+// build instead of 20. A nil *buildDebouncer does nothing.
+type buildDebouncer struct {
+	delay time.Duration
+	build func()
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	active  int  // files being processed
+	pending bool // files were written since the last build
+	stopped bool
+
+	running sync.WaitGroup // builds started by fire, awaited by Stop
+}
+
+// newBuildDebouncer creates a buildDebouncer that calls build after delay
+func newBuildDebouncer(delay time.Duration, build func()) *buildDebouncer {
+	return &buildDebouncer{delay: delay, build: build}
+}
+
+// Begin marks a file as being processed, holding back the build until it is done
+func (d *buildDebouncer) Begin() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active++
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// Changed records that the file being processed was written to the site
+func (d *buildDebouncer) Changed() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending = true
+}
+
+// Done marks a file as processed. When it was the last one and files were
+// written, the build is scheduled after the quiet period.
+func (d *buildDebouncer) Done() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if d.active > 0 || !d.pending || d.stopped {
+		return
+	}
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.delay, d.fire)
+	} else {
+		d.timer.Reset(d.delay)
+	}
+}
+
+// Stop cancels a scheduled build and waits for a running one to finish
+func (d *buildDebouncer) Stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+
+	d.running.Wait()
+}
+
+// fire runs the build unless files started processing again in the meantime
+func (d *buildDebouncer) fire() {
+	d.mu.Lock()
+	if d.active > 0 || !d.pending || d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.pending = false
+	// Registered while holding mu, so a Stop that follows waits for this build
+	d.running.Add(1)
+	d.mu.Unlock()
+
+	defer d.running.Done()
+	d.build()
+}
+
+// siteBuilds serializes site builds. With watch mode and scheduled scans both
+// enabled, two builds would otherwise write the same dist/ at once.
+var siteBuilds sync.Mutex
+
+// rebuildSite syncs content to the website and runs the Astro build, logging
+// the outcome. trigger names what asked for the build, e.g. "watch".
+func rebuildSite(cfg *config.Config, trigger string) {
+	siteBuilds.Lock()
+	defer siteBuilds.Unlock()
+
+	slog.Info("building astro website", "trigger", trigger)
+
+	// Sync content to Astro website (needed in Docker)
+	if err := syncContentToWebsite(cfg); err != nil {
+		slog.Error("failed to sync content to website", "error", err)
+		// Continue with build anyway - may work with existing files
+	}
+
+	websiteDir := cfg.Output.WebsiteDir
+	if websiteDir == "" {
+		websiteDir = "./website"
+	}
+	if err := buildAstroSite(websiteDir); err != nil {
+		slog.Error("failed to build astro site", "error", err, "website_dir", websiteDir)
+		slog.Info("manual build command", "command", fmt.Sprintf("cd %s && npm run build", websiteDir))
+	} else {
+		slog.Info("astro site built successfully")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBuildDebouncer(t *testing.T) {
+	var builds atomic.Int32
+	d := newBuildDebouncer(20*time.Millisecond, func() { builds.Add(1) })
+	defer d.Stop()
+
+	// A batch of files processed close together builds once
+	for i := 0; i < 3; i++ {
+		d.Begin()
+		d.Changed()
+		d.Done()
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("builds after batch = %d, want 1", n)
+	}
+
+	// Skipped files don't build
+	d.Begin()
+	d.Done()
+	time.Sleep(60 * time.Millisecond)
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("builds after skipped file = %d, want 1", n)
+	}
+
+	// A file still being processed holds the build back
+	d.Begin()
+	d.Changed()
+	d.Done()
+	d.Begin()
+	time.Sleep(60 * time.Millisecond)
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("builds while a file is processing = %d, want 1", n)
+	}
+	d.Done()
+	time.Sleep(60 * time.Millisecond)
+	if n := builds.Load(); n != 2 {
+		t.Fatalf("builds after the last file = %d, want 2", n)
+	}
+}
+
+func TestBuildDebouncer_Nil(t *testing.T) {
+	var d *buildDebouncer
+	d.Begin()
+	d.Changed()
+	d.Done()
+	d.Stop()
+}
+
+func TestBuildDebouncer_StopWaitsForBuild(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	d := newBuildDebouncer(time.Millisecond, func() {
+		close(started)
+		<-release
+	})
+
+	d.Begin()
+	d.Changed()
+	d.Done()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a build was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the build finished")
+	}
+
+	// Nothing builds after Stop; a second build would close started again and panic
+	d.Begin()
+	d.Changed()
+	d.Done()
+	d.fire()
+}
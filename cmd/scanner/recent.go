@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Sources that claim files in a recentSet
+const (
+	recentSourceWatch = "watch"
+	recentSourceScan  = "scan"
+)
+
+// recentFiles is shared by the watcher and full scans when watch mode runs, so
+// a file one of them just processed is not looked up again by the other
+var recentFiles *recentSet
+
+// recentClaim records which source last processed a file, and when
+type recentClaim struct {
+	source string
+	at     time.Time
+}
+
+// recentSet is an in-memory path -> last processed time map with a fixed
+// window. A nil *recentSet claims every file, which disables the check.
+type recentSet struct {
+	mu        sync.Mutex
+	window    time.Duration
+	claims    map[string]recentClaim
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// newRecentSet creates a recentSet that remembers files for window
+func newRecentSet(window time.Duration) *recentSet {
+	return &recentSet{
+		window: window,
+		claims: make(map[string]recentClaim),
+		now:    time.Now,
+	}
+}
+
+// Claim records that source is processing path and reports whether it should
+// go ahead: false when another source claimed the same path within the
+// window. A source never blocks itself, so repeated watch events still run.
+func (r *recentSet) Claim(path, source string) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastPrune) > r.window {
+		for p, claim := range r.claims {
+			if now.Sub(claim.at) > r.window {
+				delete(r.claims, p)
+			}
+		}
+		r.lastPrune = now
+	}
+
+	if claim, ok := r.claims[path]; ok && claim.source != source && now.Sub(claim.at) <= r.window {
+		return false
+	}
+	r.claims[path] = recentClaim{source: source, at: now}
+	return true
+}
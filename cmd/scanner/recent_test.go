@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecentSetClaim(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := newRecentSet(5 * time.Minute)
+	r.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		path    string
+		source  string
+		want    bool
+	}{
+		{"first claim", 0, "/movies/a.mkv", recentSourceWatch, true},
+		{"same source again", time.Second, "/movies/a.mkv", recentSourceWatch, true},
+		{"other source within window", time.Minute, "/movies/a.mkv", recentSourceScan, false},
+		{"other path", 0, "/movies/b.mkv", recentSourceScan, true},
+		{"other source after window", 10 * time.Minute, "/movies/a.mkv", recentSourceScan, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			if got := r.Claim(tt.path, tt.source); got != tt.want {
+				t.Errorf("Claim(%q, %q) = %v, want %v", tt.path, tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentSetClaim_Nil(t *testing.T) {
+	var r *recentSet
+	if !r.Claim("/movies/a.mkv", recentSourceScan) {
+		t.Error("nil recentSet should claim every file")
+	}
+}
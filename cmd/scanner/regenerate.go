@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// runRegenerate rewrites every existing MDX file using only cached TMDB responses.
+// No network requests are made, so MDX template changes can be applied offline.
+// Movies without a TMDB ID or whose cache entries have expired are skipped, as
+// are movies built from an NFO, whose curated fields TMDB data would replace.
+// Returns exit code: 0 on success, 1 if any MDX file could not be read or written
+func runRegenerate(cfg *config.Config, tmdbClient *metadata.Client, mdxWriter *writer.MDXWriter, dryRun bool) int {
+	mdxFiles, err := filepath.Glob(filepath.Join(cfg.Output.MDXDir, "*.mdx"))
+	if err != nil {
+		slog.Error("failed to list MDX files", "dir", cfg.Output.MDXDir, "error", err)
+		return 1
+	}
+
+	slog.Info("regenerating MDX files from cache", "count", len(mdxFiles))
+
+	var regenerated, skipped, failed int
+	for _, mdxPath := range mdxFiles {
+		existing, err := writer.ReadMDXFile(mdxPath)
+		if err != nil {
+			slog.Error("failed to read MDX file", "path", mdxPath, "error", err)
+			failed++
+			continue
+		}
+
+		if existing.TMDBID == 0 {
+			slog.Warn("skipping: no TMDB ID in frontmatter", "slug", existing.Slug)
+			skipped++
+			continue
+		}
+
+		// NFO titles, plots and ratings (and locked NFOs) are not in the TMDB
+		// cache; a rescan re-merges them when the NFO changes
+		if existing.NFOHash != "" {
+			slog.Info("skipping: metadata comes from an NFO", "slug", existing.Slug,
+				"suggestion", "run a scan with --force-refresh to rebuild it from the NFO")
+			skipped++
+			continue
+		}
+
+		movie, err := tmdbClient.GetCachedMovieByID(existing.TMDBID)
+		if err != nil {
+			if errors.Is(err, metadata.ErrCacheMiss) {
+				slog.Warn("skipping: cache entry missing or expired",
+					"slug", existing.Slug,
+					"tmdb_id", existing.TMDBID,
+					"suggestion", "run a scan with --force-refresh to repopulate the cache",
+				)
+			} else {
+				slog.Error("failed to load cached metadata", "slug", existing.Slug, "error", err)
+			}
+			skipped++
+			continue
+		}
+
+		// Preserve file- and scan-specific fields that are not part of the TMDB data
+		movie.Slug = existing.Slug
+		movie.CoverImage = existing.CoverImage
+		movie.BackdropImage = existing.BackdropImage
+		movie.FilePath = existing.FilePath
+		movie.FileName = existing.FileName
+		movie.FileSize = existing.FileSize
+		movie.FileHash = existing.FileHash
+		movie.Subtitles = existing.Subtitles
+		movie.Resolution = existing.Resolution
+		movie.VideoCodec = existing.VideoCodec
+		movie.AudioCodec = existing.AudioCodec
+		movie.Channels = existing.Channels
+		movie.Duration = existing.Duration
+		movie.SourceDir = existing.SourceDir
+		movie.ScannedAt = existing.ScannedAt
+		movie.IMDbRating = existing.IMDbRating
+		movie.RottenTomatoes = existing.RottenTomatoes
+		movie.Genres = writer.NormalizeGenres(movie.Genres, cfg.Options.GenreAliases, cfg.Options.MaxGenres)
+		if movie.Collection == "" {
+			movie.Collection = existing.Collection
+		}
+		movie.CollectionSlug = collectionSlug(movie.Collection)
+		movie.LowConfidence = existing.LowConfidence
+		if len(movie.Keywords) == 0 {
+			movie.Keywords = existing.Keywords
+		}
+
+		if dryRun {
+			fmt.Printf("Would regenerate: %s\n", filepath.Base(mdxPath))
+			regenerated++
+			continue
+		}
+
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			if errors.Is(err, writer.ErrMDXExists) {
+				slog.Info("skipping: never_overwrite is enabled", "slug", movie.Slug)
+				skipped++
+				continue
+			}
+			slog.Error("failed to write MDX file", "slug", movie.Slug, "error", err)
+			failed++
+			continue
+		}
+
+		slog.Debug("mdx file regenerated", "slug", movie.Slug)
+		regenerated++
+	}
+
+	slog.Info("regeneration complete",
+		"regenerated", regenerated,
+		"skipped", skipped,
+		"failed", failed,
+	)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
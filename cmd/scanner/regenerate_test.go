@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/metadata/cache"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestRunRegenerate_PreservesFileFields(t *testing.T) {
+	dir := t.TempDir()
+	db, err := cache.NewSQLiteCache(filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	combined := `{"id":949,"title":"Heat","release_date":"1995-12-15","runtime":170,"credits":{"id":949}}`
+	if err := db.Set("tmdb:movie_full:949:en-US", []byte(combined), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	client := metadata.NewClientWithConfig(metadata.ClientConfig{APIKey: "key", Cache: db})
+	defer client.Close()
+
+	cfg := &config.Config{}
+	cfg.Output.MDXDir = filepath.Join(dir, "movies")
+	mdxWriter := writer.NewMDXWriter(cfg.Output.MDXDir, filepath.Join(dir, "covers"))
+	existing := &writer.Movie{
+		Title: "Heat", Slug: "heat-1995", ReleaseYear: 1995, TMDBID: 949,
+		FilePath: "/media/Heat.1995.mkv", FileName: "Heat.1995.mkv", FileSize: 1024, FileHash: "abc123",
+		Resolution: "1080p", VideoCodec: "hevc", AudioCodec: "dts", Channels: 6, Duration: 10200,
+		LowConfidence: true,
+	}
+	if err := mdxWriter.WriteMDXFile(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runRegenerate(cfg, client, mdxWriter, false); code != 0 {
+		t.Fatalf("runRegenerate() = %d, want 0", code)
+	}
+
+	got, err := writer.ReadMDXFile(mdxWriter.GetMDXPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Runtime != 170 {
+		t.Errorf("Runtime = %d, want 170 from the cache", got.Runtime)
+	}
+	if got.FileSize != 1024 || got.FileHash != "abc123" || got.Resolution != "1080p" || got.VideoCodec != "hevc" ||
+		got.AudioCodec != "dts" || got.Channels != 6 || got.Duration != 10200 {
+		t.Errorf("file fields not preserved: %+v", got)
+	}
+	if !got.LowConfidence {
+		t.Error("lowConfidence flag cleared by regenerate")
+	}
+}
+
+func TestRunRegenerate_SkipsNFOMovies(t *testing.T) {
+	dir := t.TempDir()
+	db, err := cache.NewSQLiteCache(filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	combined := `{"id":949,"title":"Heat","overview":"TMDB plot.","release_date":"1995-12-15","vote_average":8.3,"credits":{"id":949}}`
+	if err := db.Set("tmdb:movie_full:949:en-US", []byte(combined), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	client := metadata.NewClientWithConfig(metadata.ClientConfig{APIKey: "key", Cache: db})
+	defer client.Close()
+
+	cfg := &config.Config{}
+	cfg.Output.MDXDir = filepath.Join(dir, "movies")
+	mdxWriter := writer.NewMDXWriter(cfg.Output.MDXDir, filepath.Join(dir, "covers"))
+	curated := &writer.Movie{
+		Title: "Heat (Director's Definitive Edition)", Slug: "heat-1995", ReleaseYear: 1995, TMDBID: 949,
+		Description: "Curated plot.", Rating: 9.1, NFOHash: "f00d",
+	}
+	if err := mdxWriter.WriteMDXFile(curated); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runRegenerate(cfg, client, mdxWriter, false); code != 0 {
+		t.Fatalf("runRegenerate() = %d, want 0", code)
+	}
+
+	got, err := writer.ReadMDXFile(mdxWriter.GetMDXPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != curated.Title || got.Description != "Curated plot." || got.Rating != 9.1 || got.NFOHash != "f00d" {
+		t.Errorf("NFO-sourced MDX rewritten from TMDB: %+v", got)
+	}
+}
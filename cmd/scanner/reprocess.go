@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/metadata/omdb"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// runReprocess re-resolves metadata for a single movie and rewrites its MDX
+// file, even if one already exists. target is either a video file path or the
+// slug of an existing MDX file, in which case the frontmatter filePath is used.
+// Combine with --force-refresh to bypass cached TMDB responses.
+// Returns exit code: 0 on success, 1 if the target could not be resolved or processed
+func runReprocess(cfg *config.Config, tmdbClient metadata.MovieProvider, omdbClient *omdb.Client, mdxWriter *writer.MDXWriter, target string, dryRun bool) int {
+	path, err := resolveReprocessTarget(target, mdxWriter)
+	if err != nil {
+		slog.Error("failed to resolve reprocess target", "target", target, "error", err)
+		return 1
+	}
+
+	file, err := reprocessFileInfo(path, cfg.Scanner.Directories)
+	if err != nil {
+		slog.Error("failed to read video file", "path", path, "error", err)
+		return 1
+	}
+
+	slog.Info("reprocessing file", "file", file.FileName, "title", file.Title, "year", file.Year)
+
+	if dryRun {
+		fmt.Printf("Would reprocess: %s (parsed as %q, year %d)\n", file.Path, file.Title, file.Year)
+		return 0
+	}
+
+	hook := newPostProcessHook(cfg.Options.PostProcessCommand, cfg.Options.PostProcessTimeout)
+	err = createFileHandler(cfg, tmdbClient, omdbClient, mdxWriter, hook, nil)(file)
+	hook.Wait()
+	if err != nil {
+		slog.Error("failed to reprocess file", "file", file.FileName, "error", err)
+		return 1
+	}
+	return 0
+}
+
+// resolveReprocessTarget returns the video file path for target. Existing
+// files are used as-is; anything else is treated as a slug and looked up in
+// the MDX directory.
+func resolveReprocessTarget(target string, mdxWriter *writer.MDXWriter) (string, error) {
+	if info, err := os.Stat(target); err == nil {
+		if info.IsDir() {
+			return "", fmt.Errorf("%s is a directory", target)
+		}
+		return target, nil
+	}
+
+	if !mdxWriter.MDXExists(target) {
+		return "", fmt.Errorf("no such file and no MDX file for slug %q", target)
+	}
+	existing, err := writer.ReadMDXFile(mdxWriter.GetMDXPath(target))
+	if err != nil {
+		return "", err
+	}
+	if existing.FilePath == "" {
+		return "", fmt.Errorf("MDX file for slug %q has no filePath", target)
+	}
+	return existing.FilePath, nil
+}
+
+// reprocessFileInfo builds the scanner.FileInfo for path the same way the
+// watcher does. SourceDir is set to the configured directory containing path,
+// if any.
+func reprocessFileInfo(path string, directories []string) (scanner.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return scanner.FileInfo{}, err
+	}
+
+	filename := filepath.Base(path)
+	title, year := scanner.ExtractTitleAndYear(filename)
+	discNumber := scanner.ExtractDiscNumber(filename)
+
+	file := scanner.FileInfo{
+		Path:       path,
+		FileName:   filename,
+		Title:      title,
+		Year:       year,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		Slug:       scanner.GenerateDiscSlug(title, year, discNumber),
+		DiscNumber: discNumber,
+		ShouldScan: true,
+		Subtitles:  scanner.FindSubtitles(path),
+		Language:   scanner.DetectLanguage(filename),
+	}
+
+	for _, dir := range directories {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			file.SourceDir = dir
+			break
+		}
+	}
+	return file, nil
+}
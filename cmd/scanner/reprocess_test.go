@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestRunReprocess(t *testing.T) {
+	dir := t.TempDir()
+	mediaDir := filepath.Join(dir, "media")
+	videoPath := filepath.Join(mediaDir, "Inception.2010.1080p.BluRay.mkv")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(videoPath, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Scanner.Directories = []string{mediaDir}
+	mdxWriter := writer.NewMDXWriter(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"))
+
+	// An existing MDX with stale metadata is rewritten, not skipped
+	stale := &writer.Movie{Title: "Inception", Slug: "inception-2010", ReleaseYear: 2010, Description: "stale", FilePath: videoPath}
+	if err := mdxWriter.WriteMDXFile(stale); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"by path", videoPath, 0},
+		{"by slug", "inception-2010", 0},
+		{"unknown slug", "no-such-movie-1999", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newFakeProvider(&writer.Movie{Title: "Inception", ReleaseYear: 2010, TMDBID: 27205, Description: "fresh"})
+			if code := runReprocess(cfg, provider, nil, mdxWriter, tc.target, false); code != tc.want {
+				t.Fatalf("runReprocess(%q) = %d, want %d", tc.target, code, tc.want)
+			}
+			if tc.want != 0 {
+				return
+			}
+
+			movie, err := writer.ReadMDXFile(mdxWriter.GetMDXPath("inception-2010"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if movie.Description != "fresh" || movie.TMDBID != 27205 {
+				t.Errorf("MDX not refreshed: description %q, tmdb id %d", movie.Description, movie.TMDBID)
+			}
+			if movie.FilePath != videoPath || movie.SourceDir != mediaDir {
+				t.Errorf("file info = %q in %q, want %q in %q", movie.FilePath, movie.SourceDir, videoPath, mediaDir)
+			}
+		})
+	}
+}
@@ -4,28 +4,91 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/marco/movieVault/internal/config"
 	"github.com/marco/movieVault/internal/metadata"
 	"github.com/marco/movieVault/internal/metadata/nfo"
+	"github.com/marco/movieVault/internal/metadata/omdb"
 	"github.com/marco/movieVault/internal/scanner"
 	"github.com/marco/movieVault/internal/writer"
 )
 
 // ScanResults holds the outcome of a scan operation
 type ScanResults struct {
-	TotalFiles     int
-	ProcessedFiles int
-	SuccessCount   int
-	ErrorCount     int
-	NFOCount       int
-	TMDBCount      int
-	MixedCount     int
-	Duration       time.Duration
-	Errors         []error
+	TotalFiles        int
+	ProcessedFiles    int
+	SuccessCount      int // Files that produced an MDX write
+	SkippedDuplicates int // Files whose slug was already produced by another file this run
+	ErrorCount        int
+	NFOCount          int
+	TMDBCount         int
+	MixedCount        int
+	UnmatchedCount    int            // Placeholder MDX written because no metadata was found
+	SkipReasons       map[string]int // Files left out of processing or writing, by skip* reason
+	Duration          time.Duration
+	Errors            []error
+}
+
+// Reasons recorded in ScanResults.SkipReasons
+const (
+	skipMDXExists      = "mdx exists"
+	skipNotModified    = "not modified since"
+	skipSecondaryDisc  = "multi-disc secondary"
+	skipExtra          = "extra"
+	skipRecentlyWatch  = "just processed by watch"
+	skipDuplicateSlug  = "duplicate slug"
+	skipNeverOverwrite = "never overwrite"
+	skipResumed        = "done before interruption"
+	skipBroken         = "empty or unreadable"
+)
+
+// errNeverOverwrite marks a file whose MDX exists while never_overwrite is
+// enabled; it is counted as skipped, not as an error
+var errNeverOverwrite = errors.New("mdx exists and never_overwrite is enabled")
+
+// addSkipped records count files skipped for reason
+func (r *ScanResults) addSkipped(reason string, count int) {
+	if count <= 0 {
+		return
+	}
+	if r.SkipReasons == nil {
+		r.SkipReasons = make(map[string]int)
+	}
+	r.SkipReasons[reason] += count
+}
+
+// formatSkipReasons renders skip reasons as "reason: count" pairs, most
+// common first, e.g. "mdx exists: 4790, extra: 12"
+func formatSkipReasons(reasons map[string]int) string {
+	keys := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		keys = append(keys, reason)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if reasons[keys[i]] != reasons[keys[j]] {
+			return reasons[keys[i]] > reasons[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, reason := range keys {
+		parts[i] = fmt.Sprintf("%s: %d", reason, reasons[reason])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// logSkipBreakdown logs why files found by the scan were not written
+func logSkipBreakdown(results *ScanResults) {
+	if len(results.SkipReasons) > 0 {
+		slog.Info("skipped breakdown", "reasons", formatSkipReasons(results.SkipReasons))
+	}
 }
 
 // runScan performs a full directory scan with concurrent processing
@@ -33,17 +96,51 @@ type ScanResults struct {
 func runScan(
 	ctx context.Context,
 	cfg *config.Config,
-	tmdbClient *metadata.Client,
+	tmdbClient metadata.MovieProvider,
+	omdbClient *omdb.Client,
 	mdxWriter *writer.MDXWriter,
 	forceRefresh bool,
 	dryRun bool,
 	verbose bool,
+	since time.Time,
+	showProgress bool,
+	checkpointing checkpointMode,
 ) *ScanResults {
 	startTime := time.Now()
 	results := &ScanResults{}
 
+	// Files finished by an interrupted scan are skipped without an MDX check
+	var completed map[string]bool
+	if checkpointing == checkpointResume {
+		var err error
+		if completed, err = loadScanCheckpoint(checkpointPath(cfg)); err != nil {
+			slog.Warn("cannot resume, scanning everything", "error", err)
+		} else {
+			slog.Info("resuming interrupted scan", "completed_files", len(completed))
+		}
+	}
+
+	// Rescan on .nfo edits for the files whose metadata is read from one
+	var trackNFO func(string) bool
+	if cfg.UsesNFO() {
+		trackNFO = func(path string) bool {
+			useNFO, _ := cfg.MetadataSource(path)
+			return useNFO
+		}
+	}
+
 	// Create scanner with directory exclusions
-	s := scanner.NewWithExclusions(cfg.Scanner.Extensions, cfg.Output.MDXDir, cfg.Scanner.ExcludeDirs)
+	s := scanner.NewWithOptions(cfg.Scanner.Extensions, cfg.Output.MDXDir, scanner.Options{
+		ExcludeDirs:        cfg.Scanner.ExcludeDirs,
+		SkipTVFolders:      cfg.Scanner.SkipTVFolders,
+		RefreshOlderThan:   time.Duration(cfg.Options.RefreshOlderThanDays) * 24 * time.Hour,
+		RescanUnmatched:    cfg.Options.WriteUnmatchedMDX,
+		TrackNFOChanges:    trackNFO,
+		DirExtensions:      cfg.DirectoryExtensions(),
+		OutputDirs:         cfg.OutputDirs(),
+		Completed:          completed,
+		IncludeBrokenFiles: cfg.Scanner.IncludeBrokenFiles,
+	})
 
 	// Scan all directories
 	slog.Info("scanning directories for video files", "count", len(cfg.Scanner.Directories))
@@ -57,6 +154,22 @@ func runScan(
 
 	slog.Info("scan complete", "files_found", len(files))
 	results.TotalFiles = len(files)
+	results.addSkipped(skipBroken, s.BrokenFiles())
+
+	// Record finished files until the scan completes, for --resume
+	var checkpoint *scanCheckpoint
+	if !dryRun && checkpointing != checkpointNone {
+		if checkpoint, err = openScanCheckpoint(checkpointPath(cfg), checkpointing == checkpointResume); err != nil {
+			slog.Warn("scan progress will not be recorded", "error", err)
+		}
+		defer func() {
+			if ctx.Err() == nil {
+				checkpoint.Clear()
+			} else {
+				checkpoint.Close()
+			}
+		}()
+	}
 
 	// Filter out secondary discs (CD2+) when CD1 exists in the same directory
 	files, skippedDiscs := scanner.FilterMultiDiscDuplicates(files)
@@ -64,10 +177,31 @@ func runScan(
 		slog.Info("multi-disc: skipping secondary disc",
 			"file", skip.FileName, "disc", skip.DiscNumber, "kept", skip.KeptFile)
 	}
+	results.addSkipped(skipSecondaryDisc, len(skippedDiscs))
+
+	// Skip trailers, featurettes, and other bonus videos next to the main feature
+	if cfg.Scanner.ExtrasHandling == scanner.ExtrasSkip {
+		var skippedExtras []scanner.SkippedExtra
+		files, skippedExtras = scanner.FilterExtras(files)
+		for _, skip := range skippedExtras {
+			slog.Info("extras: skipping bonus video",
+				"file", skip.FileName, "reason", skip.Reason, "kept", skip.KeptFile)
+		}
+		results.addSkipped(skipExtra, len(skippedExtras))
+	}
 
-	// Filter files based on force-refresh flag
+	// Filter files based on --since, force-refresh, or MDX existence
 	var filesToProcess []scanner.FileInfo
-	if forceRefresh {
+	if !since.IsZero() {
+		// --since selects by modification time alone, regardless of existing MDX
+		filesToProcess = scanner.FilterModifiedSince(files, since)
+		slog.Info("filtering by modification time",
+			"since", since.Format(time.RFC3339),
+			"matched", len(filesToProcess),
+			"skipped", len(files)-len(filesToProcess),
+		)
+		results.addSkipped(skipNotModified, len(files)-len(filesToProcess))
+	} else if forceRefresh {
 		filesToProcess = files
 		slog.Info("force refresh enabled", "processing_all", true)
 	} else {
@@ -80,12 +214,50 @@ func runScan(
 		if skippedCount > 0 {
 			slog.Info("skipping existing files", "count", skippedCount)
 		}
+		results.addSkipped(skipMDXExists, skippedCount)
+	}
+
+	// --force-refresh and --since select files regardless of ShouldScan
+	if len(completed) > 0 {
+		remaining := filesToProcess[:0]
+		for _, file := range filesToProcess {
+			if !completed[file.Path] {
+				remaining = append(remaining, file)
+			}
+		}
+		results.addSkipped(skipResumed, len(filesToProcess)-len(remaining))
+		filesToProcess = remaining
 	}
 
+	// Skip files the watcher processed moments ago (watch + schedule)
+	if !dryRun && recentFiles != nil {
+		claimed := filesToProcess[:0]
+		for _, file := range filesToProcess {
+			if recentFiles.Claim(file.Path, recentSourceScan) {
+				claimed = append(claimed, file)
+			}
+		}
+		if skipped := len(filesToProcess) - len(claimed); skipped > 0 {
+			slog.Info("skipping files just processed by watch mode", "count", skipped)
+		}
+		results.addSkipped(skipRecentlyWatch, len(filesToProcess)-len(claimed))
+		filesToProcess = claimed
+	}
+
+	// --force-refresh and --since also pick files ScanAll did not read or look
+	// up subtitles for
+	filesToProcess, unreadable := s.FilterUnreadable(filesToProcess)
+	results.addSkipped(skipBroken, unreadable)
+	s.AttachSubtitles(filesToProcess)
+
 	results.ProcessedFiles = len(filesToProcess)
 
 	if len(filesToProcess) == 0 {
 		slog.Info("no new files to process")
+		logSkipBreakdown(results)
+		if !dryRun {
+			writeTaxonomy(cfg)
+		}
 		results.Duration = time.Since(startTime)
 		return results
 	}
@@ -107,22 +279,42 @@ func runScan(
 		return results
 	}
 
-	// Progress reporter
+	// Progress reporter: a live bar on interactive terminals (--progress),
+	// periodic log lines otherwise
 	var processedCount int64
+	var currentFile atomic.Value
 	totalFiles := int64(len(filesToProcess))
+	var bar *progressBar
+	progressInterval := 2 * time.Second
+	if showProgress {
+		if isTerminal(os.Stdout) {
+			bar = newProgressBar(os.Stdout, totalFiles)
+			progressInterval = 250 * time.Millisecond
+		} else {
+			slog.Debug("progress bar disabled: stdout is not a terminal")
+		}
+	}
+	stopProgress := make(chan struct{})
 	progressDone := make(chan struct{})
 	go func() {
 		defer close(progressDone)
-		ticker := time.NewTicker(2 * time.Second)
+		ticker := time.NewTicker(progressInterval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
 				current := atomic.LoadInt64(&processedCount)
-				if current > 0 && current < totalFiles {
+				if bar != nil {
+					if current < totalFiles {
+						name, _ := currentFile.Load().(string)
+						bar.Render(current, name)
+					}
+				} else if current > 0 && current < totalFiles {
 					slog.Info("progress", "processed", current, "total", totalFiles,
 						"percent", fmt.Sprintf("%.0f%%", float64(current)/float64(totalFiles)*100))
 				}
+			case <-stopProgress:
+				return
 			case <-ctx.Done():
 				return
 			}
@@ -132,8 +324,17 @@ func runScan(
 	// Create SlugGuard for thread-safe slug deduplication
 	slugGuard := scanner.NewSlugGuard()
 
+	// Image downloads are I/O bound rather than API-rate-limited, so they run on
+	// their own pool sized independently of the metadata workers
+	imagePool := scanner.NewTaskPool(cfg.Scanner.ImageDownloadWorkers)
+
+	// Optional user command run per movie once its images are in place
+	hook := newPostProcessHook(cfg.Options.PostProcessCommand, cfg.Options.PostProcessTimeout)
+
 	// Define per-file processing function
 	processFn := func(ctx context.Context, file scanner.FileInfo) (string, string, error) {
+		currentFile.Store(file.FileName)
+
 		slog.Debug("file details",
 			"title", file.Title,
 			"year", file.Year,
@@ -146,19 +347,18 @@ func runScan(
 		var metadataSource string
 
 		var tmdbLookupMethod string
-		if cfg.Options.UseNFO {
-			nfoParser := nfo.NewParser()
+		useNFO, fallbackTMDB := cfg.MetadataSource(file.Path)
+		if useNFO {
+			nfoParser := nfo.NewParserWithOptions(nfo.ParserOptions{
+				PosterPreference: cfg.Options.NFOPosterPreference,
+				RatingSource:     cfg.Options.NFORatingSource,
+			})
 			movie, err = nfoParser.GetMovieFromNFO(file.Path)
 
 			if err != nil {
-				if cfg.Options.NFOFallbackTMDB {
-					slog.Debug("metadata lookup",
-						"file", file.FileName,
-						"nfo_status", "not_found_or_error",
-						"nfo_error", err.Error(),
-						"action", "fallback_to_tmdb",
-					)
-					movie, err = tmdbClient.GetFullMovieData(file.Title, file.Year)
+				if fallbackTMDB {
+					logNFOFallback(file, err)
+					movie, err = searchFileMovie(tmdbClient, file)
 					metadataSource = "TMDB"
 					tmdbLookupMethod = "search"
 				}
@@ -171,7 +371,16 @@ func runScan(
 					"nfo_tmdb_id", movie.TMDBID,
 				)
 
-				if movie.TMDBID > 0 && cfg.Options.NFOFallbackTMDB {
+				// Search TMDB with the NFO's title/year; the filename may be mislabeled
+				searchTitle, searchYear := nfoSearchTitleYear(movie, file)
+
+				if movie.Locked {
+					slog.Debug("tmdb enrichment",
+						"file", file.FileName,
+						"method", "skipped",
+						"reason", "nfo_locked",
+					)
+				} else if movie.TMDBID > 0 && fallbackTMDB {
 					slog.Debug("tmdb enrichment",
 						"file", file.FileName,
 						"method", "direct_id_lookup",
@@ -185,17 +394,17 @@ func runScan(
 								"method", "search_fallback",
 								"reason", "direct_id_not_found",
 								"tmdb_id", movie.TMDBID,
-								"search_title", file.Title,
-								"search_year", file.Year,
+								"search_title", searchTitle,
+								"search_year", searchYear,
 							)
-							tmdbMovie, tmdbErr = tmdbClient.GetFullMovieData(file.Title, file.Year)
+							tmdbMovie, tmdbErr = tmdbClient.GetFullMovieData(searchTitle, searchYear)
 							tmdbLookupMethod = "search (fallback from direct)"
 						}
 					} else {
 						tmdbLookupMethod = "direct ID"
 					}
 					if tmdbErr == nil && tmdbMovie != nil {
-						movie = mergeMovieData(movie, tmdbMovie)
+						movie = mergeMovieData(movie, tmdbMovie, cfg.Options.PreferTMDBYear)
 						metadataSource = "NFO+TMDB"
 						slog.Debug("metadata merge",
 							"file", file.FileName,
@@ -203,20 +412,20 @@ func runScan(
 							"tmdb_fields_filled", "missing_fields_only",
 						)
 					}
-				} else if cfg.Options.NFOFallbackTMDB && (movie.Title == "" || movie.ReleaseYear == 0) {
+				} else if fallbackTMDB && (movie.Title == "" || movie.ReleaseYear == 0) {
 					slog.Debug("tmdb enrichment",
 						"file", file.FileName,
 						"method", "search",
 						"reason", "nfo_incomplete",
 						"missing_title", movie.Title == "",
 						"missing_year", movie.ReleaseYear == 0,
-						"search_title", file.Title,
-						"search_year", file.Year,
+						"search_title", searchTitle,
+						"search_year", searchYear,
 					)
-					tmdbMovie, tmdbErr := tmdbClient.GetFullMovieData(file.Title, file.Year)
+					tmdbMovie, tmdbErr := tmdbClient.GetFullMovieData(searchTitle, searchYear)
 					tmdbLookupMethod = "search"
 					if tmdbErr == nil && tmdbMovie != nil {
-						movie = mergeMovieData(movie, tmdbMovie)
+						movie = mergeMovieData(movie, tmdbMovie, cfg.Options.PreferTMDBYear)
 						metadataSource = "NFO+TMDB"
 						slog.Debug("metadata merge",
 							"file", file.FileName,
@@ -225,6 +434,9 @@ func runScan(
 						)
 					}
 				}
+
+				// Fields still missing after enrichment fall back to the filename
+				movie.Title, movie.ReleaseYear = nfoSearchTitleYear(movie, file)
 			}
 		} else {
 			slog.Debug("metadata lookup",
@@ -232,7 +444,7 @@ func runScan(
 				"nfo_status", "disabled",
 				"action", "tmdb_search",
 			)
-			movie, err = tmdbClient.GetFullMovieData(file.Title, file.Year)
+			movie, err = searchFileMovie(tmdbClient, file)
 			metadataSource = "TMDB"
 			tmdbLookupMethod = "search"
 		}
@@ -245,23 +457,66 @@ func runScan(
 		}
 
 		if err != nil {
-			return "", "", fmt.Errorf("failed to fetch metadata for %s: %w", file.FileName, err)
+			// Never downgrade a matched MDX to a placeholder on a failed refresh
+			if !cfg.Options.WriteUnmatchedMDX || (mdxWriter.MDXExists(file.Slug) && !isUnmatchedPlaceholder(mdxWriter, file.Slug)) {
+				return "", "", fmt.Errorf("failed to fetch metadata for %s: %w", file.FileName, err)
+			}
+			slog.Warn("no metadata found, writing placeholder mdx", "file", file.FileName, "error", err)
+			movie = unmatchedMovie(file)
+			metadataSource = "unmatched"
 		}
 
-		// Generate clean slug from metadata title (not from filename)
-		movie.Slug = scanner.GenerateSlug(movie.Title, movie.ReleaseYear)
+		// Generate clean slug from metadata title (not from filename); secondary
+		// discs kept alongside their primary get a "-cdN" suffix
+		movie.Slug = scanner.GenerateDiscSlug(movie.Title, movie.ReleaseYear, file.DiscNumber)
 
 		// Thread-safe slug deduplication
 		if !slugGuard.TryClaimSlug(movie.Slug) {
 			slog.Info("skipping: slug already produced this run", "slug", movie.Slug, "file", file.FileName)
-			return metadataSource, movie.Slug, nil
+			checkpoint.Add(file.Path)
+			return metadataSource, movie.Slug, scanner.ErrDuplicateSlug
+		}
+
+		// Never-overwrite safety net: leave curated MDX (and its images) untouched
+		if cfg.Output.NeverOverwrite && mdxWriter.MDXExists(movie.Slug) {
+			slog.Info("skipping: mdx exists and never_overwrite is enabled", "slug", movie.Slug, "file", file.FileName)
+			checkpoint.Add(file.Path)
+			return metadataSource, movie.Slug, errNeverOverwrite
+		}
+
+		// Catch metadata drift: a search resolving the same slug to another TMDB entry
+		if strings.HasPrefix(tmdbLookupMethod, "search") {
+			if err := checkTMDBIDChange(cfg, mdxWriter, movie, file.FileName); err != nil {
+				return metadataSource, movie.Slug, err
+			}
 		}
 
 		// Add file information
 		movie.FilePath = file.Path
 		movie.FileName = file.FileName
 		movie.FileSize = file.Size
+		movie.FileHash = fileHash(cfg, s.FileFingerprint, file)
+		applyMediaInfo(movie, file)
 		movie.SourceDir = file.SourceDir
+		movie.Subtitles = file.Subtitles
+
+		if movie.Unmatched {
+			if err := mdxWriter.WriteMDXFile(movie); err != nil {
+				return metadataSource, movie.Slug, fmt.Errorf("failed to write mdx for %s: %w", movie.Title, err)
+			}
+			slog.Info("placeholder mdx file created", "slug", movie.Slug)
+			checkpoint.Add(file.Path)
+			return metadataSource, movie.Slug, nil
+		}
+
+		movie.Genres = writer.NormalizeGenres(movie.Genres, cfg.Options.GenreAliases, cfg.Options.MaxGenres)
+		movie.CollectionSlug = collectionSlug(movie.Collection)
+		if useNFO {
+			movie.NFOHash = scanner.NFOHash(file.Path)
+		}
+		if metadataSource == "TMDB" {
+			checkMatchConfidence(cfg, file, movie)
+		}
 
 		slog.Info("metadata fetched",
 			"movie", movie.Title,
@@ -275,176 +530,206 @@ func runScan(
 			"genres", movie.Genres,
 		)
 
-		// Download cover image
+		// Optional OMDb ratings enrichment
+		enrichRatings(omdbClient, movie, file.FileName)
+
+		// Image paths are deterministic, so the MDX can reference them before the
+		// downloads complete on the image pool
 		if cfg.Options.DownloadCovers {
-			coverPath := mdxWriter.GetAbsoluteCoverPath(movie.Slug)
 			movie.CoverImage = mdxWriter.GetCoverPath(movie.Slug)
+		}
+		if cfg.Options.DownloadBackdrops {
+			movie.BackdropImage = mdxWriter.GetBackdropPath(movie.Slug)
+		}
 
-			coverDownloaded := false
-			coverSource := ""
+		// Checked before the MDX is replaced, while it still names the old movie
+		replaceImages := forceRefresh || staleArtwork(mdxWriter, movie)
 
-			if cfg.Options.NFODownloadImages && movie.PosterURL != "" {
-				slog.Debug("image download attempt",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "cover",
-					"source", "NFO",
-					"url", movie.PosterURL,
-				)
-				if dlErr := tmdbClient.DownloadImageFromURL(movie.PosterURL, coverPath); dlErr != nil {
-					slog.Debug("image download failed",
+		// Write MDX file
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			return metadataSource, movie.Slug, fmt.Errorf("failed to write mdx for %s: %w", movie.Title, err)
+		}
+		if cfg.Options.WriteUnmatchedMDX {
+			removeUnmatchedPlaceholder(mdxWriter, file.Slug, movie.Slug)
+		}
+
+		// Queue image downloads on the dedicated image pool
+		imagePool.Submit(func() {
+			// Download cover image
+			if cfg.Options.DownloadCovers {
+				coverPath := mdxWriter.GetAbsoluteCoverPath(movie.Slug)
+
+				coverDownloaded := false
+				coverSource := ""
+				coverPresent := skipExistingImage(cfg, replaceImages, file.FileName, movie.Title, "cover", coverPath, movie.CoverImage)
+
+				if !coverPresent && cfg.Options.NFODownloadImages && movie.PosterURL != "" {
+					slog.Debug("image download attempt",
 						"file", file.FileName,
 						"movie", movie.Title,
 						"image_type", "cover",
 						"source", "NFO",
-						"error", dlErr.Error(),
-						"action", "fallback_to_tmdb",
+						"url", movie.PosterURL,
 					)
-				} else {
-					coverDownloaded = true
-					coverSource = "NFO"
+					if dlErr := tmdbClient.DownloadImageFromURL(movie.PosterURL, coverPath); dlErr != nil {
+						slog.Debug("image download failed",
+							"file", file.FileName,
+							"movie", movie.Title,
+							"image_type", "cover",
+							"source", "NFO",
+							"error", dlErr.Error(),
+							"action", "fallback_to_tmdb",
+						)
+					} else {
+						coverDownloaded = true
+						coverSource = "NFO"
+					}
 				}
-			}
 
-			if !coverDownloaded {
-				slog.Debug("image download attempt",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "cover",
-					"source", "TMDB",
-				)
-				var tmdbPosterPath string
-				if movie.TMDBID > 0 {
-					if details, detErr := tmdbClient.GetMovieDetails(movie.TMDBID); detErr == nil && details.PosterPath != "" {
-						tmdbPosterPath = details.PosterPath
+				if !coverPresent && !coverDownloaded {
+					slog.Debug("image download attempt",
+						"file", file.FileName,
+						"movie", movie.Title,
+						"image_type", "cover",
+						"source", "TMDB",
+					)
+					var tmdbPosterPath string
+					if movie.TMDBID > 0 {
+						if details, detErr := tmdbClient.GetMovieDetails(movie.TMDBID); detErr == nil && details.PosterPath != "" {
+							tmdbPosterPath = details.PosterPath
+						}
 					}
-				}
-				if tmdbPosterPath == "" {
-					if searchResult, searchErr := tmdbClient.SearchMovie(movie.Title, movie.ReleaseYear); searchErr == nil && searchResult != nil {
-						tmdbPosterPath = searchResult.PosterPath
+					if tmdbPosterPath == "" {
+						if searchResult, searchErr := tmdbClient.SearchMovie(movie.Title, movie.ReleaseYear); searchErr == nil && searchResult != nil {
+							tmdbPosterPath = searchResult.PosterPath
+						}
 					}
-				}
-				if tmdbPosterPath != "" {
-					if dlErr := tmdbClient.DownloadImage(tmdbPosterPath, coverPath, "poster"); dlErr != nil {
-						slog.Warn("image download failed",
+					if tmdbPosterPath != "" {
+						if dlErr := tmdbClient.DownloadImage(tmdbPosterPath, coverPath, "poster"); dlErr != nil {
+							slog.Warn("image download failed",
+								"file", file.FileName,
+								"movie", movie.Title,
+								"image_type", "cover",
+								"source", "TMDB",
+								"error", dlErr,
+							)
+						} else {
+							coverDownloaded = true
+							coverSource = "TMDB"
+						}
+					} else {
+						slog.Debug("image not available",
 							"file", file.FileName,
 							"movie", movie.Title,
 							"image_type", "cover",
-							"source", "TMDB",
-							"error", dlErr,
+							"reason", "no_poster_path_in_tmdb",
 						)
-					} else {
-						coverDownloaded = true
-						coverSource = "TMDB"
 					}
-				} else {
-					slog.Debug("image not available",
+				}
+
+				if coverDownloaded {
+					slog.Debug("image download success",
 						"file", file.FileName,
 						"movie", movie.Title,
 						"image_type", "cover",
-						"reason", "no_poster_path_in_tmdb",
+						"source", coverSource,
+						"path", coverPath,
 					)
 				}
 			}
 
-			if coverDownloaded {
-				slog.Debug("image download success",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "cover",
-					"source", coverSource,
-					"path", coverPath,
-				)
-			}
-		}
-
-		// Download backdrop image
-		if cfg.Options.DownloadBackdrops {
-			backdropPath := mdxWriter.GetAbsoluteBackdropPath(movie.Slug)
-			movie.BackdropImage = mdxWriter.GetBackdropPath(movie.Slug)
+			// Download backdrop image
+			if cfg.Options.DownloadBackdrops {
+				backdropPath := mdxWriter.GetAbsoluteBackdropPath(movie.Slug)
 
-			backdropDownloaded := false
-			backdropSource := ""
+				backdropDownloaded := false
+				backdropSource := ""
+				backdropPresent := skipExistingImage(cfg, replaceImages, file.FileName, movie.Title, "backdrop", backdropPath, movie.BackdropImage)
 
-			if cfg.Options.NFODownloadImages && movie.BackdropURL != "" {
-				slog.Debug("image download attempt",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "backdrop",
-					"source", "NFO",
-					"url", movie.BackdropURL,
-				)
-				if dlErr := tmdbClient.DownloadImageFromURL(movie.BackdropURL, backdropPath); dlErr != nil {
-					slog.Debug("image download failed",
+				if !backdropPresent && cfg.Options.NFODownloadImages && movie.BackdropURL != "" {
+					slog.Debug("image download attempt",
 						"file", file.FileName,
 						"movie", movie.Title,
 						"image_type", "backdrop",
 						"source", "NFO",
-						"error", dlErr.Error(),
-						"action", "fallback_to_tmdb",
+						"url", movie.BackdropURL,
 					)
-				} else {
-					backdropDownloaded = true
-					backdropSource = "NFO"
+					if dlErr := tmdbClient.DownloadImageFromURL(movie.BackdropURL, backdropPath); dlErr != nil {
+						slog.Debug("image download failed",
+							"file", file.FileName,
+							"movie", movie.Title,
+							"image_type", "backdrop",
+							"source", "NFO",
+							"error", dlErr.Error(),
+							"action", "fallback_to_tmdb",
+						)
+					} else {
+						backdropDownloaded = true
+						backdropSource = "NFO"
+					}
 				}
-			}
 
-			if !backdropDownloaded {
-				slog.Debug("image download attempt",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "backdrop",
-					"source", "TMDB",
-				)
-				var tmdbBackdropPath string
-				if movie.TMDBID > 0 {
-					if details, detErr := tmdbClient.GetMovieDetails(movie.TMDBID); detErr == nil && details.BackdropPath != "" {
-						tmdbBackdropPath = details.BackdropPath
+				if !backdropPresent && !backdropDownloaded {
+					slog.Debug("image download attempt",
+						"file", file.FileName,
+						"movie", movie.Title,
+						"image_type", "backdrop",
+						"source", "TMDB",
+					)
+					var tmdbBackdropPath string
+					if movie.TMDBID > 0 {
+						if details, detErr := tmdbClient.GetMovieDetails(movie.TMDBID); detErr == nil && details.BackdropPath != "" {
+							tmdbBackdropPath = details.BackdropPath
+						}
 					}
-				}
-				if tmdbBackdropPath == "" {
-					if searchResult, searchErr := tmdbClient.SearchMovie(movie.Title, movie.ReleaseYear); searchErr == nil && searchResult != nil {
-						tmdbBackdropPath = searchResult.BackdropPath
+					if tmdbBackdropPath == "" {
+						if searchResult, searchErr := tmdbClient.SearchMovie(movie.Title, movie.ReleaseYear); searchErr == nil && searchResult != nil {
+							tmdbBackdropPath = searchResult.BackdropPath
+						}
 					}
-				}
-				if tmdbBackdropPath != "" {
-					if dlErr := tmdbClient.DownloadImage(tmdbBackdropPath, backdropPath, "backdrop"); dlErr != nil {
-						slog.Warn("image download failed",
+					if tmdbBackdropPath != "" {
+						if dlErr := tmdbClient.DownloadImage(tmdbBackdropPath, backdropPath, "backdrop"); dlErr != nil {
+							slog.Warn("image download failed",
+								"file", file.FileName,
+								"movie", movie.Title,
+								"image_type", "backdrop",
+								"source", "TMDB",
+								"error", dlErr,
+							)
+						} else {
+							backdropDownloaded = true
+							backdropSource = "TMDB"
+						}
+					} else {
+						slog.Debug("image not available",
 							"file", file.FileName,
 							"movie", movie.Title,
 							"image_type", "backdrop",
-							"source", "TMDB",
-							"error", dlErr,
+							"reason", "no_backdrop_path_in_tmdb",
 						)
-					} else {
-						backdropDownloaded = true
-						backdropSource = "TMDB"
 					}
-				} else {
-					slog.Debug("image not available",
+				}
+
+				if backdropDownloaded {
+					slog.Debug("image download success",
 						"file", file.FileName,
 						"movie", movie.Title,
 						"image_type", "backdrop",
-						"reason", "no_backdrop_path_in_tmdb",
+						"source", backdropSource,
+						"path", backdropPath,
 					)
 				}
 			}
 
-			if backdropDownloaded {
-				slog.Debug("image download success",
-					"file", file.FileName,
-					"movie", movie.Title,
-					"image_type", "backdrop",
-					"source", backdropSource,
-					"path", backdropPath,
-				)
+			hookCoverPath := ""
+			if cfg.Options.DownloadCovers {
+				hookCoverPath = mdxWriter.GetAbsoluteCoverPath(movie.Slug)
 			}
-		}
+			hook.Run(movie, mdxWriter.GetMDXPath(movie.Slug), hookCoverPath)
 
-		// Write MDX file
-		if err := mdxWriter.WriteMDXFile(movie); err != nil {
-			return metadataSource, movie.Slug, fmt.Errorf("failed to write mdx for %s: %w", movie.Title, err)
-		}
+			// Only now is the file finished: an interrupted download is redone on --resume
+			checkpoint.Add(file.Path)
+		})
 
 		slog.Info("mdx file created", "slug", movie.Slug)
 		return metadataSource, movie.Slug, nil
@@ -453,12 +738,31 @@ func runScan(
 	// Run concurrent processing
 	processResults := scanner.ProcessFilesConcurrently(ctx, filesToProcess, processFn, cfg.Scanner.ConcurrentWorkers, &processedCount)
 
-	// Stop progress reporter (use a separate context for graceful shutdown)
-	close(progressDone)
+	// Wait for queued image downloads, then the post-process hooks they started
+	imagePool.Wait()
+	hook.Wait()
+
+	// Stop the progress reporter and wait for it to exit; only the reporter
+	// closes progressDone
+	close(stopProgress)
 	<-progressDone
+	if bar != nil {
+		bar.Finish(atomic.LoadInt64(&processedCount))
+	}
 
 	// Aggregate results
 	for _, r := range processResults {
+		// Slug duplicates resolved fine but produced no output; keep them out
+		// of both the success and error counts
+		if errors.Is(r.Err, scanner.ErrDuplicateSlug) {
+			results.SkippedDuplicates++
+			results.addSkipped(skipDuplicateSlug, 1)
+			continue
+		}
+		if errors.Is(r.Err, errNeverOverwrite) {
+			results.addSkipped(skipNeverOverwrite, 1)
+			continue
+		}
 		if r.Err != nil {
 			slog.Error("failed to process file",
 				"filename", r.File.FileName,
@@ -468,9 +772,6 @@ func runScan(
 			results.Errors = append(results.Errors, r.Err)
 			continue
 		}
-		// Files that were slug-duplicates (TryClaimSlug returned false) get
-		// a non-empty Slug but still succeed — they just don't produce output.
-		// We count them as successful.
 		results.SuccessCount++
 		switch r.MetadataSource {
 		case "NFO":
@@ -479,9 +780,13 @@ func runScan(
 			results.TMDBCount++
 		case "NFO+TMDB":
 			results.MixedCount++
+		case "unmatched":
+			results.UnmatchedCount++
 		}
 	}
 
+	writeTaxonomy(cfg)
+
 	results.Duration = time.Since(startTime)
 
 	// Print summary
@@ -489,9 +794,16 @@ func runScan(
 		"total_files", results.TotalFiles,
 		"processed", results.ProcessedFiles,
 		"successful", results.SuccessCount,
+		"skipped_duplicates", results.SkippedDuplicates,
 		"errors", results.ErrorCount,
 		"duration_sec", results.Duration.Seconds(),
 	)
+	logSkipBreakdown(results)
+
+	// With --quiet the summary above is filtered out; print the bottom line anyway
+	if !slog.Default().Enabled(ctx, slog.LevelInfo) {
+		printScanSummary(os.Stdout, results)
+	}
 
 	// Show metadata source breakdown
 	if results.SuccessCount > 0 {
@@ -502,8 +814,187 @@ func runScan(
 			"tmdb_percent", fmt.Sprintf("%.0f%%", float64(results.TMDBCount)/float64(results.SuccessCount)*100),
 			"mixed_count", results.MixedCount,
 			"mixed_percent", fmt.Sprintf("%.0f%%", float64(results.MixedCount)/float64(results.SuccessCount)*100),
+			"unmatched_count", results.UnmatchedCount,
 		)
 	}
 
 	return results
 }
+
+// printScanSummary writes a one-line scan result, independent of the log level
+func printScanSummary(w io.Writer, results *ScanResults) {
+	fmt.Fprintf(w, "Scan complete: %d files found, %d processed, %d written, %d duplicates skipped, %d errors (%.1fs)\n",
+		results.TotalFiles,
+		results.ProcessedFiles,
+		results.SuccessCount,
+		results.SkippedDuplicates,
+		results.ErrorCount,
+		results.Duration.Seconds(),
+	)
+	if len(results.SkipReasons) > 0 {
+		fmt.Fprintf(w, "Skipped: %s\n", formatSkipReasons(results.SkipReasons))
+	}
+}
+
+// errTMDBIDChanged is returned when options.tmdb_id_change is "refuse" and a
+// lookup resolved an existing slug to a different TMDB ID
+var errTMDBIDChanged = errors.New("tmdb id changed since the last scan")
+
+// checkTMDBIDChange compares the TMDB ID about to be written for movie.Slug
+// with the one in the existing MDX. A different ID usually means an ambiguous
+// search now picks another movie; options.tmdb_id_change decides whether that
+// is logged, refused (errTMDBIDChanged), or ignored.
+func checkTMDBIDChange(cfg *config.Config, mdxWriter *writer.MDXWriter, movie *writer.Movie, fileName string) error {
+	if cfg.Options.TMDBIDChange == "ignore" || movie.TMDBID == 0 || !mdxWriter.MDXExists(movie.Slug) {
+		return nil
+	}
+	existing, err := writer.ReadMDXFile(mdxWriter.GetMDXPath(movie.Slug))
+	if err != nil || existing.Unmatched || existing.TMDBID == 0 || existing.TMDBID == movie.TMDBID {
+		return nil
+	}
+
+	if cfg.Options.TMDBIDChange == "refuse" {
+		slog.Warn("tmdb id changed, keeping existing mdx",
+			"slug", movie.Slug,
+			"file", fileName,
+			"existing_tmdb_id", existing.TMDBID,
+			"new_tmdb_id", movie.TMDBID,
+		)
+		return fmt.Errorf("%w: %s is tmdb %d, lookup returned %d", errTMDBIDChanged, movie.Slug, existing.TMDBID, movie.TMDBID)
+	}
+	slog.Warn("tmdb id changed since last scan",
+		"slug", movie.Slug,
+		"file", fileName,
+		"existing_tmdb_id", existing.TMDBID,
+		"new_tmdb_id", movie.TMDBID,
+	)
+	return nil
+}
+
+// unmatchedPosterPath is the website's placeholder cover for unmatched movies
+const unmatchedPosterPath = "/placeholder-poster.svg"
+
+// unmatchedMovie builds the placeholder written by options.write_unmatched_mdx
+// when no metadata was found, using the title and year from the filename
+func unmatchedMovie(file scanner.FileInfo) *writer.Movie {
+	return &writer.Movie{
+		Title:       file.Title,
+		ReleaseYear: file.Year,
+		CoverImage:  unmatchedPosterPath,
+		ScannedAt:   time.Now(),
+		Unmatched:   true,
+	}
+}
+
+// isUnmatchedPlaceholder reports whether the MDX for slug is an unmatched placeholder
+func isUnmatchedPlaceholder(mdxWriter *writer.MDXWriter, slug string) bool {
+	content, err := os.ReadFile(mdxWriter.GetMDXPath(slug))
+	return err == nil && scanner.IsUnmatchedMDX(content)
+}
+
+// removeUnmatchedPlaceholder deletes the placeholder MDX a file produced under
+// its filename slug, once a match has been written under a different slug
+func removeUnmatchedPlaceholder(mdxWriter *writer.MDXWriter, fileSlug, movieSlug string) {
+	if fileSlug == "" || fileSlug == movieSlug || !isUnmatchedPlaceholder(mdxWriter, fileSlug) {
+		return
+	}
+	if err := mdxWriter.RemoveMDX(fileSlug); err != nil {
+		slog.Warn("failed to remove unmatched placeholder", "slug", fileSlug, "error", err)
+		return
+	}
+	slog.Info("removed unmatched placeholder", "slug", fileSlug, "replaced_by", movieSlug)
+}
+
+// nfoSearchTitleYear returns the title and year to use when an NFO was found.
+// NFO values are preferred because filenames are often mislabeled; the
+// filename-derived values only fill fields the NFO leaves empty.
+func nfoSearchTitleYear(nfoMovie *writer.Movie, file scanner.FileInfo) (string, int) {
+	title := nfoMovie.Title
+	if title == "" {
+		title = file.Title
+	}
+	year := nfoMovie.ReleaseYear
+	if year == 0 {
+		year = file.Year
+	}
+	return title, year
+}
+
+// logNFOFallback logs why NFO metadata was unavailable before falling back to TMDB.
+// A missing NFO is the normal case and is logged at debug level; a malformed NFO
+// usually means a broken metadata export, so it is surfaced as a warning.
+func logNFOFallback(file scanner.FileInfo, err error) {
+	if errors.Is(err, nfo.ErrNFOParse) {
+		slog.Warn("malformed nfo file, falling back to tmdb",
+			"file", file.FileName,
+			"error", err,
+		)
+		return
+	}
+
+	nfoStatus := "error"
+	if errors.Is(err, nfo.ErrNFONotFound) {
+		nfoStatus = "not_found"
+	}
+	slog.Debug("metadata lookup",
+		"file", file.FileName,
+		"nfo_status", nfoStatus,
+		"nfo_error", err.Error(),
+		"action", "fallback_to_tmdb",
+	)
+}
+
+// enrichRatings adds IMDb and Rotten Tomatoes ratings from OMDb when a client is
+// configured and the movie has an IMDb ID. Failures are logged and never fail the file.
+func enrichRatings(omdbClient *omdb.Client, movie *writer.Movie, fileName string) {
+	if omdbClient == nil || movie.IMDbID == "" {
+		return
+	}
+
+	ratings, err := omdbClient.GetRatings(movie.IMDbID)
+	if err != nil {
+		slog.Warn("omdb ratings lookup failed",
+			"file", fileName,
+			"imdb_id", movie.IMDbID,
+			"error", err,
+		)
+		return
+	}
+
+	movie.IMDbRating = ratings.IMDbRating
+	movie.RottenTomatoes = ratings.RottenTomatoes
+	slog.Debug("omdb ratings fetched",
+		"file", fileName,
+		"imdb_rating", ratings.IMDbRating,
+		"rotten_tomatoes", ratings.RottenTomatoes,
+	)
+}
+
+// fileHash returns the fingerprint of file when options.compute_file_hash is
+// enabled, read with fingerprint (scanner.FileFingerprint or a Scanner's).
+// Failures are logged and leave the movie without a fileHash.
+func fileHash(cfg *config.Config, fingerprint func(path string) (string, error), file scanner.FileInfo) string {
+	if !cfg.Options.ComputeFileHash {
+		return ""
+	}
+	hash, err := fingerprint(file.Path)
+	if err != nil {
+		slog.Warn("failed to fingerprint video file", "file", file.FileName, "error", err)
+		return ""
+	}
+	return hash
+}
+
+// writeTaxonomy regenerates the genre and director taxonomy files when
+// output.taxonomy_dir is set. Failures are logged, not fatal to the scan.
+func writeTaxonomy(cfg *config.Config) {
+	if cfg.Output.TaxonomyDir == "" {
+		return
+	}
+	count, err := writer.WriteTaxonomy(cfg.Output.MDXDir, cfg.Output.TaxonomyDir)
+	if err != nil {
+		slog.Error("failed to write taxonomy files", "dir", cfg.Output.TaxonomyDir, "error", err)
+		return
+	}
+	slog.Info("taxonomy files written", "dir", cfg.Output.TaxonomyDir, "movies", count)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco/movieVault/internal/config"
+	"github.com/marco/movieVault/internal/scanner"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestNFOSearchTitleYear(t *testing.T) {
+	file := scanner.FileInfo{FileName: "Blade.Runner.1984.mkv", Title: "Blade Runner", Year: 1984}
+
+	testCases := []struct {
+		name          string
+		nfoTitle      string
+		nfoYear       int
+		expectedTitle string
+		expectedYear  int
+	}{
+		{"nfo year overrides wrong filename year", "Blade Runner", 1982, "Blade Runner", 1982},
+		{"nfo title and year both preferred", "Blade Runner: The Final Cut", 1982, "Blade Runner: The Final Cut", 1982},
+		{"empty nfo year falls back to filename", "Blade Runner", 0, "Blade Runner", 1984},
+		{"empty nfo title falls back to filename", "", 1982, "Blade Runner", 1982},
+		{"empty nfo falls back entirely", "", 0, "Blade Runner", 1984},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			movie := &writer.Movie{Title: tc.nfoTitle, ReleaseYear: tc.nfoYear}
+			title, year := nfoSearchTitleYear(movie, file)
+			if title != tc.expectedTitle || year != tc.expectedYear {
+				t.Errorf("nfoSearchTitleYear() = (%q, %d), want (%q, %d)", title, year, tc.expectedTitle, tc.expectedYear)
+			}
+		})
+	}
+}
+
+func TestMergeMovieData_Locked(t *testing.T) {
+	tmdbMovie := &writer.Movie{Title: "Heat", Description: "A heist film.", Director: "Michael Mann", Rating: 8.3}
+
+	unlocked := mergeMovieData(&writer.Movie{Title: "Heat (Director's Cut)"}, tmdbMovie, false)
+	if unlocked.Title != "Heat (Director's Cut)" || unlocked.Description != "A heist film." || unlocked.Director != "Michael Mann" {
+		t.Errorf("unlocked merge = %+v, want NFO title kept and empty fields filled", unlocked)
+	}
+
+	locked := mergeMovieData(&writer.Movie{Title: "Heat (Director's Cut)", Locked: true}, tmdbMovie, true)
+	if locked.Title != "Heat (Director's Cut)" || locked.Description != "" || locked.Director != "" || locked.Rating != 0 {
+		t.Errorf("locked merge = %+v, want NFO fields unchanged", locked)
+	}
+}
+
+func TestMergeMovieData_YearConflict(t *testing.T) {
+	testCases := []struct {
+		name           string
+		nfoYear        int
+		nfoDate        string
+		preferTMDBYear bool
+		expectedYear   int
+		expectedDate   string
+	}{
+		{"nfo year kept", 2019, "2019-09-07", false, 2019, "2019-09-07"},
+		{"tmdb year preferred", 2019, "2019-09-07", true, 2020, "2020-02-14"},
+		{"missing nfo year filled", 0, "", false, 2020, "2020-02-14"},
+		{"same year", 2020, "2020-01-24", true, 2020, "2020-01-24"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmdbMovie := &writer.Movie{Title: "Parasite", ReleaseYear: 2020, ReleaseDate: "2020-02-14"}
+			nfoMovie := &writer.Movie{Title: "Parasite", ReleaseYear: tc.nfoYear, ReleaseDate: tc.nfoDate}
+			merged := mergeMovieData(nfoMovie, tmdbMovie, tc.preferTMDBYear)
+			if merged.ReleaseYear != tc.expectedYear || merged.ReleaseDate != tc.expectedDate {
+				t.Errorf("merged year/date = %d %q, want %d %q", merged.ReleaseYear, merged.ReleaseDate, tc.expectedYear, tc.expectedDate)
+			}
+		})
+	}
+}
+
+func TestRemoveUnmatchedPlaceholder(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+
+	placeholder := unmatchedMovie(scanner.FileInfo{Title: "Obscure Film", Year: 2003})
+	placeholder.Slug = "obscure-film-2003"
+	if err := mdxWriter.WriteMDXFile(placeholder); err != nil {
+		t.Fatalf("WriteMDXFile failed: %v", err)
+	}
+	matched := &writer.Movie{Title: "Heat", ReleaseYear: 1995, Slug: "heat-1995"}
+	if err := mdxWriter.WriteMDXFile(matched); err != nil {
+		t.Fatalf("WriteMDXFile failed: %v", err)
+	}
+
+	if !isUnmatchedPlaceholder(mdxWriter, "obscure-film-2003") {
+		t.Fatal("placeholder not recognized as unmatched")
+	}
+	if isUnmatchedPlaceholder(mdxWriter, "heat-1995") {
+		t.Fatal("matched mdx recognized as unmatched")
+	}
+
+	// A matched MDX is never removed, and a placeholder is kept when the match reuses its slug
+	removeUnmatchedPlaceholder(mdxWriter, "heat-1995", "heat-1995-remaster")
+	removeUnmatchedPlaceholder(mdxWriter, "obscure-film-2003", "obscure-film-2003")
+	for _, slug := range []string{"heat-1995", "obscure-film-2003"} {
+		if !mdxWriter.MDXExists(slug) {
+			t.Errorf("%s removed, want kept", slug)
+		}
+	}
+
+	removeUnmatchedPlaceholder(mdxWriter, "obscure-film-2003", "the-obscure-film-2003")
+	if _, err := os.Stat(mdxWriter.GetMDXPath("obscure-film-2003")); !os.IsNotExist(err) {
+		t.Errorf("placeholder still exists after match (stat err: %v)", err)
+	}
+}
+
+func TestPrintScanSummary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reasons  map[string]int
+		expected string
+	}{
+		{
+			name:     "no skips",
+			expected: "Scan complete: 12 files found, 4 processed, 3 written, 1 duplicates skipped, 1 errors (2.5s)\n",
+		},
+		{
+			name:    "skip breakdown",
+			reasons: map[string]int{skipDuplicateSlug: 1, skipMDXExists: 7, skipExtra: 1},
+			expected: "Scan complete: 12 files found, 4 processed, 3 written, 1 duplicates skipped, 1 errors (2.5s)\n" +
+				"Skipped: mdx exists: 7, duplicate slug: 1, extra: 1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printScanSummary(&buf, &ScanResults{
+				TotalFiles:        12,
+				ProcessedFiles:    4,
+				SuccessCount:      3,
+				SkippedDuplicates: 1,
+				ErrorCount:        1,
+				SkipReasons:       tc.reasons,
+				Duration:          2500 * time.Millisecond,
+			})
+
+			if buf.String() != tc.expected {
+				t.Errorf("printScanSummary() = %q, want %q", buf.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestCheckTMDBIDChange(t *testing.T) {
+	dir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	existing := []*writer.Movie{
+		{Title: "Heat", ReleaseYear: 1995, Slug: "heat-1995", TMDBID: 949},
+		{Title: "Obscure Film", ReleaseYear: 2003, Slug: "obscure-film-2003", Unmatched: true},
+	}
+	for _, movie := range existing {
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile failed: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		mode    string
+		slug    string
+		tmdbID  int
+		refused bool
+	}{
+		{"refuse", "heat-1995", 949, false},
+		{"refuse", "heat-1995", 12345, true},
+		{"warn", "heat-1995", 12345, false},
+		{"ignore", "heat-1995", 12345, false},
+		{"refuse", "obscure-film-2003", 12345, false}, // placeholders have no ID to drift from
+		{"refuse", "alien-1979", 348, false},
+	}
+
+	for _, tc := range testCases {
+		cfg := &config.Config{}
+		cfg.Options.TMDBIDChange = tc.mode
+		err := checkTMDBIDChange(cfg, mdxWriter, &writer.Movie{Slug: tc.slug, TMDBID: tc.tmdbID}, "file.mkv")
+		if refused := errors.Is(err, errTMDBIDChanged); refused != tc.refused {
+			t.Errorf("%s %s tmdb %d: err = %v, want refused %v", tc.mode, tc.slug, tc.tmdbID, err, tc.refused)
+		}
+	}
+}
+
+func TestRunScan_Completes(t *testing.T) {
+	dir := t.TempDir()
+	mediaDir := filepath.Join(dir, "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Inception.2010.1080p.mkv", "Heat.1995.mkv"} {
+		if err := os.WriteFile(filepath.Join(mediaDir, name), []byte("video"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Scanner.Directories = []string{mediaDir}
+	cfg.Scanner.Extensions = []string{".mkv"}
+	cfg.Scanner.ConcurrentWorkers = 2
+	cfg.Scanner.ImageDownloadWorkers = 1
+	cfg.Output.MDXDir = filepath.Join(dir, "movies")
+	cfg.Output.CoversDir = filepath.Join(dir, "covers")
+	cfg.Cache.Path = filepath.Join(dir, "cache.db")
+	mdxWriter := writer.NewMDXWriter(cfg.Output.MDXDir, cfg.Output.CoversDir)
+	provider := newFakeProvider(
+		&writer.Movie{Title: "Inception", ReleaseYear: 2010, TMDBID: 27205},
+		&writer.Movie{Title: "Heat", ReleaseYear: 1995, TMDBID: 949},
+	)
+
+	ctx := context.Background()
+	results := runScan(ctx, cfg, provider, nil, mdxWriter, false, false, false, time.Time{}, true, checkpointRecord)
+	if results.SuccessCount != 2 || results.ErrorCount != 0 {
+		t.Errorf("SuccessCount = %d, ErrorCount = %d (%v), want 2 and 0", results.SuccessCount, results.ErrorCount, results.Errors)
+	}
+	if _, err := os.Stat(checkpointPath(cfg)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint left behind by a completed scan: %v", err)
+	}
+
+	// A scheduled scan leaves the checkpoint of an interrupted manual scan alone
+	if err := os.WriteFile(checkpointPath(cfg), []byte("/media/Ran.1985.mkv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runScan(ctx, cfg, provider, nil, mdxWriter, false, false, false, time.Time{}, false, checkpointNone)
+	if done, err := loadScanCheckpoint(checkpointPath(cfg)); err != nil || !done["/media/Ran.1985.mkv"] {
+		t.Errorf("scheduled scan changed the checkpoint: %v, %v", done, err)
+	}
+}
+
+func TestRunScan_NeverOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	mediaDir := filepath.Join(dir, "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "Heat.1995.mkv"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Scanner.Directories = []string{mediaDir}
+	cfg.Scanner.Extensions = []string{".mkv"}
+	cfg.Scanner.ConcurrentWorkers = 1
+	cfg.Scanner.ImageDownloadWorkers = 1
+	cfg.Output.MDXDir = filepath.Join(dir, "movies")
+	cfg.Output.CoversDir = filepath.Join(dir, "covers")
+	cfg.Output.NeverOverwrite = true
+	cfg.Options.DownloadCovers = true
+	cfg.Options.DownloadBackdrops = true
+	cfg.Cache.Path = filepath.Join(dir, "cache.db")
+	mdxWriter := writer.NewMDXWriterWithOptions(cfg.Output.MDXDir, cfg.Output.CoversDir, writer.MDXWriterOptions{NeverOverwrite: true})
+
+	// A curated MDX with hand-picked artwork for a different TMDB entry
+	curated := &writer.Movie{Title: "Heat (Curated)", ReleaseYear: 1995, Slug: "heat-1995", TMDBID: 1}
+	if err := mdxWriter.WriteMDXFile(curated); err != nil {
+		t.Fatal(err)
+	}
+	images := []string{mdxWriter.GetAbsoluteCoverPath("heat-1995"), mdxWriter.GetAbsoluteBackdropPath("heat-1995")}
+	for _, path := range images {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("curated"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mdxBefore, err := os.ReadFile(mdxWriter.GetMDXPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := newFakeProvider(&writer.Movie{Title: "Heat", ReleaseYear: 1995, TMDBID: 949})
+	results := runScan(context.Background(), cfg, provider, nil, mdxWriter, true, false, false, time.Time{}, false, checkpointNone)
+	if results.SkipReasons[skipNeverOverwrite] != 1 || results.SuccessCount != 0 || results.ErrorCount != 0 {
+		t.Errorf("skips = %v, SuccessCount = %d, ErrorCount = %d (%v), want one never overwrite skip",
+			results.SkipReasons, results.SuccessCount, results.ErrorCount, results.Errors)
+	}
+
+	if after, err := os.ReadFile(mdxWriter.GetMDXPath("heat-1995")); err != nil || !bytes.Equal(after, mdxBefore) {
+		t.Errorf("existing MDX changed (err: %v)", err)
+	}
+	for _, path := range images {
+		if data, err := os.ReadFile(path); err != nil || string(data) != "curated" {
+			t.Errorf("%s replaced: %q, %v", filepath.Base(path), data, err)
+		}
+	}
+	if len(provider.downloads) != 0 {
+		t.Errorf("images downloaded: %v", provider.downloads)
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/marco/movieVault/internal/config"
 	"github.com/marco/movieVault/internal/metadata"
+	"github.com/marco/movieVault/internal/metadata/omdb"
 	"github.com/marco/movieVault/internal/writer"
 )
 
@@ -18,25 +18,43 @@ import (
 var scanInProgress atomic.Bool
 
 // startScheduler starts the scheduled scanning service
-// Runs periodic scans at configured intervals, optionally running immediately on startup
+// Runs periodic scans at configured intervals, optionally running immediately on
+// startup. See scheduleTiming for when the first scan runs.
 func startScheduler(
 	ctx context.Context,
 	cfg *config.Config,
-	tmdbClient *metadata.Client,
+	tmdbClient metadata.MovieProvider,
+	omdbClient *omdb.Client,
 	mdxWriter *writer.MDXWriter,
 	verbose bool,
 ) {
 	interval := time.Duration(cfg.Scanner.ScheduleInterval) * time.Minute
+	runOnStartup, firstDelay := scheduleTiming(cfg)
 
 	slog.Info("scheduled scanning started",
 		"interval_minutes", cfg.Scanner.ScheduleInterval,
-		"run_on_startup", *cfg.Scanner.ScheduleOnStartup,
+		"run_on_startup", runOnStartup,
+		"first_scheduled_scan_in", firstDelay.String(),
 	)
 
 	// Run initial scan on startup if enabled
-	if *cfg.Scanner.ScheduleOnStartup {
+	if runOnStartup {
 		slog.Info("running initial scheduled scan on startup")
-		runScheduledScan(ctx, cfg, tmdbClient, mdxWriter, verbose)
+		runScheduledScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, verbose)
+	}
+
+	// Wait for the first scheduled scan, which may come sooner than a full interval
+	if firstDelay != interval {
+		timer := time.NewTimer(firstDelay)
+		select {
+		case <-timer.C:
+			slog.Info("scheduled scan triggered", "initial_delay_minutes", cfg.Scanner.ScheduleInitialDelay)
+			runScheduledScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, verbose)
+		case <-ctx.Done():
+			timer.Stop()
+			slog.Info("scheduled scanning stopped")
+			return
+		}
 	}
 
 	// Create ticker for periodic scans
@@ -50,7 +68,7 @@ func startScheduler(
 			slog.Info("scheduled scan triggered",
 				"interval_minutes", cfg.Scanner.ScheduleInterval,
 			)
-			runScheduledScan(ctx, cfg, tmdbClient, mdxWriter, verbose)
+			runScheduledScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, verbose)
 
 		case <-ctx.Done():
 			slog.Info("scheduled scanning stopped")
@@ -59,11 +77,27 @@ func startScheduler(
 	}
 }
 
+// scheduleTiming reports whether a scan runs immediately on startup and how long
+// to wait before the first scheduled scan. With schedule_on_startup the first
+// scheduled scan follows a full interval later. Without it, the first scan runs
+// after schedule_initial_delay minutes, or after a full interval when unset.
+func scheduleTiming(cfg *config.Config) (runOnStartup bool, firstDelay time.Duration) {
+	interval := time.Duration(cfg.Scanner.ScheduleInterval) * time.Minute
+	if *cfg.Scanner.ScheduleOnStartup {
+		return true, interval
+	}
+	if cfg.Scanner.ScheduleInitialDelay > 0 {
+		return false, time.Duration(cfg.Scanner.ScheduleInitialDelay) * time.Minute
+	}
+	return false, interval
+}
+
 // runScheduledScan performs a single scheduled scan with overlap prevention
 func runScheduledScan(
 	ctx context.Context,
 	cfg *config.Config,
-	tmdbClient *metadata.Client,
+	tmdbClient metadata.MovieProvider,
+	omdbClient *omdb.Client,
 	mdxWriter *writer.MDXWriter,
 	verbose bool,
 ) {
@@ -81,14 +115,15 @@ func runScheduledScan(
 	startTime := time.Now()
 	slog.Info("scheduled scan started")
 
-	// Run incremental scan (forceRefresh=false, dryRun=false)
-	results := runScan(ctx, cfg, tmdbClient, mdxWriter, false, false, verbose)
+	// Run incremental scan (forceRefresh=false, dryRun=false, no --since filter)
+	results := runScan(ctx, cfg, tmdbClient, omdbClient, mdxWriter, false, false, verbose, time.Time{}, false, checkpointNone)
 
 	// Log completion with results
 	slog.Info("scheduled scan completed",
 		"duration_sec", results.Duration.Seconds(),
 		"files_processed", results.ProcessedFiles,
 		"successful", results.SuccessCount,
+		"skipped_duplicates", results.SkippedDuplicates,
 		"errors", results.ErrorCount,
 	)
 
@@ -103,20 +138,7 @@ func runScheduledScan(
 	distMissing := os.IsNotExist(distStatErr)
 
 	if cfg.Output.AutoBuild && (results.SuccessCount > 0 || (distMissing && results.TotalFiles > 0)) {
-		slog.Info("triggering astro build after scheduled scan")
-
-		// Sync content to Astro website (needed in Docker)
-		if err := syncContentToWebsite(cfg); err != nil {
-			slog.Error("failed to sync content to website", "error", err)
-			// Continue with build anyway - may work with existing files
-		}
-
-		if err := buildAstroSite(websiteDir); err != nil {
-			slog.Error("failed to build astro site", "error", err, "website_dir", websiteDir)
-			slog.Info("manual build command", "command", fmt.Sprintf("cd %s && npm run build", websiteDir))
-		} else {
-			slog.Info("astro site built successfully")
-		}
+		rebuildSite(cfg, "scheduled scan")
 	} else if results.ProcessedFiles == 0 {
 		slog.Debug("scheduled scan: no new files to process")
 	}
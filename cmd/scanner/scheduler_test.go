@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marco/movieVault/internal/config"
+)
+
+func TestScheduleTiming(t *testing.T) {
+	tests := []struct {
+		name         string
+		onStartup    bool
+		interval     int
+		initialDelay int
+		wantStartup  bool
+		wantDelay    time.Duration
+	}{
+		{"startup scan then full interval", true, 60, 0, true, 60 * time.Minute},
+		{"startup scan ignores initial delay", true, 60, 5, true, 60 * time.Minute},
+		{"no startup scan waits full interval", false, 60, 0, false, 60 * time.Minute},
+		{"no startup scan uses initial delay", false, 60, 5, false, 5 * time.Minute},
+		{"initial delay longer than interval", false, 30, 90, false, 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onStartup := tt.onStartup
+			cfg := &config.Config{}
+			cfg.Scanner.ScheduleOnStartup = &onStartup
+			cfg.Scanner.ScheduleInterval = tt.interval
+			cfg.Scanner.ScheduleInitialDelay = tt.initialDelay
+
+			gotStartup, gotDelay := scheduleTiming(cfg)
+			if gotStartup != tt.wantStartup {
+				t.Errorf("runOnStartup = %v, want %v", gotStartup, tt.wantStartup)
+			}
+			if gotDelay != tt.wantDelay {
+				t.Errorf("firstDelay = %v, want %v", gotDelay, tt.wantDelay)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFilenames(t *testing.T) {
+	filenames, err := readFilenames(strings.NewReader("Heat.1995.mkv\r\n\nThe.Matrix.1999.1080p.mkv\n"))
+	if err != nil {
+		t.Fatalf("readFilenames failed: %v", err)
+	}
+	expected := []string{"Heat.1995.mkv", "The.Matrix.1999.1080p.mkv"}
+	if !reflect.DeepEqual(filenames, expected) {
+		t.Errorf("readFilenames = %q, want %q", filenames, expected)
+	}
+}
+
+func TestPrintParserResults(t *testing.T) {
+	testCases := []struct {
+		format        string
+		trace         bool
+		filenames     []string
+		expected      string
+		hasEmptyTitle bool
+	}{
+		{
+			"json",
+			false,
+			[]string{"The.Matrix.1999.1080p.mkv", "Heat.mkv"},
+			`{"filename":"The.Matrix.1999.1080p.mkv","title":"The Matrix","year":1999,"slug":"the-matrix-1999","patterns":["resolution","year","release-group"]}` + "\n" +
+				`{"filename":"Heat.mkv","title":"Heat","year":0,"slug":"heat","patterns":[]}` + "\n",
+			false,
+		},
+		{
+			"text",
+			false,
+			[]string{"Heat.mkv"},
+			"Filename: Heat.mkv\n  Title: Heat\n  Year: (not found)\n  Slug: heat\n  Patterns matched: (none)\n\n",
+			false,
+		},
+		{
+			"text",
+			true,
+			[]string{"The.Matrix.1999.1080p-GRP.mkv"},
+			"Filename: The.Matrix.1999.1080p-GRP.mkv\n  Title: The Matrix\n  Year: 1999\n  Slug: the-matrix-1999\n  Patterns matched: resolution, year, release-group\n" +
+				"  Trace:\n" +
+				"    extension:       \"The.Matrix.1999.1080p-GRP\"\n" +
+				"    year:            \"The.Matrix..1080p-GRP\"\n" +
+				"    resolution:      \"The.Matrix.. -GRP\"\n" +
+				"    release-group:   \"The.Matrix.. \"\n" +
+				"    separators:      \"The Matrix   \"\n" +
+				"    whitespace:      \"The Matrix\"\n\n",
+			false,
+		},
+		{
+			"json",
+			true,
+			[]string{"Heat (1995).mkv"},
+			`{"filename":"Heat (1995).mkv","title":"Heat","year":1995,"slug":"heat-1995","patterns":["year-bracketed"],"trace":[{"step":"extension","result":"Heat (1995)"},{"step":"canonical","result":"Heat"}]}` + "\n",
+			false,
+		},
+		{"json", false, []string{".mkv"}, `{"filename":".mkv","title":"","year":0,"slug":"","patterns":["release-group"]}` + "\n", true},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		hasEmptyTitle := printParserResults(&out, tc.filenames, tc.format, tc.trace)
+		if out.String() != tc.expected {
+			t.Errorf("%s %v output =\n%s\nwant\n%s", tc.format, tc.filenames, out.String(), tc.expected)
+		}
+		if hasEmptyTitle != tc.hasEmptyTitle {
+			t.Errorf("%s %v hasEmptyTitle = %v, want %v", tc.format, tc.filenames, hasEmptyTitle, tc.hasEmptyTitle)
+		}
+	}
+}
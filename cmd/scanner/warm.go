@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cacheWarmer fetches and caches a movie lookup without writing anything
+type cacheWarmer interface {
+	WarmCache(title string, year int) (alreadyCached bool, err error)
+}
+
+// warmEntry is one title to pre-fetch
+type warmEntry struct {
+	Title string
+	Year  int // 0 when the line has no year
+}
+
+// runWarmCache pre-fetches TMDB metadata for the titles listed in path so the
+// next scan of them is served from the cache. No MDX files or images are
+// written. Requests go through the client's rate limiter and retry policy.
+// Returns exit code: 0 on success, 1 if the list could not be read or any title failed
+func runWarmCache(warmer cacheWarmer, path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Error("failed to open warm-cache list", "path", path, "error", err)
+		return 1
+	}
+	defer f.Close()
+
+	entries, err := parseWarmEntries(f)
+	if err != nil {
+		slog.Error("failed to read warm-cache list", "path", path, "error", err)
+		return 1
+	}
+
+	slog.Info("warming cache", "titles", len(entries))
+
+	var warmed, cached, failed int
+	for _, entry := range entries {
+		alreadyCached, err := warmer.WarmCache(entry.Title, entry.Year)
+		switch {
+		case err != nil:
+			slog.Error("failed to warm cache", "title", entry.Title, "year", entry.Year, "error", err)
+			failed++
+		case alreadyCached:
+			slog.Debug("already cached", "title", entry.Title, "year", entry.Year)
+			cached++
+		default:
+			slog.Info("cache warmed", "title", entry.Title, "year", entry.Year)
+			warmed++
+		}
+	}
+
+	slog.Info("cache warming complete",
+		"warmed", warmed,
+		"already_cached", cached,
+		"failed", failed,
+	)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseWarmEntries reads one "title[,year]" per line. The year is taken from
+// the text after the last comma when it is a 4-digit number, so titles that
+// contain commas still work. Blank lines and lines starting with "#" are
+// ignored; duplicate entries are returned once, in first-seen order.
+func parseWarmEntries(r io.Reader) ([]warmEntry, error) {
+	var entries []warmEntry
+	seen := make(map[warmEntry]bool)
+
+	lineScanner := bufio.NewScanner(r)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry := warmEntry{Title: line}
+		if idx := strings.LastIndex(line, ","); idx >= 0 {
+			yearText := strings.TrimSpace(line[idx+1:])
+			if year, err := strconv.Atoi(yearText); err == nil && len(yearText) == 4 {
+				entry = warmEntry{Title: strings.TrimSpace(line[:idx]), Year: year}
+			}
+		}
+		if entry.Title == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		entries = append(entries, entry)
+	}
+
+	if err := lineScanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWarmEntries(t *testing.T) {
+	input := `# Upcoming screenings
+The Matrix,1999
+Crouching Tiger, Hidden Dragon, 2000
+Heat
+
+Love, Actually
+The Matrix, 1999
+`
+
+	entries, err := parseWarmEntries(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseWarmEntries failed: %v", err)
+	}
+
+	expected := []warmEntry{
+		{Title: "The Matrix", Year: 1999},
+		{Title: "Crouching Tiger, Hidden Dragon", Year: 2000},
+		{Title: "Heat"},
+		{Title: "Love, Actually"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("parseWarmEntries() = %+v, want %+v", entries, expected)
+	}
+}
+
+// fakeWarmer reports titles in cached as already cached and fails titles in failing
+type fakeWarmer struct {
+	cached  map[string]bool
+	failing map[string]bool
+	calls   []string
+}
+
+func (f *fakeWarmer) WarmCache(title string, year int) (bool, error) {
+	f.calls = append(f.calls, title)
+	if f.failing[title] {
+		return false, errors.New("no results found")
+	}
+	return f.cached[title], nil
+}
+
+func TestRunWarmCache(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "titles.txt")
+	if err := os.WriteFile(listPath, []byte("Heat,1995\nAlien,1979\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	warmer := &fakeWarmer{cached: map[string]bool{"Heat": true}}
+	if code := runWarmCache(warmer, listPath); code != 0 {
+		t.Errorf("runWarmCache() = %d, want 0", code)
+	}
+	if !reflect.DeepEqual(warmer.calls, []string{"Heat", "Alien"}) {
+		t.Errorf("warmed %v, want [Heat Alien]", warmer.calls)
+	}
+
+	failing := &fakeWarmer{failing: map[string]bool{"Alien": true}}
+	if code := runWarmCache(failing, listPath); code != 1 {
+		t.Errorf("runWarmCache() with a failure = %d, want 1", code)
+	}
+}
@@ -1,14 +1,32 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// unknownFieldPattern matches yaml.v3 strict-decoding errors for unrecognized keys,
+// e.g. "line 12: field concurrent_worker not found in type config.ScannerConfig"
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+// UnknownField describes a YAML key that does not map to any config field
+type UnknownField struct {
+	Key     string
+	Line    int
+	Section string // Go type the key was found in, e.g. "config.ScannerConfig"
+}
+
 // Config represents the application configuration
 type Config struct {
 	TMDB    TMDBConfig    `yaml:"tmdb"`
@@ -17,26 +35,73 @@ type Config struct {
 	Options OptionsConfig `yaml:"options"`
 	Retry   RetryConfig   `yaml:"retry"`
 	Cache   CacheConfig   `yaml:"cache"`
+	OMDb    OMDbConfig    `yaml:"omdb"`
 }
 
 // TMDBConfig holds TMDB API configuration
 type TMDBConfig struct {
-	APIKey   string `yaml:"api_key"`
-	Language string `yaml:"language"`
+	APIKey      string            `yaml:"api_key"`
+	AccessToken string            `yaml:"access_token"` // v4 Read Access Token (bearer auth), alternative to api_key
+	Language    string            `yaml:"language"`
+	Headers     map[string]string `yaml:"headers"` // Extra HTTP headers for TMDB requests (e.g. proxy auth)
+	// RequestTimeoutSeconds bounds each TMDB API call (default: 10)
+	RequestTimeoutSeconds int `yaml:"request_timeout_seconds"`
+	// ImageTimeoutSeconds bounds each image download, body included (default: 60).
+	// With options.image_download_rate_kbps it bounds each wait for data instead.
+	ImageTimeoutSeconds int `yaml:"image_timeout_seconds"`
+	// APIBaseURL and ImageBaseURL point at a TMDB-compatible proxy or mock server
+	// (default: https://api.themoviedb.org/3 and https://image.tmdb.org/t/p)
+	APIBaseURL   string `yaml:"api_base_url"`
+	ImageBaseURL string `yaml:"image_base_url"`
+	// RequestsPerSecond caps TMDB API calls across all workers; when set it
+	// replaces options.rate_limit_delay (0 = use rate_limit_delay)
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+}
+
+// OMDbConfig holds optional OMDb API configuration for IMDb and Rotten Tomatoes
+// ratings. Enrichment is disabled when APIKey is empty.
+type OMDbConfig struct {
+	APIKey string `yaml:"api_key"`
 }
 
 // ScannerConfig holds scanner settings
 type ScannerConfig struct {
-	Directories       []string `yaml:"directories"`
-	Extensions        []string `yaml:"extensions"`
-	ExcludeDirs       []string `yaml:"exclude_dirs"`
-	ConcurrentWorkers int      `yaml:"concurrent_workers"` // Number of concurrent workers for parallel scanning (default: 5)
-	WatchMode         bool     `yaml:"watch_mode"`         // Enable watch mode to monitor directories for changes (default: false)
-	WatchDebounce     int      `yaml:"watch_debounce"`     // Seconds to wait after file change before processing (default: 30)
-	WatchRecursive    *bool    `yaml:"watch_recursive"`    // Watch subdirectories recursively (default: true, use pointer to detect nil)
-	ScheduleEnabled   bool     `yaml:"schedule_enabled"`   // Enable scheduled scans (default: false)
-	ScheduleInterval  int      `yaml:"schedule_interval"`  // Minutes between scans (default: 60)
-	ScheduleOnStartup *bool    `yaml:"schedule_on_startup"` // Run on startup (default: true, use pointer to detect nil)
+	Directories          []string `yaml:"directories"`
+	Extensions           []string `yaml:"extensions"`
+	ExcludeDirs          []string `yaml:"exclude_dirs"`
+	SkipTVFolders        bool     `yaml:"skip_tv_folders"`        // Skip directories that look like TV seasons, e.g. "Show S01" (default: false)
+	ExtraReleaseGroups   []string `yaml:"extra_release_groups"`   // Release groups stripped from titles in addition to the built-ins, e.g. "NovaRip"
+	ExtraQualityTokens   []string `yaml:"extra_quality_tokens"`   // Quality tags stripped from titles in addition to the built-ins, e.g. "DLMux"
+	ExtrasHandling       string   `yaml:"extras_handling"`        // "include" (default) or "skip" trailers, featurettes, and other bonus videos
+	ConcurrentWorkers    int      `yaml:"concurrent_workers"`     // Number of concurrent workers for parallel scanning (default: 5)
+	ImageDownloadWorkers int      `yaml:"image_download_workers"` // Number of concurrent image downloads (default: concurrent_workers)
+	WatchMode            bool     `yaml:"watch_mode"`             // Enable watch mode to monitor directories for changes (default: false)
+	WatchDebounce        int      `yaml:"watch_debounce"`         // Seconds to wait after file change before processing (default: 30)
+	WatchRecursive       *bool    `yaml:"watch_recursive"`        // Watch subdirectories recursively (default: true, use pointer to detect nil)
+	RecentWindow         int      `yaml:"recent_window"`          // Seconds a file processed by the watcher or a scan is skipped by the other (default: 300)
+	ScheduleEnabled      bool     `yaml:"schedule_enabled"`       // Enable scheduled scans (default: false)
+	ScheduleInterval     int      `yaml:"schedule_interval"`      // Minutes between scans (default: 60)
+	ScheduleOnStartup    *bool    `yaml:"schedule_on_startup"`    // Run on startup (default: true, use pointer to detect nil)
+	ScheduleInitialDelay int      `yaml:"schedule_initial_delay"` // Minutes before the first scan when schedule_on_startup is false (default: schedule_interval)
+
+	// DirectoryOverrides replace settings for individual scan directories,
+	// e.g. a different extension list per directory
+	DirectoryOverrides []DirectoryOverride `yaml:"directory_overrides"`
+
+	// IncludeBrokenFiles processes zero-byte and unreadable video files, such
+	// as failed downloads, instead of skipping them with a warning
+	IncludeBrokenFiles bool `yaml:"include_broken_files"`
+
+	imageWorkersDefaulted bool // ImageDownloadWorkers was left unset and follows ConcurrentWorkers
+}
+
+// SetConcurrentWorkers overrides concurrent_workers, e.g. from --workers.
+// image_download_workers follows it unless the config file set it.
+func (s *ScannerConfig) SetConcurrentWorkers(n int) {
+	s.ConcurrentWorkers = n
+	if s.imageWorkersDefaulted {
+		s.ImageDownloadWorkers = n
+	}
 }
 
 // OutputConfig holds output directory settings
@@ -46,6 +111,74 @@ type OutputConfig struct {
 	WebsiteDir     string `yaml:"website_dir"`
 	AutoBuild      bool   `yaml:"auto_build"`
 	CleanupMissing bool   `yaml:"cleanup_missing"`
+	NeverOverwrite bool   `yaml:"never_overwrite"` // Only create new MDX files, never rewrite existing ones (default: false)
+	// DateFormat is a Go time layout or a named preset (see dateFormatPresets) for
+	// dates rendered in MDX bodies. Load resolves presets to their layout.
+	DateFormat string `yaml:"date_format"`
+	// CoversLayout is "flat" (default, all images in covers_dir) or "sharded"
+	// (covers_dir/th/the-matrix-1999.jpg, by the first two slug characters)
+	CoversLayout string `yaml:"covers_layout"`
+	// TaxonomyDir receives genres.json and directors.json after each scan,
+	// listing every genre/director with its movie count and slugs (empty = disabled)
+	TaxonomyDir string `yaml:"taxonomy_dir"`
+	// WriteJSONSidecar writes each movie as <slug>.json into JSONSidecarDir, for
+	// tools that want structured data without parsing frontmatter
+	WriteJSONSidecar bool `yaml:"write_json_sidecar"`
+	// JSONSidecarDir receives the write_json_sidecar files. Required with
+	// write_json_sidecar, and kept out of mdx_dir: Astro may reject a content
+	// collection that mixes JSON and MDX entries.
+	JSONSidecarDir string `yaml:"json_sidecar_dir"`
+	// ReservedSlugs are site routes (e.g. "about", "index") that a movie slug
+	// must not take; a colliding slug gets a "-movie" suffix
+	ReservedSlugs []string `yaml:"reserved_slugs"`
+	// SortTitle adds sortTitle to the frontmatter, with a leading article moved
+	// to the end ("Matrix, The") for alphabetical browsing
+	SortTitle bool `yaml:"sort_title"`
+	// Articles are the leading words moved by sort_title and dropped by
+	// strip_articles_in_slug (default: The, A, An). Add localized ones such as
+	// "Il", "La", "Der" or "L'"; an article ending in an apostrophe needs no space.
+	Articles []string `yaml:"articles"`
+	// StripArticlesInSlug drops a leading article from slugs ("matrix-1999"
+	// instead of "the-matrix-1999")
+	StripArticlesInSlug bool `yaml:"strip_articles_in_slug"`
+	// BuildDebounceSeconds is how long watch mode waits after the last
+	// processed file before one auto_build rebuild (default: 60)
+	BuildDebounceSeconds int `yaml:"build_debounce_seconds"`
+}
+
+// defaultArticles are the leading articles used when output.articles is unset
+var defaultArticles = []string{"The", "A", "An"}
+
+// defaultDateFormat is the US English long form used when output.date_format is unset
+const defaultDateFormat = "January 2, 2006"
+
+// dateFormatPresets maps named output.date_format presets to Go time layouts
+var dateFormatPresets = map[string]string{
+	"long":    defaultDateFormat,
+	"iso":     "2006-01-02",
+	"eu":      "02/01/2006",
+	"us":      "01/02/2006",
+	"rfc3339": time.RFC3339,
+}
+
+// Metadata sources for SourceOverride.ForceSource
+const (
+	SourceTMDB  = "tmdb"  // Ignore NFO files and look up TMDB by filename
+	SourceNFO   = "nfo"   // Use the NFO only, without TMDB enrichment or fallback
+	SourceMerge = "merge" // Use the NFO and fill missing fields from TMDB
+)
+
+// SourceOverride forces the metadata source for videos whose path, or any
+// parent directory, matches Path (a filepath.Match glob, e.g. "/media/anime/*")
+type SourceOverride struct {
+	Path        string `yaml:"path"`
+	ForceSource string `yaml:"force_source"` // "tmdb", "nfo", or "merge"
+}
+
+// DirectoryOverride replaces scanner settings for one of scanner.directories
+type DirectoryOverride struct {
+	Path       string   `yaml:"path"`       // Must match an entry of scanner.directories
+	Extensions []string `yaml:"extensions"` // Replaces scanner.extensions for this directory
 }
 
 // OptionsConfig holds additional options
@@ -56,12 +189,97 @@ type OptionsConfig struct {
 	UseNFO            bool `yaml:"use_nfo"`
 	NFOFallbackTMDB   bool `yaml:"nfo_fallback_tmdb"`
 	NFODownloadImages bool `yaml:"nfo_download_images"` // Download images from NFO URLs when available (default: false)
+	// NFOPosterPreference is an ordered list of poster selection rules for NFO thumbs:
+	// "largest", "first", or a language code (e.g. "en"). Empty keeps the default
+	// of first "poster" aspect thumb, then first thumb.
+	NFOPosterPreference []string `yaml:"nfo_poster_preference"`
+	// NFORatingSource picks the NFO <ratings> entry by name (e.g. "imdb",
+	// "themoviedb"). Empty uses the entry marked default="true".
+	NFORatingSource string `yaml:"nfo_rating_source"`
+	// TitlePreference selects the TMDB title written to MDX: "localized" (default),
+	// "original", or "country:XX" for an ISO 3166-1 alternative title (e.g. "country:IT").
+	TitlePreference string `yaml:"title_preference"`
+	// PostProcessCommand runs after each successfully processed movie with the slug,
+	// title, MDX path, and cover path as arguments (and MOVIEVAULT_* env vars).
+	PostProcessCommand string `yaml:"post_process_command"`
+	PostProcessTimeout int    `yaml:"post_process_timeout"` // Seconds before the command is killed (default: 30)
+	// FetchKeywords adds TMDB keywords (e.g. "dystopia") to the frontmatter. Costs
+	// one extra request per movie unless folded into the combined details request.
+	FetchKeywords bool `yaml:"fetch_keywords"`
+	// IncludeFinancials adds the TMDB budget and revenue (USD) to the
+	// frontmatter and the MDX details. No extra requests are needed.
+	IncludeFinancials bool `yaml:"include_financials"`
+	// RefreshOlderThanDays rescans files whose existing MDX scannedAt is older
+	// than this many days, picking up rating changes (0 = disabled)
+	RefreshOlderThanDays int `yaml:"refresh_older_than_days"`
+	// RequireCredits fails a TMDB lookup when cast and crew cannot be fetched.
+	// By default the movie is written with an empty director and cast instead.
+	RequireCredits bool `yaml:"require_credits"`
+	// ImageDownloadRateKbps caps the combined bandwidth of cover and backdrop
+	// downloads in kilobits per second (0 = unlimited)
+	ImageDownloadRateKbps int `yaml:"image_download_rate_kbps"`
+	// SourceOverrides force the metadata source for matching files or folders,
+	// taking precedence over use_nfo and nfo_fallback_tmdb. First match wins.
+	SourceOverrides []SourceOverride `yaml:"source_overrides"`
+	// StoreImageURLs writes the remote TMDB (or NFO) poster and backdrop URLs to
+	// the frontmatter as posterUrl/backdropUrl, for sites that hotlink images
+	StoreImageURLs bool `yaml:"store_image_urls"`
+	// WriteUnmatchedMDX writes a placeholder MDX (filename title and year,
+	// "unmatched: true") when no metadata is found, and retries those files on
+	// later scans instead of skipping them
+	WriteUnmatchedMDX bool `yaml:"write_unmatched_mdx"`
+	// GenreAliases renames genres from TMDB and NFOs, e.g. "Science Fiction"
+	// to "Sci-Fi". Matching is case-insensitive; an empty value drops the genre.
+	GenreAliases map[string]string `yaml:"genre_aliases"`
+	// MinVoteCount makes TMDB searches prefer the most relevant result with at
+	// least this many votes over obscure entries (0 = take the first result)
+	MinVoteCount int `yaml:"min_vote_count"`
+	// MatchConfidenceThreshold warns when a TMDB search result's title is less
+	// similar than this (0-1) to the filename title, or its year is off by more
+	// than one (0 = disabled)
+	MatchConfidenceThreshold float64 `yaml:"match_confidence_threshold"`
+	// TagLowConfidence adds "lowConfidence: true" to the frontmatter of matches
+	// flagged by MatchConfidenceThreshold
+	TagLowConfidence bool `yaml:"tag_low_confidence"`
+	// OverwriteImages downloads covers and backdrops even when a non-empty copy
+	// already exists in covers_dir or the website's public directory
+	OverwriteImages bool `yaml:"overwrite_images"`
+	// MaxGenres keeps only the first N genres of each movie (0 = no limit)
+	MaxGenres int `yaml:"max_genres"`
+	// TMDBIDChange is what happens when a TMDB lookup returns a different ID
+	// than the existing MDX for the same slug: "warn" (default, write and log),
+	// "refuse" (keep the existing MDX and report an error), or "ignore"
+	TMDBIDChange string `yaml:"tmdb_id_change"`
+	// TMDBBlocklist lists TMDB IDs that are never picked from search results,
+	// e.g. spam entries that keep outranking the real film
+	TMDBBlocklist []int `yaml:"tmdb_blocklist"`
+	// MinFreeSpaceMB refuses to write images and MDX files when their disk has
+	// less than this many megabytes free (0 = no check)
+	MinFreeSpaceMB int `yaml:"min_free_space_mb"`
+	// FSWriteRetries retries failed MDX and image writes this many times with
+	// a short backoff, for flaky network storage (0 = no retry). Separate
+	// from retry, which only covers network requests.
+	FSWriteRetries int `yaml:"fs_write_retries"`
+	// PreferTMDBYear uses the TMDB release year and date instead of the NFO's
+	// when both are set and differ, e.g. festival vs wide release. Either way
+	// the conflict is logged. Locked NFOs always keep their year.
+	PreferTMDBYear bool `yaml:"prefer_tmdb_year"`
+	// ComputeFileHash stores a fingerprint of each video (size plus a hash of
+	// its first and last 64KB) as fileHash, so --find-duplicates can spot
+	// byte-identical copies whatever their names. Costs two reads per file.
+	ComputeFileHash bool `yaml:"compute_file_hash"`
+	// UseFFprobe reads each video's duration with ffprobe and, when a TMDB
+	// search returns several candidates, picks the one whose runtime is
+	// closest. Skipped with a warning when ffprobe is not installed.
+	UseFFprobe bool `yaml:"use_ffprobe"`
 }
 
 // RetryConfig holds retry behavior configuration
 type RetryConfig struct {
-	MaxAttempts      int `yaml:"max_attempts"`
-	InitialBackoffMs int `yaml:"initial_backoff_ms"`
+	MaxAttempts         int  `yaml:"max_attempts"`
+	InitialBackoffMs    int  `yaml:"initial_backoff_ms"`
+	MaxRateLimitRetries *int `yaml:"max_rate_limit_retries"` // Retries for HTTP 429, not counted against max_attempts (default: 5, use pointer so 0 can disable them)
+	MaxBackoffMs        int  `yaml:"max_backoff_ms"`         // Backoff stops doubling at this delay (default: 30000)
 }
 
 // CacheConfig holds cache behavior configuration
@@ -71,16 +289,26 @@ type CacheConfig struct {
 	TTLDays int    `yaml:"ttl_days"`
 }
 
-// Load reads and parses the configuration file
+// Load reads and parses the configuration file. A missing file returns an
+// error wrapping os.ErrNotExist, so callers can fall back to LoadFromEnv.
+// A profiles: section, if present, is ignored; see LoadProfile.
 func Load(path string) (*Config, error) {
-	// Expand ~ to home directory if present
+	return load(path, "")
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
 	if len(path) > 0 && path[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
 		}
-		path = filepath.Join(home, path[1:])
 	}
+	return path
+}
+
+// load reads the configuration file with the named profile (if any) merged in
+func load(path, profile string) (*Config, error) {
+	path = expandHome(path)
 
 	// Read the config file
 	data, err := os.ReadFile(path)
@@ -91,15 +319,46 @@ func Load(path string) (*Config, error) {
 	// Expand environment variables in the YAML content
 	expandedData := os.ExpandEnv(string(data))
 
+	// Merge the selected profile over the shared settings
+	merged, err := ApplyProfile([]byte(expandedData), profile)
+	if err != nil {
+		if errors.Is(err, ErrProfileNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	expandedData = string(merged)
+
 	// Parse YAML
 	var cfg Config
 	if err := yaml.Unmarshal([]byte(expandedData), &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Warn about unrecognized keys (usually typos) that yaml.v3 silently ignores
+	for _, field := range FindUnknownFields([]byte(expandedData)) {
+		slog.Warn("unknown config key ignored (check for typos)",
+			"key", field.Key,
+			"line", field.Line,
+			"section", field.Section,
+		)
+	}
+
+	return finalize(&cfg)
+}
+
+// finalize applies defaults to a parsed configuration, checks required
+// fields, creates the output directories, and validates option values
+func finalize(cfg *Config) (*Config, error) {
 	// Validate required fields
-	if cfg.TMDB.APIKey == "" || cfg.TMDB.APIKey == "your_api_key_here" {
-		return nil, fmt.Errorf("TMDB API key is required. Get one from https://www.themoviedb.org/settings/api")
+	// Exactly one TMDB credential: v3 api_key or v4 access_token
+	hasAPIKey := cfg.TMDB.APIKey != "" && cfg.TMDB.APIKey != "your_api_key_here"
+	hasAccessToken := cfg.TMDB.AccessToken != ""
+	if !hasAPIKey && !hasAccessToken {
+		return nil, fmt.Errorf("TMDB API key or access token is required. Get one from https://www.themoviedb.org/settings/api")
+	}
+	if hasAPIKey && hasAccessToken {
+		return nil, fmt.Errorf("tmdb.api_key and tmdb.access_token are mutually exclusive; set only one")
 	}
 
 	// Set default language if not specified
@@ -107,6 +366,14 @@ func Load(path string) (*Config, error) {
 		cfg.TMDB.Language = "en-US"
 	}
 
+	// Set default TMDB timeouts: short for API calls, generous for images
+	if cfg.TMDB.RequestTimeoutSeconds == 0 {
+		cfg.TMDB.RequestTimeoutSeconds = 10
+	}
+	if cfg.TMDB.ImageTimeoutSeconds == 0 {
+		cfg.TMDB.ImageTimeoutSeconds = 60
+	}
+
 	// Set default retry settings
 	if cfg.Retry.MaxAttempts == 0 {
 		cfg.Retry.MaxAttempts = 3
@@ -114,6 +381,19 @@ func Load(path string) (*Config, error) {
 	if cfg.Retry.InitialBackoffMs == 0 {
 		cfg.Retry.InitialBackoffMs = 1000
 	}
+	// MaxRateLimitRetries defaults to 5; *int keeps an explicit 0 (never retry 429s)
+	if cfg.Retry.MaxRateLimitRetries == nil {
+		defaultRetries := 5
+		cfg.Retry.MaxRateLimitRetries = &defaultRetries
+	}
+	if cfg.Retry.MaxBackoffMs == 0 {
+		cfg.Retry.MaxBackoffMs = 30000
+	}
+	// Configs with a long initial backoff predate max_backoff_ms; raise the cap
+	// rather than reject them
+	if cfg.Retry.MaxBackoffMs < cfg.Retry.InitialBackoffMs {
+		cfg.Retry.MaxBackoffMs = cfg.Retry.InitialBackoffMs
+	}
 
 	// Set default cache settings
 	// Default Path is always set; if user provides no cache section, we also default Enabled to true.
@@ -127,11 +407,27 @@ func Load(path string) (*Config, error) {
 		cfg.Cache.TTLDays = 30
 	}
 
+	// Set default title preference
+	if cfg.Options.TitlePreference == "" {
+		cfg.Options.TitlePreference = "localized"
+	}
+
+	// Set default post-process command timeout
+	if cfg.Options.PostProcessTimeout == 0 {
+		cfg.Options.PostProcessTimeout = 30
+	}
+
 	// Set default concurrent workers
 	if cfg.Scanner.ConcurrentWorkers == 0 {
 		cfg.Scanner.ConcurrentWorkers = 5
 	}
 
+	// Image downloads default to the same parallelism as metadata workers
+	if cfg.Scanner.ImageDownloadWorkers == 0 {
+		cfg.Scanner.ImageDownloadWorkers = cfg.Scanner.ConcurrentWorkers
+		cfg.Scanner.imageWorkersDefaulted = true
+	}
+
 	// Set default watch settings
 	// WatchMode defaults to false (Go zero value) - no explicit set needed
 	if cfg.Scanner.WatchDebounce == 0 {
@@ -142,6 +438,9 @@ func Load(path string) (*Config, error) {
 		defaultTrue := true
 		cfg.Scanner.WatchRecursive = &defaultTrue
 	}
+	if cfg.Scanner.RecentWindow == 0 {
+		cfg.Scanner.RecentWindow = 300
+	}
 
 	// Set default schedule settings
 	// ScheduleEnabled defaults to false (Go zero value) - no explicit set needed
@@ -166,6 +465,43 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("covers_dir is required")
 	}
 
+	// Set default date format and resolve named presets
+	if cfg.Output.DateFormat == "" {
+		cfg.Output.DateFormat = defaultDateFormat
+	}
+	if layout, ok := dateFormatPresets[strings.ToLower(cfg.Output.DateFormat)]; ok {
+		cfg.Output.DateFormat = layout
+	}
+
+	// Strip trailing slashes from TMDB base URLs to avoid double-slash request paths
+	cfg.TMDB.APIBaseURL = strings.TrimRight(cfg.TMDB.APIBaseURL, "/")
+	cfg.TMDB.ImageBaseURL = strings.TrimRight(cfg.TMDB.ImageBaseURL, "/")
+
+	// Set default extras handling
+	if cfg.Scanner.ExtrasHandling == "" {
+		cfg.Scanner.ExtrasHandling = "include"
+	}
+
+	// Set default covers layout
+	if cfg.Output.CoversLayout == "" {
+		cfg.Output.CoversLayout = "flat"
+	}
+
+	// Set default watch mode build debounce
+	if cfg.Output.BuildDebounceSeconds == 0 {
+		cfg.Output.BuildDebounceSeconds = 60
+	}
+
+	// Set default leading articles for sort titles and slugs
+	if len(cfg.Output.Articles) == 0 {
+		cfg.Output.Articles = append([]string(nil), defaultArticles...)
+	}
+
+	// Set default TMDB ID change handling
+	if cfg.Options.TMDBIDChange == "" {
+		cfg.Options.TMDBIDChange = "warn"
+	}
+
 	// Ensure output directories exist
 	if err := os.MkdirAll(cfg.Output.MDXDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create MDX directory: %w", err)
@@ -180,7 +516,123 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
-	return &cfg, nil
+	return cfg, nil
+}
+
+// MetadataSource returns the use_nfo and nfo_fallback_tmdb settings that apply
+// to the video at path: those of the first source override matching the path
+// or one of its parent directories, otherwise the global options
+func (cfg *Config) MetadataSource(path string) (useNFO, fallbackTMDB bool) {
+	for _, override := range cfg.Options.SourceOverrides {
+		if !matchesPathOrParent(override.Path, path) {
+			continue
+		}
+		switch override.ForceSource {
+		case SourceTMDB:
+			return false, true
+		case SourceNFO:
+			return true, false
+		case SourceMerge:
+			return true, true
+		}
+	}
+	return cfg.Options.UseNFO, cfg.Options.NFOFallbackTMDB
+}
+
+// UsesNFO reports whether NFO files are read for any video: use_nfo is set,
+// or a source override forces "nfo" or "merge" for some path
+func (cfg *Config) UsesNFO() bool {
+	if cfg.Options.UseNFO {
+		return true
+	}
+	for _, override := range cfg.Options.SourceOverrides {
+		if override.ForceSource == SourceNFO || override.ForceSource == SourceMerge {
+			return true
+		}
+	}
+	return false
+}
+
+// DirectoryExtensions returns the extension lists of directory_overrides
+// keyed by cleaned directory path, for scanner.Options.DirExtensions
+func (cfg *Config) DirectoryExtensions() map[string][]string {
+	extensions := make(map[string][]string)
+	for _, override := range cfg.Scanner.DirectoryOverrides {
+		if len(override.Extensions) > 0 {
+			extensions[filepath.Clean(override.Path)] = override.Extensions
+		}
+	}
+	return extensions
+}
+
+// OutputDirs returns the configured directories the scanner writes to:
+// mdx_dir, covers_dir, taxonomy_dir, and json_sidecar_dir when set
+func (cfg *Config) OutputDirs() []string {
+	var dirs []string
+	for _, dir := range []string{cfg.Output.MDXDir, cfg.Output.CoversDir, cfg.Output.TaxonomyDir, cfg.Output.JSONSidecarDir} {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// isWithinDir reports whether path is dir or lies below it, comparing
+// absolute paths
+func isWithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// matchesPathOrParent reports whether pattern matches path or any of its
+// parent directories, so a folder pattern covers everything below it
+func matchesPathOrParent(pattern, path string) bool {
+	pattern = filepath.Clean(pattern)
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if ok, _ := filepath.Match(pattern, p); ok {
+			return true
+		}
+		if parent := filepath.Dir(p); parent == p {
+			return false
+		}
+	}
+}
+
+// FindUnknownFields decodes YAML config data strictly and returns every key that
+// does not correspond to a Config field. Syntax errors are ignored here since the
+// regular (non-strict) decode reports them.
+func FindUnknownFields(data []byte) []UnknownField {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var strict Config
+	var typeErr *yaml.TypeError
+	if err := decoder.Decode(&strict); !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	var unknown []UnknownField
+	for _, msg := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		unknown = append(unknown, UnknownField{
+			Key:     match[2],
+			Line:    line,
+			Section: match[3],
+		})
+	}
+	return unknown
 }
 
 // validate performs validation on configuration options (US-028)
@@ -193,6 +645,32 @@ func (cfg *Config) validate() error {
 		slog.Warn("high concurrent_workers value may cause TMDB rate limit issues", "workers", cfg.Scanner.ConcurrentWorkers)
 	}
 
+	// Validate image_download_workers is positive
+	if cfg.Scanner.ImageDownloadWorkers < 1 {
+		return fmt.Errorf("scanner.image_download_workers must be at least 1 (got %d)", cfg.Scanner.ImageDownloadWorkers)
+	}
+
+	// Validate tmdb base URLs (empty uses the public TMDB endpoints)
+	for key, value := range map[string]string{
+		"tmdb.api_base_url":   cfg.TMDB.APIBaseURL,
+		"tmdb.image_base_url": cfg.TMDB.ImageBaseURL,
+	} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an http(s) URL (got %q)", key, value)
+		}
+	}
+
+	// Validate tmdb.headers names are non-empty
+	for name := range cfg.TMDB.Headers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("tmdb.headers contains an empty header name")
+		}
+	}
+
 	// Validate retry.max_attempts is positive
 	if cfg.Retry.MaxAttempts <= 0 {
 		return fmt.Errorf("retry.max_attempts must be positive (got %d)", cfg.Retry.MaxAttempts)
@@ -203,6 +681,29 @@ func (cfg *Config) validate() error {
 		return fmt.Errorf("retry.initial_backoff_ms must be positive (got %d)", cfg.Retry.InitialBackoffMs)
 	}
 
+	// Validate retry.max_rate_limit_retries is not negative
+	if *cfg.Retry.MaxRateLimitRetries < 0 {
+		return fmt.Errorf("retry.max_rate_limit_retries must not be negative (got %d)", *cfg.Retry.MaxRateLimitRetries)
+	}
+
+	// Validate TMDB timeouts are positive
+	if cfg.TMDB.RequestTimeoutSeconds < 0 {
+		return fmt.Errorf("tmdb.request_timeout_seconds must be positive (got %d)", cfg.TMDB.RequestTimeoutSeconds)
+	}
+	if cfg.TMDB.ImageTimeoutSeconds < 0 {
+		return fmt.Errorf("tmdb.image_timeout_seconds must be positive (got %d)", cfg.TMDB.ImageTimeoutSeconds)
+	}
+
+	if cfg.TMDB.RequestsPerSecond < 0 {
+		return fmt.Errorf("tmdb.requests_per_second must be 0 (use rate_limit_delay) or positive (got %g)", cfg.TMDB.RequestsPerSecond)
+	}
+	if cfg.TMDB.RequestsPerSecond > 0 && cfg.Options.RateLimitDelay > 0 {
+		slog.Warn("both tmdb.requests_per_second and options.rate_limit_delay are set; requests_per_second is used (set rate_limit_delay to 0 to silence this)",
+			"requests_per_second", cfg.TMDB.RequestsPerSecond,
+			"rate_limit_delay", cfg.Options.RateLimitDelay,
+		)
+	}
+
 	// Validate cache path parent directory exists and is writable when cache is enabled
 	if cfg.Cache.Enabled {
 		cacheParentDir := filepath.Dir(cfg.Cache.Path)
@@ -222,9 +723,133 @@ func (cfg *Config) validate() error {
 		}
 	}
 
-	// Warn if nfo_download_images: true but use_nfo: false
-	if cfg.Options.NFODownloadImages && !cfg.Options.UseNFO {
-		slog.Warn("nfo_download_images is enabled but use_nfo is disabled; NFO image URLs will not be available")
+	// Validate date_format is a usable Go time layout: formatting a sample time
+	// must substitute at least one layout element
+	if sample := time.Date(1999, time.November, 30, 22, 47, 33, 0, time.UTC); sample.Format(cfg.Output.DateFormat) == cfg.Output.DateFormat {
+		return fmt.Errorf("output.date_format %q is not a Go time layout or preset (e.g. \"iso\", \"January 2, 2006\")", cfg.Output.DateFormat)
+	}
+
+	// Validate extras_handling
+	if cfg.Scanner.ExtrasHandling != "include" && cfg.Scanner.ExtrasHandling != "skip" {
+		return fmt.Errorf("scanner.extras_handling must be \"include\" or \"skip\" (got %q)", cfg.Scanner.ExtrasHandling)
+	}
+
+	// JSON sidecars need their own directory, outside the MDX collection
+	if cfg.Output.WriteJSONSidecar {
+		if cfg.Output.JSONSidecarDir == "" {
+			return fmt.Errorf("output.write_json_sidecar requires output.json_sidecar_dir")
+		}
+		if isWithinDir(cfg.Output.MDXDir, cfg.Output.JSONSidecarDir) {
+			return fmt.Errorf("output.json_sidecar_dir %q must not be inside output.mdx_dir, which Astro reads as an MDX collection", cfg.Output.JSONSidecarDir)
+		}
+	}
+
+	// Validate covers_layout
+	if cfg.Output.CoversLayout != "flat" && cfg.Output.CoversLayout != "sharded" {
+		return fmt.Errorf("output.covers_layout must be \"flat\" or \"sharded\" (got %q)", cfg.Output.CoversLayout)
+	}
+
+	// Validate title_preference
+	switch pref := cfg.Options.TitlePreference; {
+	case pref == "localized", pref == "original":
+	case strings.HasPrefix(pref, "country:") && len(strings.TrimPrefix(pref, "country:")) == 2:
+	default:
+		return fmt.Errorf("options.title_preference must be \"localized\", \"original\", or \"country:XX\" (got %q)", pref)
+	}
+
+	// Validate build_debounce_seconds is positive
+	if cfg.Output.BuildDebounceSeconds < 0 {
+		return fmt.Errorf("output.build_debounce_seconds must be positive (got %d)", cfg.Output.BuildDebounceSeconds)
+	}
+
+	// Validate post_process_timeout is positive
+	if cfg.Options.PostProcessTimeout < 0 {
+		return fmt.Errorf("options.post_process_timeout must be positive (got %d)", cfg.Options.PostProcessTimeout)
+	}
+
+	// Validate image_download_rate_kbps is not negative
+	if cfg.Options.ImageDownloadRateKbps < 0 {
+		return fmt.Errorf("options.image_download_rate_kbps must be 0 (unlimited) or positive (got %d)", cfg.Options.ImageDownloadRateKbps)
+	}
+
+	// Validate refresh_older_than_days is not negative
+	if cfg.Options.RefreshOlderThanDays < 0 {
+		return fmt.Errorf("options.refresh_older_than_days must be 0 (disabled) or positive (got %d)", cfg.Options.RefreshOlderThanDays)
+	}
+	if cfg.Options.MinVoteCount < 0 {
+		return fmt.Errorf("options.min_vote_count must be 0 (disabled) or positive (got %d)", cfg.Options.MinVoteCount)
+	}
+	if cfg.Options.MatchConfidenceThreshold < 0 || cfg.Options.MatchConfidenceThreshold > 1 {
+		return fmt.Errorf("options.match_confidence_threshold must be between 0 (disabled) and 1 (got %g)", cfg.Options.MatchConfidenceThreshold)
+	}
+	if cfg.Options.MaxGenres < 0 {
+		return fmt.Errorf("options.max_genres must be 0 (no limit) or positive (got %d)", cfg.Options.MaxGenres)
+	}
+	switch cfg.Options.TMDBIDChange {
+	case "warn", "refuse", "ignore":
+	default:
+		return fmt.Errorf("options.tmdb_id_change must be \"warn\", \"refuse\", or \"ignore\" (got %q)", cfg.Options.TMDBIDChange)
+	}
+	if cfg.Options.FSWriteRetries < 0 {
+		return fmt.Errorf("options.fs_write_retries must be 0 (disabled) or positive (got %d)", cfg.Options.FSWriteRetries)
+	}
+	if cfg.Options.MinFreeSpaceMB < 0 {
+		return fmt.Errorf("options.min_free_space_mb must be 0 (disabled) or positive (got %d)", cfg.Options.MinFreeSpaceMB)
+	}
+	for _, id := range cfg.Options.TMDBBlocklist {
+		if id <= 0 {
+			return fmt.Errorf("options.tmdb_blocklist entries must be positive TMDB IDs (got %d)", id)
+		}
+	}
+
+	// Validate directory_overrides
+	for i, override := range cfg.Scanner.DirectoryOverrides {
+		if override.Path == "" {
+			return fmt.Errorf("scanner.directory_overrides[%d].path is required", i)
+		}
+		known := false
+		for _, dir := range cfg.Scanner.Directories {
+			if filepath.Clean(dir) == filepath.Clean(override.Path) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("scanner.directory_overrides[%d].path must be one of scanner.directories (got %q)", i, override.Path)
+		}
+	}
+
+	// Validate source_overrides
+	for i, override := range cfg.Options.SourceOverrides {
+		if override.Path == "" {
+			return fmt.Errorf("options.source_overrides[%d].path is required", i)
+		}
+		if _, err := filepath.Match(override.Path, ""); err != nil {
+			return fmt.Errorf("options.source_overrides[%d].path is not a valid glob (got %q): %w", i, override.Path, err)
+		}
+		switch override.ForceSource {
+		case SourceTMDB, SourceNFO, SourceMerge:
+		default:
+			return fmt.Errorf("options.source_overrides[%d].force_source must be \"tmdb\", \"nfo\", or \"merge\" (got %q)", i, override.ForceSource)
+		}
+	}
+
+	// Warn if nfo_download_images: true but no video is read from an NFO
+	if cfg.Options.NFODownloadImages && !cfg.UsesNFO() {
+		slog.Warn("nfo_download_images is enabled but use_nfo is disabled and no source override uses nfo; NFO image URLs will not be available")
+	}
+
+	// Output inside a scan directory is skipped by the scanner, but is usually
+	// a mistake (e.g. scanning the parent of the website checkout)
+	for _, out := range cfg.OutputDirs() {
+		for _, dir := range cfg.Scanner.Directories {
+			if isWithinDir(out, dir) {
+				return fmt.Errorf("scan directory %q is inside output directory %q; it would never be scanned", dir, out)
+			}
+			if isWithinDir(dir, out) {
+				slog.Warn("output directory is inside a scan directory; it will not be scanned", "output_dir", out, "scan_dir", dir)
+			}
+		}
 	}
 
 	// Warn if watch_mode: true but no directories configured
@@ -232,6 +857,10 @@ func (cfg *Config) validate() error {
 		slog.Warn("watch_mode is enabled but no directories are configured; nothing to watch")
 	}
 
+	if cfg.Scanner.RecentWindow < 0 {
+		return fmt.Errorf("scanner.recent_window must be positive (got %d)", cfg.Scanner.RecentWindow)
+	}
+
 	// Validate cache TTL is positive when cache is enabled
 	if cfg.Cache.Enabled && cfg.Cache.TTLDays <= 0 {
 		return fmt.Errorf("cache.ttl_days must be positive when cache is enabled (got %d)", cfg.Cache.TTLDays)
@@ -242,6 +871,9 @@ func (cfg *Config) validate() error {
 		if cfg.Scanner.ScheduleInterval <= 0 {
 			return fmt.Errorf("scanner.schedule_interval must be positive when schedule_enabled is true (got %d)", cfg.Scanner.ScheduleInterval)
 		}
+		if cfg.Scanner.ScheduleInitialDelay < 0 {
+			return fmt.Errorf("scanner.schedule_initial_delay must be 0 (use schedule_interval) or positive (got %d)", cfg.Scanner.ScheduleInitialDelay)
+		}
 		if cfg.Scanner.ScheduleInterval < 5 {
 			slog.Warn("very frequent scheduled scans may cause high CPU/API usage",
 				"interval_minutes", cfg.Scanner.ScheduleInterval,
@@ -0,0 +1,480 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindUnknownFields(t *testing.T) {
+	data := []byte(`tmdb:
+  api_key: "abc"
+scanner:
+  directories:
+    - "/movies"
+  concurrent_worker: 10
+options:
+  download_cover: true
+`)
+
+	unknown := FindUnknownFields(data)
+	if len(unknown) != 2 {
+		t.Fatalf("expected 2 unknown fields, got %d: %+v", len(unknown), unknown)
+	}
+
+	if unknown[0].Key != "concurrent_worker" || unknown[0].Line != 6 || unknown[0].Section != "config.ScannerConfig" {
+		t.Errorf("unexpected first unknown field: %+v", unknown[0])
+	}
+	if unknown[1].Key != "download_cover" || unknown[1].Line != 8 {
+		t.Errorf("unexpected second unknown field: %+v", unknown[1])
+	}
+}
+
+func TestFindUnknownFields_AllKnown(t *testing.T) {
+	data := []byte(`tmdb:
+  api_key: "abc"
+scanner:
+  concurrent_workers: 10
+`)
+
+	if unknown := FindUnknownFields(data); len(unknown) != 0 {
+		t.Errorf("expected no unknown fields, got %+v", unknown)
+	}
+}
+
+// loadTestConfig writes a minimal valid config with the given tmdb and extra
+// output section lines to a temp dir and loads it
+func loadTestConfig(t *testing.T, tmdb, output string) (*Config, error) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	data := fmt.Sprintf(`tmdb:
+  %s
+scanner:
+  directories:
+    - %q
+output:
+  mdx_dir: %q
+  covers_dir: %q
+  %s
+cache:
+  enabled: false
+`, tmdb, dir, filepath.Join(dir, "mdx"), filepath.Join(dir, "covers"), output)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return Load(path)
+}
+
+func TestSetConcurrentWorkers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		scanner    string
+		wantImages int
+	}{
+		{"image workers follow --workers", "", 12},
+		{"configured image workers kept", "image_download_workers: 2", 2},
+		{"configured equal to workers kept", "concurrent_workers: 3\n  image_download_workers: 3", 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			data := fmt.Sprintf("tmdb:\n  api_key: \"abc\"\nscanner:\n  directories:\n    - %q\n  %s\noutput:\n  mdx_dir: %q\n  covers_dir: %q\ncache:\n  enabled: false\n",
+				dir, tc.scanner, filepath.Join(dir, "mdx"), filepath.Join(dir, "covers"))
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			cfg.Scanner.SetConcurrentWorkers(12)
+			if cfg.Scanner.ConcurrentWorkers != 12 {
+				t.Errorf("ConcurrentWorkers = %d, want 12", cfg.Scanner.ConcurrentWorkers)
+			}
+			if cfg.Scanner.ImageDownloadWorkers != tc.wantImages {
+				t.Errorf("ImageDownloadWorkers = %d, want %d", cfg.Scanner.ImageDownloadWorkers, tc.wantImages)
+			}
+		})
+	}
+}
+
+func TestLoad_TMDBCredentials(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tmdb    string
+		wantErr string
+	}{
+		{"api key only", `api_key: "abc"`, ""},
+		{"access token only", `access_token: "jwt"`, ""},
+		{"neither", `language: "en-US"`, "API key or access token is required"},
+		{"both", "api_key: \"abc\"\n  access_token: \"jwt\"", "mutually exclusive"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tc.tmdb, "")
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_MaxBackoffBelowInitial(t *testing.T) {
+	testCases := []struct {
+		name  string
+		retry string
+		want  int
+	}{
+		{"default cap raised", "initial_backoff_ms: 60000", 60000},
+		{"configured cap raised", "initial_backoff_ms: 60000\n  max_backoff_ms: 5000", 60000},
+		{"cap above initial kept", "initial_backoff_ms: 500\n  max_backoff_ms: 5000", 5000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadTestConfig(t, `api_key: "abc"`, "never_overwrite: false\nretry:\n  "+tc.retry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Retry.MaxBackoffMs != tc.want {
+				t.Errorf("MaxBackoffMs = %d, want %d", cfg.Retry.MaxBackoffMs, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoad_JSONSidecarDir(t *testing.T) {
+	testCases := []struct {
+		name    string
+		output  string // %[1]s is the temp dir holding mdx_dir
+		wantErr string
+	}{
+		{"separate directory", "write_json_sidecar: true\n  json_sidecar_dir: %[1]s/json", ""},
+		{"missing directory", "write_json_sidecar: true", "requires output.json_sidecar_dir"},
+		{"mdx directory", "write_json_sidecar: true\n  json_sidecar_dir: %[1]s/mdx", "must not be inside output.mdx_dir"},
+		{"below mdx directory", "write_json_sidecar: true\n  json_sidecar_dir: %[1]s/mdx/json", "must not be inside output.mdx_dir"},
+		{"disabled", "write_json_sidecar: false\n  json_sidecar_dir: %[1]s/mdx", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			data := fmt.Sprintf("tmdb:\n  api_key: \"abc\"\nscanner:\n  directories:\n    - %[2]q\noutput:\n  mdx_dir: %[1]s/mdx\n  covers_dir: %[1]s/covers\n  "+tc.output+"\ncache:\n  enabled: false\n", dir, t.TempDir())
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			_, err := Load(path)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_MaxRateLimitRetries(t *testing.T) {
+	testCases := []struct {
+		name  string
+		retry string
+		want  int
+	}{
+		{"default", "max_attempts: 3", 5},
+		{"explicit zero disables", "max_rate_limit_retries: 0", 0},
+		{"configured", "max_rate_limit_retries: 2", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadTestConfig(t, `api_key: "abc"`, "never_overwrite: false\nretry:\n  "+tc.retry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := *cfg.Retry.MaxRateLimitRetries; got != tc.want {
+				t.Errorf("MaxRateLimitRetries = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoad_DateFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+		wantErr  bool
+	}{
+		{"default", "", "January 2, 2006", false},
+		{"iso preset", `date_format: "iso"`, "2006-01-02", false},
+		{"preset case-insensitive", `date_format: "ISO"`, "2006-01-02", false},
+		{"custom layout", `date_format: "2 Jan 2006"`, "2 Jan 2006", false},
+		{"invalid layout", `date_format: "yyyy-mm-dd"`, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadTestConfig(t, `api_key: "abc"`, tc.output)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "date_format") {
+					t.Errorf("expected date_format error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Output.DateFormat != tc.expected {
+				t.Errorf("DateFormat = %q, want %q", cfg.Output.DateFormat, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLoad_OutputInsideScanDir(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name    string
+		mdxDir  string
+		wantErr bool
+	}{
+		{"separate", filepath.Join(dir, "site", "mdx"), false},
+		{"inside scan dir", filepath.Join(dir, "movies", "mdx"), false},
+		{"same as scan dir", filepath.Join(dir, "movies"), true},
+		{"scan dir inside output", dir, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			data := fmt.Sprintf("tmdb:\n  api_key: \"abc\"\nscanner:\n  directories: [%q]\noutput:\n  mdx_dir: %q\n  covers_dir: %q\ncache:\n  enabled: false\n",
+				filepath.Join(dir, "movies"), tc.mdxDir, filepath.Join(dir, "site", "covers"))
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			_, err := Load(path)
+			if tc.wantErr && (err == nil || !strings.Contains(err.Error(), "never be scanned")) {
+				t.Errorf("expected nested output error, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_TMDBBaseURLs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tmdb     string
+		expected string
+		wantErr  bool
+	}{
+		{"default", `api_key: "abc"`, "", false},
+		{"trailing slash stripped", "api_key: \"abc\"\n  api_base_url: \"http://proxy.lan:8080/3/\"", "http://proxy.lan:8080/3", false},
+		{"missing scheme", "api_key: \"abc\"\n  api_base_url: \"proxy.lan/3\"", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadTestConfig(t, tc.tmdb, "")
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "api_base_url") {
+					t.Errorf("expected api_base_url error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.TMDB.APIBaseURL != tc.expected {
+				t.Errorf("APIBaseURL = %q, want %q", cfg.TMDB.APIBaseURL, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MOVIEVAULT_TMDB_API_KEY", "abc")
+	t.Setenv("MOVIEVAULT_SCAN_DIRS", "/movies, /more-movies")
+	t.Setenv("MOVIEVAULT_OUTPUT_MDX_DIR", filepath.Join(dir, "movies"))
+	t.Setenv("MOVIEVAULT_OUTPUT_COVERS_DIR", filepath.Join(dir, "covers"))
+	t.Setenv("MOVIEVAULT_SCANNER_WATCH_RECURSIVE", "false")
+	t.Setenv("MOVIEVAULT_OPTIONS_USE_NFO", "0")
+	t.Setenv("MOVIEVAULT_RETRY_MAX_ATTEMPTS", "7")
+	t.Setenv("MOVIEVAULT_TMDB_HEADERS", "X-Proxy=1")
+	t.Setenv("MOVIEVAULT_TMDB_REQUESTS_PER_SECOND", "2.5")
+	t.Setenv("MOVIEVAULT_CACHE_ENABLED", "false")
+	t.Setenv("MOVIEVAULT_CACHE_PATH", filepath.Join(dir, "cache.db"))
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+
+	// A missing config file is reported, not replaced by the environment
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load(missing.yaml) error = %v, want os.ErrNotExist", err)
+	}
+
+	if cfg.TMDB.APIKey != "abc" || cfg.TMDB.Headers["X-Proxy"] != "1" || cfg.TMDB.RequestsPerSecond != 2.5 {
+		t.Errorf("unexpected tmdb config: %+v", cfg.TMDB)
+	}
+	if len(cfg.Scanner.Directories) != 2 || cfg.Scanner.Directories[1] != "/more-movies" {
+		t.Errorf("Directories = %v", cfg.Scanner.Directories)
+	}
+	if *cfg.Scanner.WatchRecursive || cfg.Options.UseNFO || cfg.Retry.MaxAttempts != 7 || cfg.Cache.Enabled {
+		t.Errorf("env overrides not applied: watch_recursive=%v use_nfo=%v max_attempts=%d cache=%v",
+			*cfg.Scanner.WatchRecursive, cfg.Options.UseNFO, cfg.Retry.MaxAttempts, cfg.Cache.Enabled)
+	}
+	// Unset keys keep the example defaults
+	if len(cfg.Scanner.Extensions) == 0 || !cfg.Options.DownloadCovers || cfg.Retry.InitialBackoffMs != 1000 {
+		t.Errorf("expected example defaults, got extensions=%v download_covers=%v initial_backoff_ms=%d",
+			cfg.Scanner.Extensions, cfg.Options.DownloadCovers, cfg.Retry.InitialBackoffMs)
+	}
+
+	t.Setenv("MOVIEVAULT_RETRY_MAX_ATTEMPTS", "many")
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "MOVIEVAULT_RETRY_MAX_ATTEMPTS") {
+		t.Errorf("expected invalid value error, got %v", err)
+	}
+}
+
+func TestMetadataSource(t *testing.T) {
+	cfg := &Config{}
+	cfg.Options.UseNFO = true
+	cfg.Options.NFOFallbackTMDB = true
+	cfg.Options.SourceOverrides = []SourceOverride{
+		{Path: "/media/imports/*", ForceSource: SourceTMDB},
+		{Path: "/media/curated", ForceSource: SourceNFO},
+		{Path: "/media/curated/*/Heat*", ForceSource: SourceMerge},
+	}
+
+	testCases := []struct {
+		path         string
+		useNFO       bool
+		fallbackTMDB bool
+	}{
+		{"/media/imports/Heat (1995)/Heat.mkv", false, true},
+		{"/media/imports/Heat.mkv", false, true},
+		{"/media/curated/Alien (1979)/Alien.mkv", true, false},
+		{"/media/curated/Heat (1995)/Heat.mkv", true, false}, // earlier folder override wins
+		{"/media/other/Heat.mkv", true, true},
+	}
+
+	for _, tc := range testCases {
+		useNFO, fallbackTMDB := cfg.MetadataSource(tc.path)
+		if useNFO != tc.useNFO || fallbackTMDB != tc.fallbackTMDB {
+			t.Errorf("MetadataSource(%q) = %v, %v; want %v, %v", tc.path, useNFO, fallbackTMDB, tc.useNFO, tc.fallbackTMDB)
+		}
+	}
+}
+
+func TestUsesNFO(t *testing.T) {
+	testCases := []struct {
+		name      string
+		useNFO    bool
+		overrides []SourceOverride
+		want      bool
+	}{
+		{"disabled", false, nil, false},
+		{"global", true, nil, true},
+		{"tmdb override only", false, []SourceOverride{{Path: "/media/imports", ForceSource: SourceTMDB}}, false},
+		{"nfo override", false, []SourceOverride{{Path: "/media/curated", ForceSource: SourceNFO}}, true},
+		{"merge override", false, []SourceOverride{{Path: "/media/curated", ForceSource: SourceMerge}}, true},
+	}
+
+	for _, tc := range testCases {
+		cfg := &Config{}
+		cfg.Options.UseNFO = tc.useNFO
+		cfg.Options.SourceOverrides = tc.overrides
+		if got := cfg.UsesNFO(); got != tc.want {
+			t.Errorf("%s: UsesNFO() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	data := fmt.Sprintf(`tmdb:
+  api_key: "abc"
+  language: "en-US"
+scanner:
+  directories:
+    - %q
+  concurrent_workers: 3
+output:
+  mdx_dir: %q
+  covers_dir: %q
+cache:
+  enabled: false
+profiles:
+  kids:
+    tmdb:
+      language: "it-IT"
+    scanner:
+      directories:
+        - %q
+`, dir, filepath.Join(dir, "mdx"), filepath.Join(dir, "covers"), filepath.Join(dir, "kids"))
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	standalone := fmt.Sprintf("tmdb:\n  api_key: \"xyz\"\nscanner:\n  directories: [%q]\noutput:\n  mdx_dir: %q\n  covers_dir: %q\ncache:\n  enabled: false\n", dir, filepath.Join(dir, "mdx"), filepath.Join(dir, "covers"))
+	if err := os.WriteFile(filepath.Join(dir, "family.yaml"), []byte(standalone), 0644); err != nil {
+		t.Fatalf("failed to write profile file: %v", err)
+	}
+
+	cfg, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfile without a profile failed: %v", err)
+	}
+	if cfg.TMDB.Language != "en-US" || cfg.Scanner.Directories[0] != dir {
+		t.Errorf("base config = language %q, directories %v", cfg.TMDB.Language, cfg.Scanner.Directories)
+	}
+
+	cfg, err = LoadProfile(path, "kids")
+	if err != nil {
+		t.Fatalf("LoadProfile(kids) failed: %v", err)
+	}
+	if cfg.TMDB.Language != "it-IT" || cfg.TMDB.APIKey != "abc" {
+		t.Errorf("kids tmdb = language %q, api_key %q; want the override merged over the shared key", cfg.TMDB.Language, cfg.TMDB.APIKey)
+	}
+	if len(cfg.Scanner.Directories) != 1 || cfg.Scanner.Directories[0] != filepath.Join(dir, "kids") {
+		t.Errorf("kids directories = %v, want the profile list to replace the shared one", cfg.Scanner.Directories)
+	}
+	if cfg.Scanner.ConcurrentWorkers != 3 {
+		t.Errorf("kids concurrent_workers = %d, want the shared value 3", cfg.Scanner.ConcurrentWorkers)
+	}
+
+	cfg, err = LoadProfile(path, "family")
+	if err != nil {
+		t.Fatalf("LoadProfile(family) failed: %v", err)
+	}
+	if cfg.TMDB.APIKey != "xyz" {
+		t.Errorf("family api_key = %q, want the value from family.yaml", cfg.TMDB.APIKey)
+	}
+
+	if _, err := LoadProfile(path, "missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("LoadProfile(missing) error = %v, want ErrProfileNotFound", err)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables read by LoadFromEnv
+const EnvPrefix = "MOVIEVAULT_"
+
+// scanDirsEnv is a shorthand for MOVIEVAULT_SCANNER_DIRECTORIES
+const scanDirsEnv = EnvPrefix + "SCAN_DIRS"
+
+// LoadFromEnv builds the configuration from environment variables alone, for
+// deployments without a config file. Each YAML key maps to
+// MOVIEVAULT_<SECTION>_<KEY> in upper case, e.g. tmdb.api_key is
+// MOVIEVAULT_TMDB_API_KEY and output.mdx_dir is MOVIEVAULT_OUTPUT_MDX_DIR.
+// Lists are comma-separated and header maps use "Name=value" pairs separated by
+// commas. MOVIEVAULT_SCAN_DIRS is accepted for scanner.directories. Unset keys
+// keep the values from config.example.yaml.
+func LoadFromEnv() (*Config, error) {
+	cfg := envBaseConfig()
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), EnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if dirs, ok := os.LookupEnv(scanDirsEnv); ok && len(cfg.Scanner.Directories) == 0 {
+		cfg.Scanner.Directories = splitEnvList(dirs)
+	}
+	return finalize(&cfg)
+}
+
+// envBaseConfig returns the config.example.yaml values that differ from Go
+// zero values, so an environment-only setup behaves like the example file
+func envBaseConfig() Config {
+	var cfg Config
+	cfg.Scanner.Extensions = []string{".mp4", ".mkv", ".avi", ".mov", ".m4v", ".webm", ".flv", ".wmv"}
+	cfg.Output.MDXDir = "./website/src/content/movies"
+	cfg.Output.CoversDir = "./website/public/covers"
+	cfg.Output.WebsiteDir = "./website"
+	cfg.Options.RateLimitDelay = 250
+	cfg.Options.DownloadCovers = true
+	cfg.Options.DownloadBackdrops = true
+	cfg.Options.UseNFO = true
+	cfg.Options.NFOFallbackTMDB = true
+	return cfg
+}
+
+// applyEnv sets each field of the struct v from the environment variable named
+// prefix plus its upper-cased YAML key, recursing into nested sections
+func applyEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + strings.ToUpper(name)
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, key+"_", lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvField(field, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setEnvField parses value into field according to its type
+func setEnvField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setEnvField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", field.Type())
+		}
+		field.Set(reflect.ValueOf(splitEnvList(value)))
+	case reflect.Map:
+		headers := make(map[string]string)
+		for _, pair := range splitEnvList(value) {
+			name, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected Name=value, got %q", pair)
+			}
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(val)
+		}
+		field.Set(reflect.ValueOf(headers))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// splitEnvList splits a comma-separated value, trimming spaces and dropping
+// empty entries
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey is the top-level config key holding named profile overrides
+const profilesKey = "profiles"
+
+// ErrProfileNotFound is returned when --profile names neither an entry under
+// profiles: in the config file nor a <name>.yaml file next to it
+var ErrProfileNotFound = errors.New("config profile not found")
+
+// LoadProfile loads the configuration for a named profile. The profile is
+// looked up first under the profiles: key of the file at path, whose entries
+// are merged over the shared top-level settings, and then as a standalone
+// <profile>.yaml in the same directory. An empty profile behaves like Load.
+func LoadProfile(path, profile string) (*Config, error) {
+	resolved, err := ResolveProfile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+	if resolved != path {
+		return Load(resolved)
+	}
+	return load(path, profile)
+}
+
+// ResolveProfile returns the config file to read for profile: path itself when
+// it defines profiles.<profile> (or profile is empty), otherwise <profile>.yaml
+// next to it
+func ResolveProfile(path, profile string) (string, error) {
+	if profile == "" {
+		return path, nil
+	}
+	path = expandHome(path)
+
+	if data, err := os.ReadFile(path); err == nil {
+		expanded := []byte(os.ExpandEnv(string(data)))
+		if ok, err := hasProfile(expanded, profile); err != nil {
+			return "", fmt.Errorf("failed to parse config file: %w", err)
+		} else if ok {
+			return path, nil
+		}
+	}
+
+	sibling := filepath.Join(filepath.Dir(path), profile+".yaml")
+	if _, err := os.Stat(sibling); err == nil {
+		return sibling, nil
+	}
+	return "", fmt.Errorf("%w: no %s.%s in %s and no %s", ErrProfileNotFound, profilesKey, profile, path, sibling)
+}
+
+// ApplyProfile returns config YAML with the profiles: key removed and, when
+// profile is set, that profile's settings merged over the top-level ones.
+// Nested sections merge key by key; lists and scalars are replaced.
+func ApplyProfile(data []byte, profile string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	root := documentMapping(&doc)
+	if root == nil {
+		if profile != "" {
+			return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, profile)
+		}
+		return data, nil
+	}
+
+	profiles := removeMappingKey(root, profilesKey)
+	if profile != "" {
+		override := mappingValue(profiles, profile)
+		if override == nil {
+			return nil, fmt.Errorf("%w: %q is not defined under %s", ErrProfileNotFound, profile, profilesKey)
+		}
+		if override.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s.%s must be a mapping of config sections", profilesKey, profile)
+		}
+		mergeMapping(root, override)
+	} else if profiles == nil {
+		return data, nil
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+// hasProfile reports whether config YAML defines profiles.<profile>
+func hasProfile(data []byte, profile string) (bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	return mappingValue(mappingValue(documentMapping(&doc), profilesKey), profile) != nil, nil
+}
+
+// documentMapping returns the top-level mapping of a parsed document, if any
+func documentMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingValue returns the value for key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeMappingKey deletes key from a mapping node and returns its value, or nil
+func removeMappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// mergeMapping copies every key of override into base, recursing into
+// mappings present on both sides
+func mergeMapping(base, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		existing := mappingValue(base, key.Value)
+		switch {
+		case existing == nil:
+			base.Content = append(base.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMapping(existing, value)
+		default:
+			*existing = *value
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package diskspace guards file writes against filling up the output disk.
+package diskspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLowDiskSpace is returned by Check when the filesystem holding a path has
+// less free space than required
+var ErrLowDiskSpace = errors.New("not enough free disk space")
+
+// errUnsupported is returned by available on platforms without statfs
+var errUnsupported = errors.New("free space check not supported on this platform")
+
+// Check returns ErrLowDiskSpace when the filesystem holding path has less than
+// minMB megabytes available to unprivileged users. path may be a file that does
+// not exist yet; its nearest existing parent directory is checked. minMB <= 0
+// disables the check, and platforms without statfs always pass.
+func Check(path string, minMB int) error {
+	if minMB <= 0 {
+		return nil
+	}
+
+	dir := existingDir(path)
+	free, err := available(dir)
+	if err != nil {
+		if errors.Is(err, errUnsupported) {
+			return nil
+		}
+		return fmt.Errorf("failed to check free space on %s: %w", dir, err)
+	}
+
+	if free < uint64(minMB)*1024*1024 {
+		return fmt.Errorf("%w on %s: %d MB free, minimum is %d MB", ErrLowDiskSpace, dir, free/(1024*1024), minMB)
+	}
+	return nil
+}
+
+// existingDir returns path if it is a directory, otherwise its closest
+// existing ancestor, so a check can run before output directories are created
+func existingDir(path string) string {
+	dir := path
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,31 @@
+package diskspace
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "covers", "th", "the-matrix-1999.jpg")
+
+	if err := Check(missing, 0); err != nil {
+		t.Errorf("Check(min 0) = %v, want nil", err)
+	}
+	if err := Check(missing, 1); err != nil {
+		t.Errorf("Check(min 1 MB) = %v, want nil", err)
+	}
+
+	err := Check(missing, 1<<30) // 1 PB
+	if _, statErr := available(dir); errors.Is(statErr, errUnsupported) {
+		if err != nil {
+			t.Errorf("Check on %s = %v, want nil when unsupported", runtime.GOOS, err)
+		}
+		return
+	}
+	if !errors.Is(err, ErrLowDiskSpace) {
+		t.Errorf("Check(min 1 PB) = %v, want ErrLowDiskSpace", err)
+	}
+}
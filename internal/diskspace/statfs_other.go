@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd)
+
+package diskspace
+
+// available has no statfs to call on this platform, so the check is skipped
+func available(dir string) (uint64, error) {
+	return 0, errUnsupported
+}
@@ -2,23 +2,73 @@ package nfo
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/marco/movieVault/internal/writer"
 )
 
+var (
+	// ErrNFONotFound is returned when no .nfo file exists for a video file.
+	// This is the normal case for libraries without Jellyfin/Kodi metadata.
+	ErrNFONotFound = errors.New("no .nfo file found")
+
+	// ErrNFOParse is returned when an .nfo file exists but cannot be parsed.
+	// The underlying XML error is wrapped alongside it.
+	ErrNFOParse = errors.New("failed to parse .nfo XML")
+)
+
+// Poster preference tokens understood by ParserOptions.PosterPreference.
+// Any other token is treated as a language code matched against <thumb language="...">.
+const (
+	PosterPreferenceFirst   = "first"
+	PosterPreferenceLargest = "largest"
+)
+
+// runtimeSecondsThreshold separates minutes from seconds in plain numeric
+// runtimes: no feature runs this many minutes, but many run this many seconds
+const runtimeSecondsThreshold = 1000
+
+// tmdbSizePattern extracts the width segment from TMDB-style image URLs (e.g. /w500/)
+var tmdbSizePattern = regexp.MustCompile(`/w(\d+)/`)
+
 // Parser handles parsing of .nfo files
-type Parser struct{}
+type Parser struct {
+	posterPreference []string
+	ratingSource     string
+}
+
+// ParserOptions holds optional NFO parser behavior
+type ParserOptions struct {
+	// PosterPreference is an ordered list of poster selection rules: "largest",
+	// "first", or a language code. The first rule that selects a thumb wins.
+	// Empty means the default: first "poster" aspect thumb, then first thumb.
+	PosterPreference []string
+	// RatingSource selects the <ratings> entry to use by name (e.g. "imdb",
+	// "themoviedb"). Empty means the entry marked default="true".
+	RatingSource string
+}
 
 // NewParser creates a new NFO parser instance
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// NewParserWithOptions creates a new NFO parser with custom selection options
+func NewParserWithOptions(opts ParserOptions) *Parser {
+	return &Parser{
+		posterPreference: opts.PosterPreference,
+		ratingSource:     opts.RatingSource,
+	}
+}
+
 // FindNFOFile locates the .nfo file for a given video file.
 // Priority order:
 // 1. movie.nfo (Jellyfin/Kodi shared metadata — preferred for multi-part movies)
@@ -41,7 +91,7 @@ func (p *Parser) FindNFOFile(videoPath string) (string, error) {
 		return fileNameNFO, nil
 	}
 
-	return "", fmt.Errorf("no .nfo file found for %s", videoPath)
+	return "", fmt.Errorf("%w for %s", ErrNFONotFound, videoPath)
 }
 
 // ParseNFOFile reads and parses an .nfo XML file
@@ -53,24 +103,107 @@ func (p *Parser) ParseNFOFile(nfoPath string) (*NFOMovie, error) {
 
 	var nfo NFOMovie
 	if err := xml.Unmarshal(data, &nfo); err != nil {
-		return nil, fmt.Errorf("failed to parse .nfo XML: %w", err)
+		return nil, fmt.Errorf("%w %s: %w", ErrNFOParse, nfoPath, err)
 	}
 
 	return &nfo, nil
 }
 
+// selectRating returns the NFO rating on a 0-10 scale. From a <ratings> block
+// it prefers the configured source, then the default="true" entry, then the
+// first entry; without one it falls back to the flat <rating> element.
+func (p *Parser) selectRating(nfo *NFOMovie) float64 {
+	if nfo.Ratings == nil || len(nfo.Ratings.Ratings) == 0 {
+		return nfo.Rating
+	}
+	ratings := nfo.Ratings.Ratings
+
+	selected := &ratings[0]
+	for i := range ratings {
+		if ratings[i].Default {
+			selected = &ratings[i]
+			break
+		}
+	}
+	if p.ratingSource != "" {
+		for i := range ratings {
+			if strings.EqualFold(ratings[i].Name, p.ratingSource) {
+				selected = &ratings[i]
+				break
+			}
+		}
+	}
+
+	// Scale ratings published on another range (e.g. max="100") to 0-10
+	if selected.Max > 0 && selected.Max != 10 {
+		return selected.Value * 10 / selected.Max
+	}
+	return selected.Value
+}
+
+// parseRuntime converts an NFO <runtime> to minutes. Generators disagree on
+// the format, so it accepts plain minutes ("115", "115 min"), seconds
+// ("6900", recognized by being implausibly large for minutes), and clock
+// strings ("01:55" or "01:55:00"). Unparseable values return 0 (unknown).
+func parseRuntime(raw string) int {
+	s := strings.TrimSpace(strings.ToLower(raw))
+	if s == "" {
+		return 0
+	}
+
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0
+		}
+		var hours, minutes, seconds int
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0
+			}
+			switch i {
+			case 0:
+				hours = n
+			case 1:
+				minutes = n
+			case 2:
+				seconds = n
+			}
+		}
+		return int(math.Round(float64(hours*3600+minutes*60+seconds) / 60))
+	}
+
+	for _, suffix := range []string{"minutes", "mins", "min"} {
+		if strings.HasSuffix(s, suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, suffix))
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0
+	}
+	if value > runtimeSecondsThreshold {
+		value /= 60
+	}
+	return int(math.Round(value))
+}
+
 // ConvertToMovie transforms NFO data to writer.Movie struct
 func (p *Parser) ConvertToMovie(nfo *NFOMovie) *writer.Movie {
 	movie := &writer.Movie{
 		Title:       nfo.Title,
 		Description: nfo.Plot,
-		Rating:      nfo.Rating,
+		Rating:      writer.NormalizeRating(p.selectRating(nfo)),
 		ReleaseYear: nfo.Year,
-		Runtime:     nfo.Runtime,
+		Runtime:     writer.NormalizeRuntime(parseRuntime(nfo.Runtime)),
 		Genres:      nfo.Genres,
 		TMDBID:      nfo.TMDBID,
 		IMDbID:      nfo.IMDbID,
 		ScannedAt:   time.Now(),
+		Locked:      nfo.LockData,
+		Collection:  nfo.SetName(),
 	}
 
 	// Parse year from premiered date if year is missing
@@ -102,7 +235,7 @@ func (p *Parser) ConvertToMovie(nfo *NFOMovie) *writer.Movie {
 
 	// Extract poster URL from <thumb> elements (US-018)
 	// Look for poster aspect or use first thumb as poster
-	movie.PosterURL = extractPosterURL(nfo.Thumbs)
+	movie.PosterURL = selectPosterURL(nfo.Thumbs, p.posterPreference)
 
 	// Extract backdrop URL from <fanart><thumb> elements (US-018)
 	movie.BackdropURL = extractBackdropURL(nfo.Fanart)
@@ -127,21 +260,77 @@ func extractPosterURL(thumbs []NFOThumb) string {
 
 	// First look for explicit poster aspect
 	for _, thumb := range thumbs {
-		if strings.EqualFold(thumb.Aspect, "poster") && thumb.URL != "" {
-			return strings.TrimSpace(thumb.URL)
+		if url := strings.TrimSpace(thumb.URL); strings.EqualFold(thumb.Aspect, "poster") && url != "" {
+			return url
 		}
 	}
 
 	// Fall back to first thumb with a URL
 	for _, thumb := range thumbs {
-		if thumb.URL != "" {
-			return strings.TrimSpace(thumb.URL)
+		if url := strings.TrimSpace(thumb.URL); url != "" {
+			return url
 		}
 	}
 
 	return ""
 }
 
+// selectPosterURL applies the configured poster preference rules in order,
+// falling back to extractPosterURL when no rule selects a thumb
+func selectPosterURL(thumbs []NFOThumb, preferences []string) string {
+	var posters []NFOThumb
+	for _, thumb := range thumbs {
+		if strings.EqualFold(thumb.Aspect, "poster") && strings.TrimSpace(thumb.URL) != "" {
+			posters = append(posters, thumb)
+		}
+	}
+
+	for _, pref := range preferences {
+		pref = strings.ToLower(strings.TrimSpace(pref))
+		switch pref {
+		case "", PosterPreferenceFirst:
+			return extractPosterURL(thumbs)
+		case PosterPreferenceLargest:
+			bestIdx, bestSize := -1, 0
+			for i, thumb := range posters {
+				if size := estimateThumbSize(thumb); size > bestSize {
+					bestIdx, bestSize = i, size
+				}
+			}
+			if bestIdx >= 0 {
+				return strings.TrimSpace(posters[bestIdx].URL)
+			}
+		default:
+			for _, thumb := range posters {
+				if strings.EqualFold(thumb.Language, pref) {
+					return strings.TrimSpace(thumb.URL)
+				}
+			}
+		}
+	}
+
+	return extractPosterURL(thumbs)
+}
+
+// estimateThumbSize returns a relative size rank for a thumb, or 0 if unknown.
+// TMDB-style "/original/" URLs rank highest, "/wNNN/" URLs rank by width, and a
+// thumb with a preview attribute is assumed to point at a full-size image.
+func estimateThumbSize(thumb NFOThumb) int {
+	url := strings.TrimSpace(thumb.URL)
+	if strings.Contains(url, "/original/") {
+		return 100000
+	}
+	if match := tmdbSizePattern.FindStringSubmatch(url); len(match) > 1 {
+		if width, err := strconv.Atoi(match[1]); err == nil {
+			return width
+		}
+	}
+	if thumb.Preview != "" {
+		return 1
+	}
+	return 0
+}
+
 // extractBackdropURL finds the best backdrop URL from NFO fanart elements
 // Returns first backdrop/fanart thumb URL found
 func extractBackdropURL(fanart *NFOFanart) string {
@@ -151,8 +340,8 @@ func extractBackdropURL(fanart *NFOFanart) string {
 
 	// Return first fanart thumb with a URL
 	for _, thumb := range fanart.Thumbs {
-		if thumb.URL != "" {
-			return strings.TrimSpace(thumb.URL)
+		if url := strings.TrimSpace(thumb.URL); url != "" {
+			return url
 		}
 	}
 
@@ -172,5 +361,19 @@ func (p *Parser) GetMovieFromNFO(videoPath string) (*writer.Movie, error) {
 	}
 
 	movie := p.ConvertToMovie(nfo)
+
+	// Relative image paths (e.g. <art><poster>poster.jpg</poster>) point next to the .nfo
+	nfoDir := filepath.Dir(nfoPath)
+	movie.PosterURL = resolveImagePath(nfoDir, movie.PosterURL)
+	movie.BackdropURL = resolveImagePath(nfoDir, movie.BackdropURL)
 	return movie, nil
 }
+
+// resolveImagePath joins a relative local image path onto dir. URLs and
+// absolute paths are returned unchanged.
+func resolveImagePath(dir, image string) string {
+	if image == "" || strings.Contains(image, "://") || filepath.IsAbs(image) {
+		return image
+	}
+	return filepath.Join(dir, image)
+}
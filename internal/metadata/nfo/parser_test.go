@@ -0,0 +1,228 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectPosterURL(t *testing.T) {
+	thumbs := []NFOThumb{
+		{Aspect: "landscape", URL: "https://image.tmdb.org/t/p/original/landscape.jpg"},
+		{Aspect: "poster", Language: "en", URL: "https://image.tmdb.org/t/p/w500/en.jpg"},
+		{Aspect: "poster", Language: "it", URL: "https://image.tmdb.org/t/p/w780/it.jpg"},
+		{Aspect: "poster", Preview: "https://image.tmdb.org/t/p/w500/full.jpg", URL: "https://image.tmdb.org/t/p/original/full.jpg"},
+	}
+
+	testCases := []struct {
+		name        string
+		preferences []string
+		expected    string
+	}{
+		{"default keeps first poster", nil, "https://image.tmdb.org/t/p/w500/en.jpg"},
+		{"first", []string{"first"}, "https://image.tmdb.org/t/p/w500/en.jpg"},
+		{"largest", []string{"largest"}, "https://image.tmdb.org/t/p/original/full.jpg"},
+		{"language match", []string{"it"}, "https://image.tmdb.org/t/p/w780/it.jpg"},
+		{"language case-insensitive", []string{"IT"}, "https://image.tmdb.org/t/p/w780/it.jpg"},
+		{"unmatched language falls through", []string{"de", "largest"}, "https://image.tmdb.org/t/p/original/full.jpg"},
+		{"unmatched rules fall back to default", []string{"de"}, "https://image.tmdb.org/t/p/w500/en.jpg"},
+	}
+
+	for _, tc := range testCases {
+		got := selectPosterURL(thumbs, tc.preferences)
+		if got != tc.expected {
+			t.Errorf("%s: selectPosterURL(%v) = %q, want %q", tc.name, tc.preferences, got, tc.expected)
+		}
+	}
+}
+
+func TestConvertToMovie_NormalizesRatingAndRuntime(t *testing.T) {
+	testCases := []struct {
+		rating          float64
+		runtime         string
+		expectedRating  float64
+		expectedRuntime int
+	}{
+		{7.1999998, "148", 7.2, 148},
+		{8.25, "0", 8.3, 0},
+		{6.04, "100000", 6.0, 0},
+		{0, "-5", 0, 0},
+	}
+
+	parser := NewParser()
+	for _, tc := range testCases {
+		movie := parser.ConvertToMovie(&NFOMovie{Title: "Test", Rating: tc.rating, Runtime: tc.runtime})
+		if movie.Rating != tc.expectedRating {
+			t.Errorf("rating %v normalized to %v, want %v", tc.rating, movie.Rating, tc.expectedRating)
+		}
+		if movie.Runtime != tc.expectedRuntime {
+			t.Errorf("runtime %q normalized to %d, want %d", tc.runtime, movie.Runtime, tc.expectedRuntime)
+		}
+	}
+}
+
+func TestParseRuntime(t *testing.T) {
+	testCases := []struct {
+		raw  string
+		want int
+	}{
+		{"", 0},
+		{"115", 115},
+		{" 115 ", 115},
+		{"115 min", 115},
+		{"115mins", 115},
+		{"114.6", 115},
+		{"6900", 115},
+		{"1440", 24},
+		{"01:55", 115},
+		{"1:55:00", 115},
+		{"01:54:40", 115},
+		{"0:45:10", 45},
+		{"1:2:3:4", 0},
+		{"1:xx", 0},
+		{"-20", 0},
+		{"unknown", 0},
+	}
+
+	for _, tc := range testCases {
+		if got := parseRuntime(tc.raw); got != tc.want {
+			t.Errorf("parseRuntime(%q) = %d, want %d", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestConvertToMovie_RatingFormats(t *testing.T) {
+	nested := `<movie>
+  <title>The Matrix</title>
+  <ratings>
+    <rating name="themoviedb" max="10"><value>8.2</value><votes>24000</votes></rating>
+    <rating name="imdb" max="10" default="true"><value>8.7</value><votes>2,000,000</votes></rating>
+    <rating name="metacritic" max="100"><value>73</value></rating>
+  </ratings>
+</movie>`
+
+	testCases := []struct {
+		name     string
+		xml      string
+		source   string
+		expected float64
+	}{
+		{"flat rating", `<movie><title>Heat</title><rating>8.3</rating></movie>`, "", 8.3},
+		{"flat rating ignores source", `<movie><title>Heat</title><rating>8.3</rating></movie>`, "imdb", 8.3},
+		{"nested default entry", nested, "", 8.7},
+		{"nested configured source", nested, "themoviedb", 8.2},
+		{"nested source is case-insensitive", nested, "TheMovieDB", 8.2},
+		{"nested 100-point scale", nested, "metacritic", 7.3},
+		{"nested unknown source uses default", nested, "rottentomatoes", 8.7},
+		{"nested without default uses first", `<movie><ratings><rating name="tmdb"><value>6.5</value></rating><rating name="imdb"><value>7</value></rating></ratings></movie>`, "", 6.5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var nfo NFOMovie
+			if err := xml.Unmarshal([]byte(tc.xml), &nfo); err != nil {
+				t.Fatalf("failed to parse NFO: %v", err)
+			}
+			movie := NewParserWithOptions(ParserOptions{RatingSource: tc.source}).ConvertToMovie(&nfo)
+			if movie.Rating != tc.expected {
+				t.Errorf("rating = %v, want %v", movie.Rating, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConvertToMovie_LockData(t *testing.T) {
+	testCases := []struct {
+		xml    string
+		locked bool
+	}{
+		{`<movie><title>Heat</title><lockdata>true</lockdata></movie>`, true},
+		{`<movie><title>Heat</title><lockdata>false</lockdata></movie>`, false},
+		{`<movie><title>Heat</title></movie>`, false},
+	}
+
+	for _, tc := range testCases {
+		var nfo NFOMovie
+		if err := xml.Unmarshal([]byte(tc.xml), &nfo); err != nil {
+			t.Fatalf("failed to parse NFO: %v", err)
+		}
+		if movie := NewParser().ConvertToMovie(&nfo); movie.Locked != tc.locked {
+			t.Errorf("%s: Locked = %v, want %v", tc.xml, movie.Locked, tc.locked)
+		}
+	}
+}
+
+func TestConvertToMovie_Set(t *testing.T) {
+	testCases := []struct {
+		xml        string
+		collection string
+	}{
+		{`<movie><title>Aliens</title><set><name>Alien Collection</name><overview>Xenomorphs.</overview></set></movie>`, "Alien Collection"},
+		{`<movie><title>Aliens</title><set> Alien Collection </set></movie>`, "Alien Collection"},
+		{`<movie><title>Aliens</title><set></set></movie>`, ""},
+		{`<movie><title>Heat</title></movie>`, ""},
+	}
+
+	for _, tc := range testCases {
+		var nfo NFOMovie
+		if err := xml.Unmarshal([]byte(tc.xml), &nfo); err != nil {
+			t.Fatalf("failed to parse NFO: %v", err)
+		}
+		if movie := NewParser().ConvertToMovie(&nfo); movie.Collection != tc.collection {
+			t.Errorf("%s: Collection = %q, want %q", tc.xml, movie.Collection, tc.collection)
+		}
+	}
+}
+
+func TestGetMovieFromNFO_ArtOnly(t *testing.T) {
+	testCases := []struct {
+		name             string
+		art              string
+		expectedPoster   string
+		expectedBackdrop string
+		relative         bool // expected paths are relative to the .nfo directory
+	}{
+		{
+			"urls",
+			"<poster>https://image.tmdb.org/t/p/original/poster.jpg</poster><fanart>https://image.tmdb.org/t/p/original/fanart.jpg</fanart>",
+			"https://image.tmdb.org/t/p/original/poster.jpg",
+			"https://image.tmdb.org/t/p/original/fanart.jpg",
+			false,
+		},
+		{
+			"absolute paths",
+			"<poster>/config/metadata/poster.jpg</poster><fanart>\n  /config/metadata/fanart.jpg\n</fanart>",
+			"/config/metadata/poster.jpg",
+			"/config/metadata/fanart.jpg",
+			false,
+		},
+		{"relative paths", "<poster>poster.jpg</poster><fanart>extrafanart/fanart1.jpg</fanart>", "poster.jpg", "extrafanart/fanart1.jpg", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data := "<movie><title>Heat</title><thumb aspect=\"poster\">  </thumb><fanart></fanart><art>" + tc.art + "</art></movie>"
+			if err := os.WriteFile(filepath.Join(dir, "movie.nfo"), []byte(data), 0644); err != nil {
+				t.Fatalf("failed to write nfo: %v", err)
+			}
+
+			movie, err := NewParser().GetMovieFromNFO(filepath.Join(dir, "Heat.1995.mkv"))
+			if err != nil {
+				t.Fatalf("GetMovieFromNFO failed: %v", err)
+			}
+			expectedPoster, expectedBackdrop := tc.expectedPoster, tc.expectedBackdrop
+			if tc.relative {
+				expectedPoster = filepath.Join(dir, expectedPoster)
+				expectedBackdrop = filepath.Join(dir, expectedBackdrop)
+			}
+			if movie.PosterURL != expectedPoster {
+				t.Errorf("PosterURL = %q, want %q", movie.PosterURL, expectedPoster)
+			}
+			if movie.BackdropURL != expectedBackdrop {
+				t.Errorf("BackdropURL = %q, want %q", movie.BackdropURL, expectedBackdrop)
+			}
+		})
+	}
+}
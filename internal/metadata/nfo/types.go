@@ -1,6 +1,9 @@
 package nfo
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strings"
+)
 
 // NFOMovie represents the structure of a Jellyfin .nfo XML file
 type NFOMovie struct {
@@ -8,9 +11,10 @@ type NFOMovie struct {
 	Title     string      `xml:"title"`
 	Plot      string      `xml:"plot"`
 	Rating    float64     `xml:"rating"`
+	Ratings   *NFORatings `xml:"ratings"` // Kodi multi-source ratings; preferred over Rating when present
 	Year      int         `xml:"year"`
 	Premiered string      `xml:"premiered"`
-	Runtime   int         `xml:"runtime"`
+	Runtime   string      `xml:"runtime"` // Minutes, seconds, or HH:MM[:SS]; see parseRuntime
 	Genres    []string    `xml:"genre"`
 	Directors []string    `xml:"director"`
 	Actors    []NFOActor  `xml:"actor"`
@@ -19,6 +23,26 @@ type NFOMovie struct {
 	Thumbs    []NFOThumb  `xml:"thumb"`
 	Fanart    *NFOFanart  `xml:"fanart"`
 	Art       *NFOArt     `xml:"art"`
+	LockData  bool        `xml:"lockdata"` // Jellyfin: metadata is user-curated and must not be refreshed
+	Set       *NFOSet     `xml:"set"`
+}
+
+// NFOSet represents the collection a movie belongs to. Kodi writes
+// <set><name>Alien Collection</name></set>; older files use <set>Alien Collection</set>.
+type NFOSet struct {
+	Name  string `xml:"name"`
+	Value string `xml:",chardata"`
+}
+
+// SetName returns the collection name from either <set> form, or "" when absent
+func (n *NFOMovie) SetName() string {
+	if n.Set == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(n.Set.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(n.Set.Value)
 }
 
 // NFOActor represents an actor in the .nfo file
@@ -30,8 +54,10 @@ type NFOActor struct {
 
 // NFOThumb represents a thumbnail/poster image
 type NFOThumb struct {
-	Aspect  string `xml:"aspect,attr"`
-	URL     string `xml:",chardata"`
+	Aspect   string `xml:"aspect,attr"`
+	Preview  string `xml:"preview,attr"`  // Smaller preview URL, present when URL is full size
+	Language string `xml:"language,attr"` // Poster language code (e.g. "en"), when provided
+	URL      string `xml:",chardata"`
 }
 
 // NFOFanart represents fanart/backdrop images
@@ -44,3 +70,19 @@ type NFOArt struct {
 	Poster string `xml:"poster"`
 	Fanart string `xml:"fanart"`
 }
+
+// NFORatings represents the Kodi <ratings> block holding one rating per source
+type NFORatings struct {
+	Ratings []NFORating `xml:"rating"`
+}
+
+// NFORating represents a single source rating, e.g.
+// <rating name="imdb" max="10" default="true"><value>8.5</value></rating>.
+// <votes> is not read: Kodi and MediaElch write it with thousands separators
+// ("1,234"), which would fail to parse as a number.
+type NFORating struct {
+	Name    string  `xml:"name,attr"`
+	Max     float64 `xml:"max,attr"`
+	Default bool    `xml:"default,attr"`
+	Value   float64 `xml:"value"`
+}
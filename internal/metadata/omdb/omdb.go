@@ -0,0 +1,175 @@
+// Package omdb provides an optional OMDb API client for IMDb and Rotten Tomatoes ratings.
+package omdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marco/movieVault/internal/metadata/cache"
+	"github.com/marco/movieVault/internal/retry"
+)
+
+const omdbAPIBaseURL = "https://www.omdbapi.com/"
+
+// Ratings holds the external ratings returned by OMDb.
+// Zero values mean the rating was not available.
+type Ratings struct {
+	IMDbRating     float64 `json:"imdb_rating"`     // IMDb user rating (0-10)
+	RottenTomatoes int     `json:"rotten_tomatoes"` // Tomatometer score (0-100)
+}
+
+// omdbResponse is the subset of the OMDb title response we use
+type omdbResponse struct {
+	IMDbRating string       `json:"imdbRating"`
+	Ratings    []omdbRating `json:"Ratings"`
+	Response   string       `json:"Response"`
+	Error      string       `json:"Error"`
+}
+
+// omdbRating is a single entry in the OMDb Ratings array
+type omdbRating struct {
+	Source string `json:"Source"`
+	Value  string `json:"Value"`
+}
+
+// Client represents an OMDb API client
+type Client struct {
+	apiKey         string
+	baseURL        string // omdbAPIBaseURL, replaced by tests
+	httpClient     *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	cache          cache.Cache
+	cacheTTL       time.Duration
+}
+
+// ClientConfig holds configuration for the OMDb client
+type ClientConfig struct {
+	APIKey           string
+	MaxAttempts      int
+	InitialBackoffMs int
+	Cache            cache.Cache
+	CacheTTLDays     int
+}
+
+// NewClient creates a new OMDb API client
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.InitialBackoffMs <= 0 {
+		cfg.InitialBackoffMs = 1000
+	}
+	if cfg.CacheTTLDays <= 0 {
+		cfg.CacheTTLDays = 30
+	}
+
+	return &Client{
+		apiKey:         cfg.APIKey,
+		baseURL:        omdbAPIBaseURL,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		cache:          cfg.Cache,
+		cacheTTL:       time.Duration(cfg.CacheTTLDays) * 24 * time.Hour,
+	}
+}
+
+// GetRatings fetches IMDb and Rotten Tomatoes ratings for an IMDb ID (e.g. "tt0133093")
+func (c *Client) GetRatings(imdbID string) (*Ratings, error) {
+	if imdbID == "" {
+		return nil, fmt.Errorf("imdb id is empty")
+	}
+
+	// Check cache first
+	cacheKey := fmt.Sprintf("omdb:ratings:%s", imdbID)
+	if c.cache != nil {
+		if cachedData, found := c.cache.Get(cacheKey); found {
+			var cachedResult Ratings
+			if err := json.Unmarshal(cachedData, &cachedResult); err == nil {
+				return &cachedResult, nil
+			}
+		}
+	}
+
+	params := url.Values{}
+	params.Set("apikey", c.apiKey)
+	params.Set("i", imdbID)
+	requestURL := c.baseURL + "?" + params.Encode()
+
+	var body []byte
+	err := retry.Retry(func() error {
+		resp, err := c.httpClient.Get(requestURL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("OMDb API error (status %d): %s", resp.StatusCode, string(data))
+		}
+		body = data
+		return nil
+	}, c.maxAttempts, c.initialBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ratings: %w", err)
+	}
+
+	var omdbResp omdbResponse
+	if err := json.Unmarshal(body, &omdbResp); err != nil {
+		return nil, fmt.Errorf("failed to decode OMDb response: %w", err)
+	}
+	if omdbResp.Response == "False" {
+		return nil, fmt.Errorf("OMDb API error: %s", omdbResp.Error)
+	}
+
+	ratings := parseRatings(&omdbResp)
+
+	// Cache the result
+	if c.cache != nil {
+		if resultData, err := json.Marshal(ratings); err == nil {
+			_ = c.cache.Set(cacheKey, resultData, c.cacheTTL)
+		}
+	}
+
+	return ratings, nil
+}
+
+// parseRatings extracts numeric ratings from an OMDb response.
+// OMDb uses "N/A" for missing values, which parse to zero.
+func parseRatings(resp *omdbResponse) *Ratings {
+	ratings := &Ratings{}
+
+	if rating, err := strconv.ParseFloat(resp.IMDbRating, 64); err == nil {
+		ratings.IMDbRating = rating
+	}
+
+	for _, r := range resp.Ratings {
+		switch r.Source {
+		case "Internet Movie Database":
+			if ratings.IMDbRating == 0 {
+				// Value format: "8.7/10"
+				if rating, err := strconv.ParseFloat(strings.TrimSuffix(r.Value, "/10"), 64); err == nil {
+					ratings.IMDbRating = rating
+				}
+			}
+		case "Rotten Tomatoes":
+			// Value format: "88%"
+			if score, err := strconv.Atoi(strings.TrimSuffix(r.Value, "%")); err == nil {
+				ratings.RottenTomatoes = score
+			}
+		}
+	}
+
+	return ratings
+}
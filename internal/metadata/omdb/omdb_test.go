@@ -0,0 +1,118 @@
+package omdb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco/movieVault/internal/metadata/cache"
+)
+
+func TestParseRatings(t *testing.T) {
+	testCases := []struct {
+		name string
+		resp omdbResponse
+		want Ratings
+	}{
+		{"both ratings", omdbResponse{
+			IMDbRating: "8.7",
+			Ratings: []omdbRating{
+				{Source: "Internet Movie Database", Value: "8.7/10"},
+				{Source: "Rotten Tomatoes", Value: "88%"},
+				{Source: "Metacritic", Value: "73/100"},
+			},
+		}, Ratings{IMDbRating: 8.7, RottenTomatoes: 88}},
+		{"imdb from the ratings array", omdbResponse{
+			IMDbRating: "N/A",
+			Ratings:    []omdbRating{{Source: "Internet Movie Database", Value: "7.9/10"}},
+		}, Ratings{IMDbRating: 7.9}},
+		{"everything N/A", omdbResponse{
+			IMDbRating: "N/A",
+			Ratings:    []omdbRating{{Source: "Rotten Tomatoes", Value: "N/A"}},
+		}, Ratings{}},
+		{"no ratings", omdbResponse{}, Ratings{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseRatings(&tc.resp); *got != tc.want {
+				t.Errorf("parseRatings() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetRatings(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("apikey") != "key" {
+			t.Errorf("apikey = %q, want key", r.URL.Query().Get("apikey"))
+		}
+		switch r.URL.Query().Get("i") {
+		case "tt0133093":
+			w.Write([]byte(`{"Title":"The Matrix","imdbRating":"8.7","Ratings":[{"Source":"Rotten Tomatoes","Value":"83%"}],"Response":"True"}`))
+		case "tt0000001":
+			w.Write([]byte(`{"Title":"Carmencita","imdbRating":"N/A","Ratings":[],"Response":"True"}`))
+		case "tt9999999":
+			w.Write([]byte(`{"Response":"False","Error":"Incorrect IMDb ID."}`))
+		case "tt5000000":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"Response":"False","Error":"Invalid API key!"}`))
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	client := NewClient(ClientConfig{APIKey: "key", MaxAttempts: 1, Cache: db})
+	client.baseURL = server.URL
+
+	testCases := []struct {
+		imdbID  string
+		want    Ratings
+		wantErr string
+	}{
+		{"tt0133093", Ratings{IMDbRating: 8.7, RottenTomatoes: 83}, ""},
+		{"tt0000001", Ratings{}, ""},
+		{"tt9999999", Ratings{}, "Incorrect IMDb ID."},
+		{"tt5000000", Ratings{}, "status 401"},
+		{"tt1234567", Ratings{}, "failed to decode"},
+		{"", Ratings{}, "imdb id is empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.imdbID, func(t *testing.T) {
+			ratings, err := client.GetRatings(tc.imdbID)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("GetRatings(%q) error = %v, want %q", tc.imdbID, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRatings(%q) failed: %v", tc.imdbID, err)
+			}
+			if *ratings != tc.want {
+				t.Errorf("GetRatings(%q) = %+v, want %+v", tc.imdbID, *ratings, tc.want)
+			}
+		})
+	}
+
+	// Successful lookups are served from the cache
+	before := requests
+	if ratings, err := client.GetRatings("tt0133093"); err != nil || ratings.IMDbRating != 8.7 {
+		t.Errorf("cached GetRatings = %+v, %v", ratings, err)
+	}
+	if requests != before {
+		t.Errorf("cached lookup made %d requests", requests-before)
+	}
+}
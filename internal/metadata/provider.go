@@ -0,0 +1,26 @@
+package metadata
+
+import "github.com/marco/movieVault/internal/writer"
+
+// MovieProvider is the set of metadata lookups and image downloads the scan
+// pipeline depends on. *Client implements it against the TMDB API; tests can
+// substitute a fake to exercise the pipeline without network access.
+type MovieProvider interface {
+	// SearchMovie returns the best TMDB search result for title and optional year
+	SearchMovie(title string, year int) (*TMDBMovie, error)
+	// GetMovieDetails fetches detailed information about a movie
+	GetMovieDetails(tmdbID int) (*TMDBMovieDetails, error)
+	// GetMovieCredits fetches cast and crew for a movie
+	GetMovieCredits(tmdbID int) (*TMDBCreditsResponse, error)
+	// GetFullMovieData searches for a movie and returns complete metadata
+	GetFullMovieData(title string, year int) (*writer.Movie, error)
+	// GetMovieByID returns complete metadata for a known TMDB ID
+	GetMovieByID(tmdbID int) (*writer.Movie, error)
+	// DownloadImage downloads a TMDB image path ("poster" or "backdrop") to outputPath
+	DownloadImage(imagePath string, outputPath string, imageType string) error
+	// DownloadImageFromURL downloads an image URL, or copies a local path, to outputPath
+	DownloadImageFromURL(imageURL string, outputPath string) error
+}
+
+// Client must satisfy MovieProvider
+var _ MovieProvider = (*Client)(nil)
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minThrottleBurst is the smallest burst allowed, so very low rates still make
+// progress in reasonably sized reads
+const minThrottleBurst = 1024
+
+// byteRateLimiter is a token bucket measured in bytes. It is shared by all
+// image downloads of a client, so the limit applies to their combined
+// bandwidth rather than to each download separately.
+type byteRateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Bytes per second
+	burst  float64 // Bucket capacity in bytes
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// newByteRateLimiter returns a limiter for kbps kilobits per second, or nil
+// when kbps is 0 or negative (unlimited)
+func newByteRateLimiter(kbps int) *byteRateLimiter {
+	if kbps <= 0 {
+		return nil
+	}
+	rate := float64(kbps) * 1000 / 8
+	burst := rate
+	if burst < minThrottleBurst {
+		burst = minThrottleBurst
+	}
+	return &byteRateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// take consumes n bytes from the bucket, sleeping until they have been earned.
+// Callers that overdraw the bucket wait for the debt to be repaid, so concurrent
+// readers are paced fairly without holding the lock while sleeping.
+func (l *byteRateLimiter) take(n int) {
+	l.mu.Lock()
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		l.sleep(wait)
+	}
+}
+
+// throttledReader paces reads from r through a byteRateLimiter
+type throttledReader struct {
+	r       io.Reader
+	limiter *byteRateLimiter
+}
+
+// Read reads at most one burst at a time and waits for the bytes it returns
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > int(t.limiter.burst) {
+		p = p[:int(t.limiter.burst)]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.limiter.take(n)
+	}
+	return n, err
+}
+
+// idleTimeoutReader closes body when a single Read waits longer than timeout
+// for data. Unlike http.Client.Timeout it only measures time spent waiting on
+// the server, so throttle sleeps between reads never count against it.
+type idleTimeoutReader struct {
+	body    io.ReadCloser
+	timeout time.Duration
+}
+
+// Read reads from body, closing it if no data arrives within the timeout
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(r.timeout, func() {
+		timedOut.Store(true)
+		r.body.Close()
+	})
+	n, err := r.body.Read(p)
+	timer.Stop()
+	if timedOut.Load() {
+		return n, fmt.Errorf("image download stalled for %s", r.timeout)
+	}
+	return n, err
+}
+
+// throttleImage wraps an image download body with the client's bandwidth
+// limit, if one is configured. Throttled downloads can legitimately take
+// longer than the image timeout when several share the limit, so the image
+// client has no overall deadline then and the timeout applies to each wait
+// for data instead.
+func (c *Client) throttleImage(body io.ReadCloser) io.Reader {
+	if c.imageLimiter == nil {
+		return body
+	}
+	return &throttledReader{
+		r:       &idleTimeoutReader{body: body, timeout: c.imageTimeout},
+		limiter: c.imageLimiter,
+	}
+}
+
+// newImageHTTPClient returns the client used for image downloads. Without a
+// bandwidth limit, timeout bounds the whole download. With one, it bounds the
+// wait for response headers; the body is guarded by idleTimeoutReader.
+func newImageHTTPClient(timeout time.Duration, throttled bool) *http.Client {
+	if !throttled {
+		return &http.Client{Timeout: timeout}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
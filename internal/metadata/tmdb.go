@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,19 +17,34 @@ import (
 	"sync"
 	"time"
 
+	"github.com/marco/movieVault/internal/diskspace"
 	"github.com/marco/movieVault/internal/metadata/cache"
 	"github.com/marco/movieVault/internal/retry"
 	"github.com/marco/movieVault/internal/writer"
 )
 
 const (
-	tmdbAPIBaseURL  = "https://api.themoviedb.org/3"
+	tmdbAPIHost      = "api.themoviedb.org"
+	tmdbAPIBaseURL   = "https://" + tmdbAPIHost + "/3"
 	tmdbImageBaseURL = "https://image.tmdb.org/t/p"
-	posterSize      = "w500"
-	backdropSize    = "w1280"
+	posterSize       = "w500"
+	backdropSize     = "w1280"
+
+	// maxFacetValues caps the studios and countries kept per movie
+	maxFacetValues = 5
+
+	// DefaultUserAgent is sent on all requests when ClientConfig.UserAgent is empty
+	DefaultUserAgent = "movieVault/dev"
+
+	// DefaultRequestTimeout bounds a TMDB API call when ClientConfig.RequestTimeoutSeconds is unset
+	DefaultRequestTimeout = 10 * time.Second
+	// DefaultImageTimeout bounds an image download when ClientConfig.ImageTimeoutSeconds is unset
+	DefaultImageTimeout = 60 * time.Second
 )
 
-// RetryLogFunc is a callback for logging retry attempts
+// RetryLogFunc is a callback for logging retry attempts. When retries run out it
+// is called a final time with backoff 0 and a *retry.ExhaustedError summarizing
+// the attempt count and whether rate limiting was involved.
 type RetryLogFunc func(attempt int, maxAttempts int, backoff time.Duration, err error)
 
 // CacheLogFunc is a callback for logging cache operations
@@ -33,33 +52,74 @@ type CacheLogFunc func(operation string, key string, hit bool)
 
 // Client represents a TMDB API client
 type Client struct {
-	apiKey         string
-	language       string
-	httpClient     *http.Client
-	rateDelay      time.Duration
-	rateLimiter    *time.Ticker
-	rateLimiterMu  sync.Mutex // protects rateLimiter for Close()
-	maxAttempts    int
-	initialBackoff time.Duration
-	retryLogFunc   RetryLogFunc
-	cache          cache.Cache
-	cacheTTL       time.Duration
-	cacheLogFunc   CacheLogFunc
-	forceRefresh   bool
+	apiKey              string
+	accessToken         string
+	language            string
+	httpClient          *http.Client // TMDB API requests
+	imageHTTPClient     *http.Client // Image downloads, which may be large
+	imageTimeout        time.Duration
+	rateDelay           time.Duration
+	rateLimiter         *time.Ticker
+	rateLimiterMu       sync.Mutex // protects rateLimiter for Close()
+	maxAttempts         int
+	maxRateLimitRetries int
+	initialBackoff      time.Duration
+	maxBackoff          time.Duration
+	retryLogFunc        RetryLogFunc
+	cache               cache.Cache
+	cacheTTL            time.Duration
+	cacheLogFunc        CacheLogFunc
+	forceRefresh        bool
+	titlePreference     string
+	userAgent           string
+	headers             map[string]string
+	fetchKeywords       bool
+	includeFinancials   bool
+	requireCredits      bool
+	apiBaseURL          string           // No trailing slash
+	apiHost             string           // host[:port] of apiBaseURL; requests to it carry custom headers
+	imageBaseURL        string           // No trailing slash
+	imageHost           string           // host[:port] of imageBaseURL
+	imageLimiter        *byteRateLimiter // nil when image downloads are unthrottled
+	minVoteCount        int              // Vote floor for preferring a search result
+	blocklist           map[int]bool     // TMDB IDs never picked from search results
+	minFreeSpaceMB      int              // Refuse image writes below this much free disk space (0 = no check)
+	fsWriteRetries      int              // Extra attempts for failed image writes (0 = no retry)
+	logger              *slog.Logger
 }
 
 // ClientConfig holds configuration for the TMDB client
 type ClientConfig struct {
-	APIKey           string
-	Language         string
-	RateLimitDelayMs int
-	MaxAttempts      int
-	InitialBackoffMs int
-	RetryLogFunc     RetryLogFunc
-	Cache            cache.Cache
-	CacheTTLDays     int
-	CacheLogFunc     CacheLogFunc
-	ForceRefresh     bool
+	APIKey                string
+	AccessToken           string // v4 Read Access Token; when set, used instead of APIKey
+	Language              string
+	RateLimitDelayMs      int
+	RequestsPerSecond     float64 // Caps API calls per second across workers; overrides RateLimitDelayMs when > 0
+	MaxAttempts           int
+	MaxRateLimitRetries   int // Retries for HTTP 429 (default: 5, negative = never retry)
+	InitialBackoffMs      int
+	MaxBackoffMs          int // Cap for exponential backoff (default: retry.DefaultMaxBackoff)
+	RetryLogFunc          RetryLogFunc
+	Cache                 cache.Cache
+	CacheTTLDays          int
+	CacheLogFunc          CacheLogFunc
+	ForceRefresh          bool
+	TitlePreference       string            // "localized" (default), "original", or "country:XX"
+	UserAgent             string            // User-Agent for all requests (default: DefaultUserAgent)
+	Headers               map[string]string // Extra headers sent to TMDB hosts (e.g. proxy auth)
+	FetchKeywords         bool              // Include TMDB keywords in movie metadata
+	IncludeFinancials     bool              // Include TMDB budget and revenue in movie metadata
+	RequireCredits        bool              // Fail the lookup when credits cannot be fetched instead of leaving cast empty
+	RequestTimeoutSeconds int               // Timeout per TMDB API request (default: DefaultRequestTimeout)
+	ImageTimeoutSeconds   int               // Timeout per image download, including the body unless throttled (default: DefaultImageTimeout)
+	ImageDownloadRateKbps int               // Combined bandwidth cap for image downloads in kilobits/s (0 = unlimited)
+	APIBaseURL            string            // TMDB API root, e.g. a caching proxy (default: https://api.themoviedb.org/3)
+	ImageBaseURL          string            // TMDB image root (default: https://image.tmdb.org/t/p)
+	MinVoteCount          int               // Prefer search results with at least this many votes (0 = take TMDB's first result)
+	Blocklist             []int             // TMDB IDs skipped in search results, e.g. spam entries that outrank the real film
+	MinFreeSpaceMB        int               // Refuse to write images when the covers disk has less free space (0 = no check)
+	FSWriteRetries        int               // Extra attempts when writing a downloaded image fails, e.g. on NFS (0 = no retry)
+	Logger                *slog.Logger      // Receives the client's log messages (default: slog.Default())
 }
 
 // NewClient creates a new TMDB API client
@@ -84,23 +144,80 @@ func NewClientWithConfig(cfg ClientConfig) *Client {
 	if cfg.InitialBackoffMs <= 0 {
 		cfg.InitialBackoffMs = 1000
 	}
+	if cfg.MaxRateLimitRetries == 0 {
+		cfg.MaxRateLimitRetries = 5
+	} else if cfg.MaxRateLimitRetries < 0 {
+		cfg.MaxRateLimitRetries = 0
+	}
 	if cfg.CacheTTLDays <= 0 {
 		cfg.CacheTTLDays = 30
 	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = tmdbAPIBaseURL
+	}
+	if cfg.ImageBaseURL == "" {
+		cfg.ImageBaseURL = tmdbImageBaseURL
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	cfg.APIBaseURL = strings.TrimRight(cfg.APIBaseURL, "/")
+	cfg.ImageBaseURL = strings.TrimRight(cfg.ImageBaseURL, "/")
 	rateDelay := time.Duration(cfg.RateLimitDelayMs) * time.Millisecond
+	if cfg.RequestsPerSecond > 0 {
+		rateDelay = time.Duration(float64(time.Second) / cfg.RequestsPerSecond)
+	}
+	requestTimeout := DefaultRequestTimeout
+	if cfg.RequestTimeoutSeconds > 0 {
+		requestTimeout = time.Duration(cfg.RequestTimeoutSeconds) * time.Second
+	}
+	imageTimeout := DefaultImageTimeout
+	if cfg.ImageTimeoutSeconds > 0 {
+		imageTimeout = time.Duration(cfg.ImageTimeoutSeconds) * time.Second
+	}
 
 	client := &Client{
-		apiKey:         cfg.APIKey,
-		language:       cfg.Language,
-		httpClient:     &http.Client{Timeout: 30 * time.Second},
-		rateDelay:      rateDelay,
-		maxAttempts:    cfg.MaxAttempts,
-		initialBackoff: time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
-		retryLogFunc:   cfg.RetryLogFunc,
-		cache:          cfg.Cache,
-		cacheTTL:       time.Duration(cfg.CacheTTLDays) * 24 * time.Hour,
-		cacheLogFunc:   cfg.CacheLogFunc,
-		forceRefresh:   cfg.ForceRefresh,
+		apiKey:              cfg.APIKey,
+		accessToken:         cfg.AccessToken,
+		language:            cfg.Language,
+		httpClient:          &http.Client{Timeout: requestTimeout},
+		imageHTTPClient:     newImageHTTPClient(imageTimeout, cfg.ImageDownloadRateKbps > 0),
+		imageTimeout:        imageTimeout,
+		rateDelay:           rateDelay,
+		maxAttempts:         cfg.MaxAttempts,
+		maxRateLimitRetries: cfg.MaxRateLimitRetries,
+		initialBackoff:      time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		maxBackoff:          time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		retryLogFunc:        cfg.RetryLogFunc,
+		cache:               cfg.Cache,
+		cacheTTL:            time.Duration(cfg.CacheTTLDays) * 24 * time.Hour,
+		cacheLogFunc:        cfg.CacheLogFunc,
+		forceRefresh:        cfg.ForceRefresh,
+		titlePreference:     cfg.TitlePreference,
+		userAgent:           cfg.UserAgent,
+		headers:             cfg.Headers,
+		fetchKeywords:       cfg.FetchKeywords,
+		includeFinancials:   cfg.IncludeFinancials,
+		requireCredits:      cfg.RequireCredits,
+		imageLimiter:        newByteRateLimiter(cfg.ImageDownloadRateKbps),
+		apiBaseURL:          cfg.APIBaseURL,
+		apiHost:             urlHost(cfg.APIBaseURL),
+		imageBaseURL:        cfg.ImageBaseURL,
+		imageHost:           urlHost(cfg.ImageBaseURL),
+		minVoteCount:        cfg.MinVoteCount,
+		minFreeSpaceMB:      cfg.MinFreeSpaceMB,
+		fsWriteRetries:      cfg.FSWriteRetries,
+		logger:              cfg.Logger,
+	}
+
+	if len(cfg.Blocklist) > 0 {
+		client.blocklist = make(map[int]bool, len(cfg.Blocklist))
+		for _, id := range cfg.Blocklist {
+			client.blocklist[id] = true
+		}
 	}
 
 	if rateDelay > 0 {
@@ -133,61 +250,195 @@ func (c *Client) waitForRateLimit() {
 }
 
 // doRequestWithRetry executes an HTTP GET request with retry logic.
-// For TMDB API requests (api.themoviedb.org), the centralized rate limiter
-// is consulted before each attempt. Image CDN requests are not rate-limited.
+// For TMDB API requests (under the API base URL), the centralized rate limiter
+// is consulted before each attempt and the request timeout applies. Image
+// and other requests are not rate-limited and use the image timeout.
+// HTTP 429 responses honor Retry-After and do not consume a regular attempt;
+// they draw from the separate maxRateLimitRetries budget instead.
 func (c *Client) doRequestWithRetry(requestURL string) (*http.Response, error) {
 	// Rate-limit only TMDB API calls, not image CDN downloads
-	if strings.Contains(requestURL, "api.themoviedb.org") {
+	httpClient := c.imageHTTPClient
+	if c.isAPIRequest(requestURL) {
 		c.waitForRateLimit()
+		httpClient = c.httpClient
 	}
 
 	var resp *http.Response
-	var lastErr error
-	attempt := 0
+	opts := retry.Options{
+		MaxAttempts:         c.maxAttempts,
+		MaxRateLimitRetries: c.maxRateLimitRetries,
+		InitialBackoff:      c.initialBackoff,
+		MaxBackoff:          c.maxBackoff,
+	}
+	if c.retryLogFunc != nil {
+		opts.OnRetry = func(attempt int, maxAttempts int, wait time.Duration, err error) {
+			c.retryLogFunc(attempt, maxAttempts, wait, err)
+		}
+	}
 
-	err := retry.Retry(func() error {
-		attempt++
-		var reqErr error
-		resp, reqErr = c.httpClient.Get(requestURL)
+	err := retry.RetryWithOptions(func() error {
+		req, reqErr := c.newRequest(requestURL)
 		if reqErr != nil {
-			lastErr = reqErr
-			// Log retry attempt if callback provided
-			if c.retryLogFunc != nil && attempt < c.maxAttempts {
-				backoff := c.initialBackoff * time.Duration(1<<(attempt-1))
-				if retry.IsRateLimited(reqErr) {
-					backoff *= 2
-				}
-				c.retryLogFunc(attempt, c.maxAttempts, backoff, reqErr)
-			}
+			return reqErr
+		}
+		resp, reqErr = httpClient.Do(req)
+		if reqErr != nil {
+			return reqErr
+		}
+		if reqErr = decodeResponseBody(resp); reqErr != nil {
+			resp.Body.Close()
 			return reqErr
 		}
 
-		// Check for retryable HTTP status codes
-		if resp.StatusCode >= 500 || resp.StatusCode == 429 {
+		// Rate limited: surface the server's Retry-After hint
+		if resp.StatusCode == http.StatusTooManyRequests {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			statusErr := fmt.Errorf("TMDB API error (status %d): %s", resp.StatusCode, string(body))
-			lastErr = statusErr
-			// Log retry attempt if callback provided
-			if c.retryLogFunc != nil && attempt < c.maxAttempts {
-				backoff := c.initialBackoff * time.Duration(1<<(attempt-1))
-				if resp.StatusCode == 429 {
-					backoff *= 2
-				}
-				c.retryLogFunc(attempt, c.maxAttempts, backoff, statusErr)
+			return &retry.RateLimitError{
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+				Err:        &APIError{StatusCode: resp.StatusCode, Body: string(body)},
 			}
-			return statusErr
+		}
+
+		// Check for retryable HTTP status codes
+		if resp.StatusCode >= 500 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		return nil
-	}, c.maxAttempts, c.initialBackoff)
+	}, opts)
 
 	if err != nil {
-		return nil, lastErr
+		return nil, err
 	}
 	return resp, nil
 }
 
+// newRequest builds a GET request carrying the client's User-Agent. Custom
+// headers are only attached for TMDB hosts and the configured API and image
+// base URLs, so that credentials such as proxy auth are never sent to
+// arbitrary NFO image URLs.
+func (c *Client) newRequest(requestURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+	// Setting Accept-Encoding disables the transport's transparent gzip
+	// handling, so decodeResponseBody decompresses explicitly
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	if c.accessToken != "" && c.isAPIRequest(requestURL) {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	if isTMDBHost(req.URL.Hostname()) || req.URL.Host == c.apiHost || req.URL.Host == c.imageHost {
+		for name, value := range c.headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	return req, nil
+}
+
+// isAPIRequest reports whether requestURL is under the API base URL. The base
+// URLs are compared by path, not just host, so one reverse proxy can serve both
+// (e.g. http://proxy.lan/3 and http://proxy.lan/t/p); when both match, the
+// longer base URL wins.
+func (c *Client) isAPIRequest(requestURL string) bool {
+	underBase := func(base string) bool {
+		return requestURL == base || strings.HasPrefix(requestURL, base+"/") || strings.HasPrefix(requestURL, base+"?")
+	}
+	if !underBase(c.apiBaseURL) {
+		return false
+	}
+	return len(c.imageBaseURL) <= len(c.apiBaseURL) || !underBase(c.imageBaseURL)
+}
+
+// decodeResponseBody replaces resp.Body with a decompressing reader when the
+// response is gzip or deflate encoded, so callers always read plain bytes
+func decodeResponseBody(resp *http.Response) error {
+	var decoded io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decode gzip response: %w", err)
+		}
+		decoded = gz
+	case "deflate":
+		zr, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decode deflate response: %w", err)
+		}
+		decoded = zr
+	default:
+		return nil
+	}
+
+	resp.Body = &decodedBody{ReadCloser: decoded, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody closes both the decompressor and the underlying response body
+type decodedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+	return err
+}
+
+// setAuthParam adds the v3 api_key query parameter. It is a no-op when the
+// client authenticates with a v4 bearer token, which newRequest sends as a header.
+func (c *Client) setAuthParam(params url.Values) {
+	if c.accessToken != "" {
+		return
+	}
+	params.Set("api_key", c.apiKey)
+}
+
+// isTMDBHost reports whether host belongs to TMDB (API or image CDN)
+func isTMDBHost(host string) bool {
+	return host == "themoviedb.org" || strings.HasSuffix(host, ".themoviedb.org") ||
+		host == "tmdb.org" || strings.HasSuffix(host, ".tmdb.org")
+}
+
+// urlHost returns the host[:port] of rawURL, or "" if it cannot be parsed
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// parseRetryAfter parses a Retry-After header given either as delay seconds or
+// an HTTP date. Returns zero if the header is absent or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
 // getFromCache retrieves data from cache if available and not force-refreshing
 func (c *Client) getFromCache(key string) ([]byte, bool) {
 	if c.cache == nil || c.forceRefresh {
@@ -217,29 +468,156 @@ func (c *Client) setToCache(key string, data []byte) {
 
 // SearchMovie searches for a movie by title and optional year
 func (c *Client) SearchMovie(title string, year int) (*TMDBMovie, error) {
-	// Build cache key
-	cacheKey := fmt.Sprintf("tmdb:search:%s:%d", title, year)
+	return c.searchMovie(title, year, "")
+}
+
+// searchMovie is SearchMovie with the original language guessed from the
+// filename, which is searched when the client language finds nothing
+func (c *Client) searchMovie(title string, year int, originalLanguage string) (*TMDBMovie, error) {
+	cacheKey := c.searchCacheKey(title, year)
 
 	// Check cache first
 	if cachedData, found := c.getFromCache(cacheKey); found {
 		var cachedResult TMDBMovie
-		if err := json.Unmarshal(cachedData, &cachedResult); err == nil {
+		// A result cached before its ID was blocklisted is searched again
+		if err := json.Unmarshal(cachedData, &cachedResult); err == nil && !c.blocklist[cachedResult.ID] {
 			return &cachedResult, nil
 		}
 	}
 
+	results, err := c.searchWithFallback(title, year, originalLanguage)
+	if err != nil {
+		return nil, err
+	}
+	selected := selectSearchResult(results, c.minVoteCount, c.blocklist)
+	if selected < 0 {
+		return nil, fmt.Errorf("no results found for '%s' (all %d results are in tmdb_blocklist)", title, len(results))
+	}
+	result := &results[selected]
+	if result.ID != results[0].ID {
+		c.logger.Debug("tmdb search: skipped blocklisted or low-vote results",
+			"query", title,
+			"skipped_id", results[0].ID,
+			"skipped_votes", results[0].VoteCount,
+			"selected_id", result.ID,
+			"selected_votes", result.VoteCount,
+		)
+	}
+
+	// Cache the result
+	if resultData, err := json.Marshal(result); err == nil {
+		c.setToCache(cacheKey, resultData)
+	}
+
+	return result, nil
+}
+
+// SearchMovieCandidates returns every search result for title and optional
+// year that is not in tmdb_blocklist, most relevant first. Used when the best
+// match is picked by something other than TMDB's ranking, such as runtime.
+func (c *Client) SearchMovieCandidates(title string, year int) ([]TMDBMovie, error) {
+	return c.searchMovieCandidates(title, year, "")
+}
+
+// searchMovieCandidates is SearchMovieCandidates with the original language
+// guessed from the filename, see searchMovie
+func (c *Client) searchMovieCandidates(title string, year int, originalLanguage string) ([]TMDBMovie, error) {
+	cacheKey := c.localizedCacheKey(fmt.Sprintf("tmdb:search_candidates:%s:%d", title, year))
+
+	if cachedData, found := c.getFromCache(cacheKey); found {
+		var cached []TMDBMovie
+		if err := json.Unmarshal(cachedData, &cached); err == nil {
+			if candidates := c.unblocked(cached); len(candidates) > 0 {
+				return candidates, nil
+			}
+		}
+	}
+
+	results, err := c.searchWithFallback(title, year, originalLanguage)
+	if err != nil {
+		return nil, err
+	}
+	candidates := c.unblocked(results)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no results found for '%s' (all %d results are in tmdb_blocklist)", title, len(results))
+	}
+
+	if data, err := json.Marshal(candidates); err == nil {
+		c.setToCache(cacheKey, data)
+	}
+	return candidates, nil
+}
+
+// unblocked returns the results whose IDs are not in the blocklist
+func (c *Client) unblocked(results []TMDBMovie) []TMDBMovie {
+	var kept []TMDBMovie
+	for _, r := range results {
+		if !c.blocklist[r.ID] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// searchWithFallback searches in the client language, then in
+// originalLanguage when nothing matched and it is a different language.
+// Returns an error when both come back empty.
+func (c *Client) searchWithFallback(title string, year int, originalLanguage string) ([]TMDBMovie, error) {
+	results, err := c.searchResults(title, year, c.language)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 && originalLanguage != "" && !sameLanguage(originalLanguage, c.language) {
+		// Original-language titles (e.g. "La Vita è Bella") sometimes miss with
+		// a localized search, so retry in the language the filename names
+		if results, err = c.searchResults(title, year, originalLanguage); err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			c.logger.Info("tmdb search: matched in original language",
+				"query", title,
+				"language", originalLanguage,
+				"client_language", c.language,
+			)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results found for '%s'", title)
+	}
+	return results, nil
+}
+
+// sameLanguage reports whether two TMDB language values share their ISO 639-1
+// part, e.g. "it" and "it-IT". An empty value is TMDB's default, en-US.
+func sameLanguage(a, b string) bool {
+	primary := func(language string) string {
+		if language == "" {
+			return "en"
+		}
+		code, _, _ := strings.Cut(language, "-")
+		return strings.ToLower(code)
+	}
+	return primary(a) == primary(b)
+}
+
+// searchResults runs one /search/movie request and returns the first page of
+// results. An empty language leaves the parameter unset.
+func (c *Client) searchResults(title string, year int, language string) ([]TMDBMovie, error) {
 	// Build query parameters
 	params := url.Values{}
-	params.Set("api_key", c.apiKey)
+	c.setAuthParam(params)
 	params.Set("query", title)
 	if year > 0 {
 		params.Set("year", strconv.Itoa(year))
 	}
-	params.Set("language", c.language)
+	if language != "" {
+		params.Set("language", language)
+	}
 	params.Set("page", "1")
 
 	// Make request with retry
-	searchURL := fmt.Sprintf("%s/search/movie?%s", tmdbAPIBaseURL, params.Encode())
+	searchURL := fmt.Sprintf("%s/search/movie?%s", c.apiBaseURL, params.Encode())
 	resp, err := c.doRequestWithRetry(searchURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search movie: %w", err)
@@ -248,7 +626,7 @@ func (c *Client) SearchMovie(title string, year int) (*TMDBMovie, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse response
@@ -257,23 +635,34 @@ func (c *Client) SearchMovie(title string, year int) (*TMDBMovie, error) {
 		return nil, fmt.Errorf("failed to decode search response: %w", err)
 	}
 
-	// Return first result if available
-	if len(searchResp.Results) == 0 {
-		return nil, fmt.Errorf("no results found for '%s'", title)
-	}
+	return searchResp.Results, nil
+}
 
-	// Cache the result
-	if resultData, err := json.Marshal(searchResp.Results[0]); err == nil {
-		c.setToCache(cacheKey, resultData)
+// selectSearchResult returns the index of the best search result that is not
+// in blocked: the first (most relevant) one with at least minVotes votes, so
+// obscure or fan-made entries don't win over the real film. When no result
+// reaches the floor, or minVotes is 0, the first unblocked result is used.
+// Returns -1 when every result is blocked.
+func selectSearchResult(results []TMDBMovie, minVotes int, blocked map[int]bool) int {
+	first := -1
+	for i, r := range results {
+		if blocked[r.ID] {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if minVotes <= 0 || r.VoteCount >= minVotes {
+			return i
+		}
 	}
-
-	return &searchResp.Results[0], nil
+	return first
 }
 
 // GetMovieDetails fetches detailed information about a movie
 func (c *Client) GetMovieDetails(tmdbID int) (*TMDBMovieDetails, error) {
 	// Build cache key
-	cacheKey := fmt.Sprintf("tmdb:movie:%d", tmdbID)
+	cacheKey := c.localizedCacheKey(fmt.Sprintf("tmdb:movie:%d", tmdbID))
 
 	// Check cache first
 	if cachedData, found := c.getFromCache(cacheKey); found {
@@ -282,12 +671,15 @@ func (c *Client) GetMovieDetails(tmdbID int) (*TMDBMovieDetails, error) {
 			return &cachedResult, nil
 		}
 	}
+	if combined, found := c.getCombinedFromCache(tmdbID); found {
+		return &combined.TMDBMovieDetails, nil
+	}
 
 	params := url.Values{}
-	params.Set("api_key", c.apiKey)
+	c.setAuthParam(params)
 	params.Set("language", c.language)
 
-	detailsURL := fmt.Sprintf("%s/movie/%d?%s", tmdbAPIBaseURL, tmdbID, params.Encode())
+	detailsURL := fmt.Sprintf("%s/movie/%d?%s", c.apiBaseURL, tmdbID, params.Encode())
 	resp, err := c.doRequestWithRetry(detailsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie details: %w", err)
@@ -296,7 +688,7 @@ func (c *Client) GetMovieDetails(tmdbID int) (*TMDBMovieDetails, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var details TMDBMovieDetails
@@ -312,10 +704,49 @@ func (c *Client) GetMovieDetails(tmdbID int) (*TMDBMovieDetails, error) {
 	return &details, nil
 }
 
+// GetCollection fetches a collection (franchise) and its member movies
+func (c *Client) GetCollection(collectionID int) (*TMDBCollection, error) {
+	cacheKey := c.localizedCacheKey(fmt.Sprintf("tmdb:collection:%d", collectionID))
+
+	if cachedData, found := c.getFromCache(cacheKey); found {
+		var cachedResult TMDBCollection
+		if err := json.Unmarshal(cachedData, &cachedResult); err == nil {
+			return &cachedResult, nil
+		}
+	}
+
+	params := url.Values{}
+	c.setAuthParam(params)
+	params.Set("language", c.language)
+
+	collectionURL := fmt.Sprintf("%s/collection/%d?%s", c.apiBaseURL, collectionID, params.Encode())
+	resp, err := c.doRequestWithRetry(collectionURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var collection TMDBCollection
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to decode collection: %w", err)
+	}
+
+	if resultData, err := json.Marshal(collection); err == nil {
+		c.setToCache(cacheKey, resultData)
+	}
+
+	return &collection, nil
+}
+
 // GetMovieCredits fetches cast and crew information
 func (c *Client) GetMovieCredits(tmdbID int) (*TMDBCreditsResponse, error) {
 	// Build cache key
-	cacheKey := fmt.Sprintf("tmdb:credits:%d", tmdbID)
+	cacheKey := c.localizedCacheKey(fmt.Sprintf("tmdb:credits:%d", tmdbID))
 
 	// Check cache first
 	if cachedData, found := c.getFromCache(cacheKey); found {
@@ -324,12 +755,15 @@ func (c *Client) GetMovieCredits(tmdbID int) (*TMDBCreditsResponse, error) {
 			return &cachedResult, nil
 		}
 	}
+	if combined, found := c.getCombinedFromCache(tmdbID); found {
+		return &combined.Credits, nil
+	}
 
 	params := url.Values{}
-	params.Set("api_key", c.apiKey)
+	c.setAuthParam(params)
 	params.Set("language", c.language)
 
-	creditsURL := fmt.Sprintf("%s/movie/%d/credits?%s", tmdbAPIBaseURL, tmdbID, params.Encode())
+	creditsURL := fmt.Sprintf("%s/movie/%d/credits?%s", c.apiBaseURL, tmdbID, params.Encode())
 	resp, err := c.doRequestWithRetry(creditsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie credits: %w", err)
@@ -338,7 +772,7 @@ func (c *Client) GetMovieCredits(tmdbID int) (*TMDBCreditsResponse, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var credits TMDBCreditsResponse
@@ -354,74 +788,457 @@ func (c *Client) GetMovieCredits(tmdbID int) (*TMDBCreditsResponse, error) {
 	return &credits, nil
 }
 
+// CheckCredentials verifies that TMDB is reachable and accepts the configured
+// API key or access token by requesting the lightweight /configuration endpoint
+func (c *Client) CheckCredentials() error {
+	params := url.Values{}
+	c.setAuthParam(params)
+
+	configURL := fmt.Sprintf("%s/configuration?%s", c.apiBaseURL, params.Encode())
+	resp, err := c.doRequestWithRetry(configURL)
+	if err != nil {
+		return fmt.Errorf("failed to reach TMDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("TMDB rejected the credentials (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// GetMovieKeywords fetches the keywords (tags) of a movie
+func (c *Client) GetMovieKeywords(tmdbID int) (*TMDBKeywordsResponse, error) {
+	// Build cache key
+	cacheKey := fmt.Sprintf("tmdb:keywords:%d", tmdbID)
+
+	// Check cache first
+	if cachedData, found := c.getFromCache(cacheKey); found {
+		var cachedResult TMDBKeywordsResponse
+		if err := json.Unmarshal(cachedData, &cachedResult); err == nil {
+			return &cachedResult, nil
+		}
+	}
+	if combined, found := c.getCombinedFromCache(tmdbID); found && combined.Keywords != nil {
+		return combined.Keywords, nil
+	}
+
+	params := url.Values{}
+	c.setAuthParam(params)
+
+	keywordsURL := fmt.Sprintf("%s/movie/%d/keywords?%s", c.apiBaseURL, tmdbID, params.Encode())
+	resp, err := c.doRequestWithRetry(keywordsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie keywords: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var keywords TMDBKeywordsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&keywords); err != nil {
+		return nil, fmt.Errorf("failed to decode keywords: %w", err)
+	}
+
+	// Cache the result
+	if resultData, err := json.Marshal(keywords); err == nil {
+		c.setToCache(cacheKey, resultData)
+	}
+
+	return &keywords, nil
+}
+
+// GetMovieDetailsWithCredits fetches details, credits, and videos in a single
+// request using append_to_response, halving request volume versus calling
+// GetMovieDetails and GetMovieCredits separately. Keywords are appended too
+// when keyword fetching is enabled.
+func (c *Client) GetMovieDetailsWithCredits(tmdbID int) (*TMDBMovieDetailsWithCredits, error) {
+	// Check cache first; entries cached without keywords still satisfy the
+	// request, since applyKeywords falls back to a separate lookup
+	if combined, found := c.getCombinedFromCache(tmdbID); found {
+		return combined, nil
+	}
+
+	params := url.Values{}
+	c.setAuthParam(params)
+	params.Set("language", c.language)
+	if c.fetchKeywords {
+		params.Set("append_to_response", "credits,videos,keywords")
+	} else {
+		params.Set("append_to_response", "credits,videos")
+	}
+
+	detailsURL := fmt.Sprintf("%s/movie/%d?%s", c.apiBaseURL, tmdbID, params.Encode())
+	resp, err := c.doRequestWithRetry(detailsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var combined TMDBMovieDetailsWithCredits
+	if err := json.NewDecoder(resp.Body).Decode(&combined); err != nil {
+		return nil, fmt.Errorf("failed to decode movie details: %w", err)
+	}
+
+	// Cache the result
+	if resultData, err := json.Marshal(combined); err == nil {
+		c.setToCache(c.combinedCacheKey(tmdbID), resultData)
+	}
+
+	return &combined, nil
+}
+
+// getDetailsAndCredits fetches details and credits with a single combined
+// request. If that fails for a movie that exists, details and credits are
+// fetched separately; a credits failure then only leaves the director and cast
+// empty, unless credits are required. A rate-limited failure is returned as
+// is, since two more requests would only add to the load TMDB is shedding.
+func (c *Client) getDetailsAndCredits(tmdbID int) (*TMDBMovieDetailsWithCredits, error) {
+	combined, err := c.GetMovieDetailsWithCredits(tmdbID)
+	if err == nil || isNotFound(err) {
+		return combined, err
+	}
+	var exhausted *retry.ExhaustedError
+	if errors.As(err, &exhausted) && exhausted.RateLimited {
+		return nil, err
+	}
+
+	details, detailsErr := c.GetMovieDetails(tmdbID)
+	if detailsErr != nil {
+		return nil, err
+	}
+	combined = &TMDBMovieDetailsWithCredits{TMDBMovieDetails: *details}
+
+	credits, err := c.GetMovieCredits(tmdbID)
+	if err != nil {
+		if c.requireCredits {
+			return nil, err
+		}
+		c.logger.Warn("tmdb credits unavailable, continuing without cast and director", "tmdb_id", tmdbID, "error", err)
+		return combined, nil
+	}
+	combined.Credits = *credits
+	return combined, nil
+}
+
+// searchCacheKey returns the cache key for a title search
+func (c *Client) searchCacheKey(title string, year int) string {
+	key := fmt.Sprintf("tmdb:search:%s:%d", title, year)
+	if c.minVoteCount > 0 {
+		// The vote floor changes which result is picked
+		key += fmt.Sprintf(":votes%d", c.minVoteCount)
+	}
+	return c.localizedCacheKey(key)
+}
+
+// combinedCacheKey returns the cache key for append_to_response payloads
+func (c *Client) combinedCacheKey(tmdbID int) string {
+	return c.localizedCacheKey(fmt.Sprintf("tmdb:movie_full:%d", tmdbID))
+}
+
+// localizedCacheKey suffixes key with the client language (e.g.
+// "tmdb:movie:603:it-IT"), so clients with different languages sharing one
+// cache never serve each other's translations. Keywords and alternative titles
+// are language-independent and keep unsuffixed keys.
+func (c *Client) localizedCacheKey(key string) string {
+	return key + ":" + c.language
+}
+
+// getCachedLegacy reads key with the client language suffix, then without
+// it: versions before per-language keys wrote "tmdb:movie:603" and
+// "tmdb:credits:603", and those entries should keep serving cache-only lookups
+// after an upgrade. Ignores force-refresh, like GetCachedMovieByID.
+func (c *Client) getCachedLegacy(key string) ([]byte, bool) {
+	if data, found := c.cache.Get(c.localizedCacheKey(key)); found {
+		return data, true
+	}
+	return c.cache.Get(key)
+}
+
+// getCombinedFromCache retrieves a cached append_to_response payload
+func (c *Client) getCombinedFromCache(tmdbID int) (*TMDBMovieDetailsWithCredits, bool) {
+	cachedData, found := c.getFromCache(c.combinedCacheKey(tmdbID))
+	if !found {
+		return nil, false
+	}
+	var combined TMDBMovieDetailsWithCredits
+	if err := json.Unmarshal(cachedData, &combined); err != nil {
+		return nil, false
+	}
+	return &combined, true
+}
+
+// GetAlternativeTitles fetches country-specific alternative titles for a movie
+func (c *Client) GetAlternativeTitles(tmdbID int) (*TMDBAlternativeTitlesResponse, error) {
+	// Build cache key
+	cacheKey := fmt.Sprintf("tmdb:alt_titles:%d", tmdbID)
+
+	// Check cache first
+	if cachedData, found := c.getFromCache(cacheKey); found {
+		var cachedResult TMDBAlternativeTitlesResponse
+		if err := json.Unmarshal(cachedData, &cachedResult); err == nil {
+			return &cachedResult, nil
+		}
+	}
+
+	params := url.Values{}
+	c.setAuthParam(params)
+
+	titlesURL := fmt.Sprintf("%s/movie/%d/alternative_titles?%s", c.apiBaseURL, tmdbID, params.Encode())
+	resp, err := c.doRequestWithRetry(titlesURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alternative titles: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var titles TMDBAlternativeTitlesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&titles); err != nil {
+		return nil, fmt.Errorf("failed to decode alternative titles: %w", err)
+	}
+
+	// Cache the result
+	if resultData, err := json.Marshal(titles); err == nil {
+		c.setToCache(cacheKey, resultData)
+	}
+
+	return &titles, nil
+}
+
+// applyTitlePreference replaces movie.Title according to the configured title
+// preference. Alternative titles are only fetched for "country:XX" preferences;
+// lookup failures keep the localized title.
+func (c *Client) applyTitlePreference(movie *writer.Movie, details *TMDBMovieDetails) {
+	var altTitles []TMDBAlternativeTitle
+	if strings.HasPrefix(c.titlePreference, "country:") {
+		if titles, err := c.GetAlternativeTitles(details.ID); err == nil {
+			altTitles = titles.Titles
+		}
+	}
+	movie.Title = SelectTitle(details, altTitles, c.titlePreference)
+}
+
+// applyKeywords sets movie.Keywords when keyword fetching is enabled, using the
+// appended keywords when present and a separate lookup otherwise. Lookup
+// failures leave the keywords empty.
+func (c *Client) applyKeywords(movie *writer.Movie, combined *TMDBMovieDetailsWithCredits) {
+	if !c.fetchKeywords {
+		return
+	}
+	keywords := combined.Keywords
+	if keywords == nil {
+		fetched, err := c.GetMovieKeywords(combined.ID)
+		if err != nil {
+			return
+		}
+		keywords = fetched
+	}
+	movie.Keywords = keywordNames(keywords)
+}
+
+// keywordNames returns the names of keywords in TMDB order
+func keywordNames(keywords *TMDBKeywordsResponse) []string {
+	var names []string
+	for _, k := range keywords.Keywords {
+		if k.Name != "" {
+			names = append(names, k.Name)
+		}
+	}
+	return names
+}
+
+// SelectTitle picks a display title for a movie based on preference:
+//   - "localized" (or empty): the title in the configured language, falling back to the original title
+//   - "original": the original-language title, falling back to the localized title
+//   - "country:XX": the alternative title for ISO 3166-1 country XX, falling back to localized
+func SelectTitle(details *TMDBMovieDetails, altTitles []TMDBAlternativeTitle, preference string) string {
+	localized := details.Title
+	if localized == "" {
+		localized = details.OriginalTitle
+	}
+
+	switch {
+	case preference == "original":
+		if details.OriginalTitle != "" {
+			return details.OriginalTitle
+		}
+	case strings.HasPrefix(preference, "country:"):
+		country := strings.TrimPrefix(preference, "country:")
+		for _, alt := range altTitles {
+			if strings.EqualFold(alt.ISO31661, country) && alt.Title != "" {
+				return alt.Title
+			}
+		}
+	}
+
+	return localized
+}
+
+// SearchHints carries what is known about a video beyond its parsed title and year
+type SearchHints struct {
+	RuntimeMinutes int    // Actual video length, picks among several search results (0 = unknown)
+	Language       string // ISO 639-1 code from the filename, searched when the client language finds nothing ("" = none)
+}
+
 // GetFullMovieData fetches all data needed for a Movie struct
 func (c *Client) GetFullMovieData(title string, year int) (*writer.Movie, error) {
+	return c.fullMovieData(title, year, "")
+}
+
+// fullMovieData is GetFullMovieData with the original language guessed from
+// the filename, see searchMovie
+func (c *Client) fullMovieData(title string, year int, originalLanguage string) (*writer.Movie, error) {
 	// Search for the movie
-	searchResult, err := c.SearchMovie(title, year)
+	searchResult, err := c.searchMovie(title, year, originalLanguage)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get detailed information
-	details, err := c.GetMovieDetails(searchResult.ID)
+	// Get detailed information and credits in one request
+	combined, err := c.getDetailsAndCredits(searchResult.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get credits
-	credits, err := c.GetMovieCredits(searchResult.ID)
+	movie := c.buildMovie(&combined.TMDBMovieDetails, &combined.Credits)
+	c.applyTitlePreference(movie, &combined.TMDBMovieDetails)
+	c.applyKeywords(movie, combined)
+	return movie, nil
+}
+
+// maxRuntimeCandidates caps how many search results GetFullMovieDataByRuntime
+// fetches details for, since each costs a request on a cold cache
+const maxRuntimeCandidates = 5
+
+// GetFullMovieDataByRuntime is GetFullMovieData for a video whose actual
+// length is known. When the search returns several candidates, the one whose
+// TMDB runtime is closest to runtimeMinutes wins instead of TMDB's first
+// result, which separates remakes and same-name films. With a single
+// candidate or runtimeMinutes <= 0 it behaves like GetFullMovieData.
+func (c *Client) GetFullMovieDataByRuntime(title string, year int, runtimeMinutes int) (*writer.Movie, error) {
+	return c.GetFullMovieDataWithHints(title, year, SearchHints{RuntimeMinutes: runtimeMinutes})
+}
+
+// GetFullMovieDataWithHints is GetFullMovieData using what the scanner learned
+// about the file: a runtime matches like GetFullMovieDataByRuntime, and a
+// language is searched when the client language finds nothing.
+func (c *Client) GetFullMovieDataWithHints(title string, year int, hints SearchHints) (*writer.Movie, error) {
+	runtimeMinutes := hints.RuntimeMinutes
+	if runtimeMinutes <= 0 {
+		return c.fullMovieData(title, year, hints.Language)
+	}
+	candidates, err := c.searchMovieCandidates(title, year, hints.Language)
 	if err != nil {
 		return nil, err
 	}
-
-	// Extract genres
-	var genres []string
-	for _, genre := range details.Genres {
-		genres = append(genres, genre.Name)
+	// Zero-vote spam must not win on runtime over the film the regular search picks
+	candidates = withVotes(candidates, c.minVoteCount)
+	if len(candidates) == 1 {
+		return c.fullMovieData(title, year, hints.Language)
+	}
+	if len(candidates) > maxRuntimeCandidates {
+		candidates = candidates[:maxRuntimeCandidates]
 	}
 
-	// Extract director(s)
-	var directors []string
-	for _, crew := range credits.Crew {
-		if crew.Job == "Director" {
-			directors = append(directors, crew.Name)
+	fetched := make([]*TMDBMovieDetailsWithCredits, 0, len(candidates))
+	runtimes := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		combined, err := c.getDetailsAndCredits(candidate.ID)
+		if err != nil {
+			c.logger.Debug("tmdb runtime match: skipped candidate", "query", title, "tmdb_id", candidate.ID, "error", err)
+			continue
 		}
-	}
-	director := strings.Join(directors, ", ")
+		fetched = append(fetched, combined)
+		runtimes = append(runtimes, combined.Runtime)
+	}
+	selected := closestRuntime(runtimes, runtimeMinutes)
+	if selected < 0 {
+		return c.fullMovieData(title, year, hints.Language)
+	}
+	combined := fetched[selected]
+	c.logger.Debug("tmdb runtime match",
+		"query", title,
+		"file_runtime", runtimeMinutes,
+		"candidates", len(fetched),
+		"selected_id", combined.ID,
+		"selected_runtime", combined.Runtime,
+	)
+
+	movie := c.buildMovie(&combined.TMDBMovieDetails, &combined.Credits)
+	c.applyTitlePreference(movie, &combined.TMDBMovieDetails)
+	c.applyKeywords(movie, combined)
+	return movie, nil
+}
 
-	// Extract top cast (first 5)
-	var cast []string
-	maxCast := 5
-	if len(credits.Cast) < maxCast {
-		maxCast = len(credits.Cast)
+// withVotes returns the results with at least minVotes votes, the floor
+// selectSearchResult applies. All results are kept when minVotes is 0 or none
+// reaches it, as selectSearchResult then falls back to the first result.
+func withVotes(results []TMDBMovie, minVotes int) []TMDBMovie {
+	if minVotes <= 0 {
+		return results
+	}
+	var kept []TMDBMovie
+	for _, r := range results {
+		if r.VoteCount >= minVotes {
+			kept = append(kept, r)
+		}
 	}
-	for i := 0; i < maxCast; i++ {
-		cast = append(cast, credits.Cast[i].Name)
+	if len(kept) == 0 {
+		return results
 	}
+	return kept
+}
 
-	// Extract release year
-	releaseYear := 0
-	if len(details.ReleaseDate) >= 4 {
-		releaseYear, _ = strconv.Atoi(details.ReleaseDate[:4])
+// closestRuntime returns the index of the runtime nearest target, preferring
+// the earlier (more relevant) one on ties. Unknown (zero) runtimes are
+// skipped; returns -1 when none is known.
+func closestRuntime(runtimes []int, target int) int {
+	best, bestDiff := -1, 0
+	for i, r := range runtimes {
+		if r <= 0 {
+			continue
+		}
+		diff := r - target
+		if diff < 0 {
+			diff = -diff
+		}
+		if best < 0 || diff < bestDiff {
+			best, bestDiff = i, diff
+		}
 	}
+	return best
+}
 
-	// Build Movie struct
-	movie := &writer.Movie{
-		Title:         details.Title,
-		Description:   details.Overview,
-		Rating:        details.VoteAverage,
-		ReleaseYear:   releaseYear,
-		ReleaseDate:   details.ReleaseDate,
-		Runtime:       details.Runtime,
-		Genres:        genres,
-		Director:      director,
-		Cast:          cast,
-		TMDBID:        details.ID,
-		IMDbID:        details.IMDbID,
-		ScannedAt:     time.Now(),
-	}
+// APIError is a TMDB API response with an unexpected HTTP status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
 
-	return movie, nil
+func (e *APIError) Error() string {
+	return fmt.Sprintf("TMDB API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// isNotFound reports whether err is a TMDB 404 response
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 // ErrMovieNotFound is returned when a movie is not found by ID
@@ -429,22 +1246,131 @@ var ErrMovieNotFound = fmt.Errorf("movie not found")
 
 // GetMovieByID fetches a movie directly by its TMDB ID, bypassing search
 func (c *Client) GetMovieByID(tmdbID int) (*writer.Movie, error) {
-	// Get detailed information
-	details, err := c.GetMovieDetails(tmdbID)
+	// Get detailed information and credits in one request
+	combined, err := c.getDetailsAndCredits(tmdbID)
 	if err != nil {
 		// Check for 404 response
-		if strings.Contains(err.Error(), "status 404") {
+		if isNotFound(err) {
 			return nil, ErrMovieNotFound
 		}
 		return nil, err
 	}
 
-	// Get credits
-	credits, err := c.GetMovieCredits(tmdbID)
-	if err != nil {
-		return nil, err
+	movie := c.buildMovie(&combined.TMDBMovieDetails, &combined.Credits)
+	c.applyTitlePreference(movie, &combined.TMDBMovieDetails)
+	c.applyKeywords(movie, combined)
+	return movie, nil
+}
+
+// ErrCacheMiss is returned by cache-only lookups when the entry is absent or expired
+var ErrCacheMiss = fmt.Errorf("cache entry not found or expired")
+
+// GetCachedMovieByID rebuilds a movie from cached details and credits responses
+// without making any network requests. Returns ErrCacheMiss if either entry is
+// missing or expired. The force-refresh setting is ignored.
+func (c *Client) GetCachedMovieByID(tmdbID int) (*writer.Movie, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("cache is not enabled")
+	}
+
+	// Prefer the combined append_to_response entry written by current scans
+	if combinedData, found := c.cache.Get(c.combinedCacheKey(tmdbID)); found {
+		var combined TMDBMovieDetailsWithCredits
+		if err := json.Unmarshal(combinedData, &combined); err == nil {
+			movie := c.buildMovie(&combined.TMDBMovieDetails, &combined.Credits)
+			movie.Title = SelectTitle(&combined.TMDBMovieDetails, c.cachedAlternativeTitles(tmdbID), c.titlePreference)
+			if c.fetchKeywords && combined.Keywords != nil {
+				movie.Keywords = keywordNames(combined.Keywords)
+			} else {
+				movie.Keywords = c.cachedKeywords(tmdbID)
+			}
+			return movie, nil
+		}
+	}
+
+	// Fall back to separate entries written by older scans
+	detailsData, found := c.getCachedLegacy(fmt.Sprintf("tmdb:movie:%d", tmdbID))
+	if !found {
+		return nil, fmt.Errorf("movie details for TMDB ID %d: %w", tmdbID, ErrCacheMiss)
+	}
+	var details TMDBMovieDetails
+	if err := json.Unmarshal(detailsData, &details); err != nil {
+		return nil, fmt.Errorf("failed to decode cached movie details: %w", err)
+	}
+
+	creditsData, found := c.getCachedLegacy(fmt.Sprintf("tmdb:credits:%d", tmdbID))
+	if !found {
+		return nil, fmt.Errorf("credits for TMDB ID %d: %w", tmdbID, ErrCacheMiss)
+	}
+	var credits TMDBCreditsResponse
+	if err := json.Unmarshal(creditsData, &credits); err != nil {
+		return nil, fmt.Errorf("failed to decode cached credits: %w", err)
+	}
+
+	movie := c.buildMovie(&details, &credits)
+	movie.Title = SelectTitle(&details, c.cachedAlternativeTitles(tmdbID), c.titlePreference)
+	movie.Keywords = c.cachedKeywords(tmdbID)
+	return movie, nil
+}
+
+// WarmCache performs the same search, details, and credits lookup as a scan of
+// title and year, so a later scan is served from the cache. alreadyCached
+// reports that the search result and combined details were cached beforehand
+// and no request was made. Honors force-refresh by always fetching.
+func (c *Client) WarmCache(title string, year int) (alreadyCached bool, err error) {
+	if c.cache == nil {
+		return false, fmt.Errorf("cache is not enabled")
+	}
+
+	if !c.forceRefresh {
+		if data, found := c.cache.Get(c.searchCacheKey(title, year)); found {
+			var result TMDBMovie
+			if json.Unmarshal(data, &result) == nil {
+				if _, found := c.cache.Get(c.combinedCacheKey(result.ID)); found {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	if _, err := c.GetFullMovieData(title, year); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// cachedAlternativeTitles returns alternative titles from the cache only, or nil
+func (c *Client) cachedAlternativeTitles(tmdbID int) []TMDBAlternativeTitle {
+	data, found := c.cache.Get(fmt.Sprintf("tmdb:alt_titles:%d", tmdbID))
+	if !found {
+		return nil
+	}
+	var titles TMDBAlternativeTitlesResponse
+	if err := json.Unmarshal(data, &titles); err != nil {
+		return nil
+	}
+	return titles.Titles
+}
+
+// cachedKeywords returns keyword names from the cache only, or nil when keyword
+// fetching is disabled or nothing is cached
+func (c *Client) cachedKeywords(tmdbID int) []string {
+	if !c.fetchKeywords {
+		return nil
+	}
+	data, found := c.cache.Get(fmt.Sprintf("tmdb:keywords:%d", tmdbID))
+	if !found {
+		return nil
+	}
+	var keywords TMDBKeywordsResponse
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return nil
 	}
+	return keywordNames(&keywords)
+}
 
+// buildMovie converts TMDB details and credits responses into a writer.Movie
+func (c *Client) buildMovie(details *TMDBMovieDetails, credits *TMDBCreditsResponse) *writer.Movie {
 	// Extract genres
 	var genres []string
 	for _, genre := range details.Genres {
@@ -476,39 +1402,72 @@ func (c *Client) GetMovieByID(tmdbID int) (*writer.Movie, error) {
 		releaseYear, _ = strconv.Atoi(details.ReleaseDate[:4])
 	}
 
+	// Extract studios and countries, in TMDB's order
+	var studios []string
+	for _, company := range details.ProductionCompanies {
+		if len(studios) < maxFacetValues && company.Name != "" {
+			studios = append(studios, company.Name)
+		}
+	}
+	var countries []string
+	for _, country := range details.ProductionCountries {
+		if len(countries) < maxFacetValues && country.Name != "" {
+			countries = append(countries, country.Name)
+		}
+	}
+
+	var collection string
+	if details.BelongsToCollection != nil {
+		collection = details.BelongsToCollection.Name
+	}
+
 	// Build Movie struct
 	movie := &writer.Movie{
 		Title:       details.Title,
 		Description: details.Overview,
-		Rating:      details.VoteAverage,
+		Rating:      writer.NormalizeRating(details.VoteAverage),
 		ReleaseYear: releaseYear,
 		ReleaseDate: details.ReleaseDate,
-		Runtime:     details.Runtime,
+		Runtime:     writer.NormalizeRuntime(details.Runtime),
 		Genres:      genres,
 		Director:    director,
 		Cast:        cast,
 		TMDBID:      details.ID,
 		IMDbID:      details.IMDbID,
 		ScannedAt:   time.Now(),
+		PosterURL:   c.ImageURL(details.PosterPath, "poster"),
+		BackdropURL: c.ImageURL(details.BackdropPath, "backdrop"),
+		Collection:  collection,
+		Studios:     studios,
+		Countries:   countries,
 	}
-
-	return movie, nil
+	if c.includeFinancials {
+		movie.Budget = details.Budget
+		movie.Revenue = details.Revenue
+	}
+	return movie
 }
 
-// DownloadImage downloads an image from TMDB to a local path
-func (c *Client) DownloadImage(imagePath string, outputPath string, imageType string) error {
+// ImageURL returns the full TMDB URL for an image path from the API, sized
+// for imageType ("poster" or "backdrop"). Returns "" for an empty path.
+func (c *Client) ImageURL(imagePath string, imageType string) string {
 	if imagePath == "" {
-		return fmt.Errorf("image path is empty")
+		return ""
 	}
-
-	// Determine size based on type
 	size := posterSize
 	if imageType == "backdrop" {
 		size = backdropSize
 	}
+	return fmt.Sprintf("%s/%s%s", c.imageBaseURL, size, imagePath)
+}
+
+// DownloadImage downloads an image from TMDB to a local path
+func (c *Client) DownloadImage(imagePath string, outputPath string, imageType string) error {
+	if imagePath == "" {
+		return fmt.Errorf("image path is empty")
+	}
 
-	// Build image URL
-	imageURL := fmt.Sprintf("%s/%s%s", tmdbImageBaseURL, size, imagePath)
+	imageURL := c.ImageURL(imagePath, imageType)
 
 	// Download image with retry
 	resp, err := c.doRequestWithRetry(imageURL)
@@ -521,24 +1480,7 @@ func (c *Client) DownloadImage(imagePath string, outputPath string, imageType st
 		return fmt.Errorf("failed to download image (status %d)", resp.StatusCode)
 	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create output file
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer outFile.Close()
-
-	// Copy image data
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to write image: %w", err)
-	}
-
-	return nil
+	return c.writeImage(outputPath, c.throttleImage(resp.Body))
 }
 
 // DownloadImageFromURL downloads an image from an arbitrary URL or copies from a local path (US-020)
@@ -550,7 +1492,7 @@ func (c *Client) DownloadImageFromURL(imageURL string, outputPath string) error
 
 	// Local filesystem path — copy directly
 	if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
-		return copyLocalImage(imageURL, outputPath)
+		return c.copyLocalImage(imageURL, outputPath)
 	}
 
 	// Download image with retry
@@ -564,47 +1506,44 @@ func (c *Client) DownloadImageFromURL(imageURL string, outputPath string) error
 		return fmt.Errorf("failed to download image from URL (status %d)", resp.StatusCode)
 	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create output file
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer outFile.Close()
-
-	// Copy image data
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to write image: %w", err)
-	}
-
-	return nil
+	return c.writeImage(outputPath, c.throttleImage(resp.Body))
 }
 
 // copyLocalImage copies an image from a local filesystem path to the output path
-func copyLocalImage(srcPath string, outputPath string) error {
+func (c *Client) copyLocalImage(srcPath string, outputPath string) error {
 	src, err := os.Open(srcPath)
 	if err != nil {
 		return fmt.Errorf("failed to open local image %s: %w", srcPath, err)
 	}
 	defer src.Close()
 
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
+	return c.writeImage(outputPath, src)
+}
 
-	dst, err := os.Create(outputPath)
+// writeImage reads r fully and writes it to outputPath through a temporary
+// file that is renamed into place, so a failed download (e.g. a full disk)
+// never leaves a truncated or empty image that later scans would treat as
+// present. The image is buffered so a failed write can be retried
+// (fs_write_retries) without downloading it again.
+func (c *Client) writeImage(outputPath string, r io.Reader) error {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return fmt.Errorf("failed to read image: %w", err)
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, src); err != nil {
-		return fmt.Errorf("failed to copy local image: %w", err)
+	// Ensure output directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := diskspace.Check(outputPath, c.minFreeSpaceMB); err != nil {
+		return fmt.Errorf("refusing to write image: %w", err)
 	}
 
+	err = retry.RetryLocalWithLogger(func() error {
+		return writer.WriteFileAtomic(outputPath, data)
+	}, c.fsWriteRetries, c.logger)
+	if err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
 	return nil
 }
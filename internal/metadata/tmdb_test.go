@@ -0,0 +1,994 @@
+package metadata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marco/movieVault/internal/metadata/cache"
+	"github.com/marco/movieVault/internal/writer"
+)
+
+func TestSelectTitle(t *testing.T) {
+	details := &TMDBMovieDetails{ID: 1, Title: "La città incantata", OriginalTitle: "千と千尋の神隠し"}
+	altTitles := []TMDBAlternativeTitle{
+		{ISO31661: "US", Title: "Spirited Away"},
+		{ISO31661: "FR", Title: "Le Voyage de Chihiro"},
+	}
+
+	testCases := []struct {
+		preference string
+		expected   string
+	}{
+		{"", "La città incantata"},
+		{"localized", "La città incantata"},
+		{"original", "千と千尋の神隠し"},
+		{"country:US", "Spirited Away"},
+		{"country:fr", "Le Voyage de Chihiro"},
+		{"country:DE", "La città incantata"},
+	}
+
+	for _, tc := range testCases {
+		if got := SelectTitle(details, altTitles, tc.preference); got != tc.expected {
+			t.Errorf("SelectTitle(%q) = %q, want %q", tc.preference, got, tc.expected)
+		}
+	}
+
+	// Empty localized title falls back to original
+	empty := &TMDBMovieDetails{OriginalTitle: "Original"}
+	if got := SelectTitle(empty, nil, "localized"); got != "Original" {
+		t.Errorf("SelectTitle with empty localized title = %q, want %q", got, "Original")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"garbage", 0},
+		{"Thu, 01 Jan 2026 12:00:30 GMT", 30 * time.Second},
+		{"Thu, 01 Jan 2026 11:59:00 GMT", 0},
+	}
+
+	for _, tc := range testCases {
+		if got := parseRetryAfter(tc.header, now); got != tc.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.header, got, tc.expected)
+		}
+	}
+}
+
+func TestNewRequest_Headers(t *testing.T) {
+	client := NewClientWithConfig(ClientConfig{
+		APIKey:  "key",
+		Headers: map[string]string{"Proxy-Authorization": "Basic abc"},
+	})
+	defer client.Close()
+
+	req, err := client.newRequest("https://api.themoviedb.org/3/movie/1")
+	if err != nil {
+		t.Fatalf("newRequest failed: %v", err)
+	}
+	if got := req.Header.Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, DefaultUserAgent)
+	}
+	if got := req.Header.Get("Proxy-Authorization"); got != "Basic abc" {
+		t.Errorf("Proxy-Authorization = %q, want %q", got, "Basic abc")
+	}
+
+	// Custom headers must not leak to non-TMDB hosts
+	req, err = client.newRequest("https://example.com/poster.jpg")
+	if err != nil {
+		t.Fatalf("newRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Proxy-Authorization"); got != "" {
+		t.Errorf("Proxy-Authorization sent to non-TMDB host: %q", got)
+	}
+	if got := req.Header.Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, DefaultUserAgent)
+	}
+}
+
+func TestNewRequest_BearerAuth(t *testing.T) {
+	client := NewClientWithConfig(ClientConfig{AccessToken: "jwt"})
+	defer client.Close()
+
+	req, err := client.newRequest(tmdbAPIBaseURL + "/movie/1")
+	if err != nil {
+		t.Fatalf("newRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer jwt" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer jwt")
+	}
+
+	params := url.Values{}
+	client.setAuthParam(params)
+	if params.Has("api_key") {
+		t.Error("api_key query param should not be set with bearer auth")
+	}
+
+	// Image CDN requests do not carry the token
+	req, err = client.newRequest(tmdbImageBaseURL + "/w500/poster.jpg")
+	if err != nil {
+		t.Fatalf("newRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization sent to image CDN: %q", got)
+	}
+}
+
+func TestNewClientWithConfig_RequestsPerSecond(t *testing.T) {
+	testCases := []struct {
+		name     string
+		delayMs  int
+		rps      float64
+		expected time.Duration
+	}{
+		{"delay only", 250, 0, 250 * time.Millisecond},
+		{"requests per second", 0, 50, 20 * time.Millisecond},
+		{"requests per second wins", 250, 10, 100 * time.Millisecond},
+		{"fractional", 0, 0.5, 2 * time.Second},
+		{"unlimited", 0, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClientWithConfig(ClientConfig{APIKey: "key", RateLimitDelayMs: tc.delayMs, RequestsPerSecond: tc.rps})
+			defer client.Close()
+			if client.rateDelay != tc.expected {
+				t.Errorf("rateDelay = %v, want %v", client.rateDelay, tc.expected)
+			}
+			if (client.rateLimiter != nil) != (tc.expected > 0) {
+				t.Errorf("rateLimiter set = %v, want %v", client.rateLimiter != nil, tc.expected > 0)
+			}
+		})
+	}
+}
+
+func TestDoRequestWithRetry_DecodesGzip(t *testing.T) {
+	const payload = `{"id":27205,"cast":[{"name":"Leonardo DiCaprio"}]}`
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(payload))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key"})
+	defer client.Close()
+
+	resp, err := client.doRequestWithRetry(server.URL + "/movie/27205/credits")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if string(body) != payload {
+		t.Errorf("body = %q, want %q", body, payload)
+	}
+	if acceptEncoding != "gzip, deflate" {
+		t.Errorf("Accept-Encoding = %q, want %q", acceptEncoding, "gzip, deflate")
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Error("Content-Encoding header should be removed after decoding")
+	}
+}
+
+func TestDoRequestWithRetry_RateLimitRetriesDisabled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", APIBaseURL: server.URL, MaxAttempts: 1, MaxRateLimitRetries: -1})
+	defer client.Close()
+
+	if _, err := client.doRequestWithRetry(server.URL + "/movie/603"); err == nil {
+		t.Fatal("expected the 429 to be returned")
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, want 1 (no 429 retries)", requests)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	combined := &TMDBMovieDetailsWithCredits{
+		TMDBMovieDetails: TMDBMovieDetails{ID: 603, Title: "The Matrix", ReleaseDate: "1999-03-30"},
+		Keywords: &TMDBKeywordsResponse{ID: 603, Keywords: []TMDBKeyword{
+			{ID: 4565, Name: "dystopia"},
+			{ID: 310, Name: "artificial intelligence"},
+		}},
+	}
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db, FetchKeywords: true})
+	data, _ := json.Marshal(combined)
+	if err := db.Set(client.combinedCacheKey(603), data, time.Hour); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	disabled := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db})
+	movie := &writer.Movie{}
+	disabled.applyKeywords(movie, combined)
+	if movie.Keywords != nil {
+		t.Errorf("expected no keywords when disabled, got %v", movie.Keywords)
+	}
+
+	expected := []string{"dystopia", "artificial intelligence"}
+
+	client.applyKeywords(movie, combined)
+	if !reflect.DeepEqual(movie.Keywords, expected) {
+		t.Errorf("applyKeywords() = %v, want %v", movie.Keywords, expected)
+	}
+
+	// Served from the appended payload without a separate request
+	keywords, err := client.GetMovieKeywords(603)
+	if err != nil || !reflect.DeepEqual(keywordNames(keywords), expected) {
+		t.Errorf("GetMovieKeywords() = %v, %v; want %v", keywords, err, expected)
+	}
+
+	cached, err := client.GetCachedMovieByID(603)
+	if err != nil {
+		t.Fatalf("GetCachedMovieByID failed: %v", err)
+	}
+	if !reflect.DeepEqual(cached.Keywords, expected) {
+		t.Errorf("cached keywords = %v, want %v", cached.Keywords, expected)
+	}
+}
+
+func TestCacheKeys_IncludeLanguage(t *testing.T) {
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	english := NewClientWithConfig(ClientConfig{APIKey: "key", Language: "en-US", Cache: db})
+	italian := NewClientWithConfig(ClientConfig{APIKey: "key", Language: "it-IT", Cache: db})
+
+	if got := italian.combinedCacheKey(603); got != "tmdb:movie_full:603:it-IT" {
+		t.Errorf("combinedCacheKey() = %q, want %q", got, "tmdb:movie_full:603:it-IT")
+	}
+	if english.localizedCacheKey("tmdb:search:Matrix:1999") == italian.localizedCacheKey("tmdb:search:Matrix:1999") {
+		t.Error("expected search keys to differ per language")
+	}
+
+	// An English entry in a shared cache must not be served to the Italian client
+	data, _ := json.Marshal(TMDBMovieDetailsWithCredits{
+		TMDBMovieDetails: TMDBMovieDetails{ID: 603, Title: "The Matrix"},
+	})
+	if err := db.Set(english.combinedCacheKey(603), data, time.Hour); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	if movie, err := english.GetCachedMovieByID(603); err != nil || movie.Title != "The Matrix" {
+		t.Errorf("english GetCachedMovieByID() = %v, %v", movie, err)
+	}
+	if _, err := italian.GetCachedMovieByID(603); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("italian GetCachedMovieByID() error = %v, want ErrCacheMiss", err)
+	}
+}
+
+// handlerTransport serves every request from handler instead of the network
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, r)
+	return rec.Result(), nil
+}
+
+func TestGetCachedMovieByID_LegacyKeys(t *testing.T) {
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	// Entries written before cache keys carried the language
+	details, _ := json.Marshal(TMDBMovieDetails{ID: 949, Title: "Heat", Runtime: 170})
+	credits, _ := json.Marshal(TMDBCreditsResponse{ID: 949, Crew: []TMDBCrewMember{{Name: "Michael Mann", Job: "Director"}}})
+	if err := db.Set("tmdb:movie:949", details, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set("tmdb:credits:949", credits, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db})
+	defer client.Close()
+	movie, err := client.GetCachedMovieByID(949)
+	if err != nil {
+		t.Fatalf("GetCachedMovieByID() error: %v", err)
+	}
+	if movie.Title != "Heat" || movie.Runtime != 170 || movie.Director != "Michael Mann" {
+		t.Errorf("GetCachedMovieByID() = %+v", movie)
+	}
+}
+
+func TestGetMovieByID_CreditsFailure(t *testing.T) {
+	// The combined and credits requests fail; plain details succeed
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/3/movie/603" && r.URL.Query().Get("append_to_response") == "" {
+			json.NewEncoder(w).Encode(TMDBMovieDetails{ID: 603, Title: "The Matrix", ReleaseDate: "1999-03-30"})
+			return
+		}
+		http.Error(w, "upstream timeout", http.StatusBadGateway)
+	})
+
+	testCases := []struct {
+		name           string
+		requireCredits bool
+		wantErr        bool
+	}{
+		{"degrades to empty credits", false, false},
+		{"strict mode fails", true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClientWithConfig(ClientConfig{APIKey: "key", MaxAttempts: 1, RequireCredits: tc.requireCredits})
+			defer client.Close()
+			client.httpClient.Transport = handlerTransport{handler}
+
+			movie, err := client.GetMovieByID(603)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetMovieByID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if movie.Title != "The Matrix" || movie.ReleaseYear != 1999 {
+				t.Errorf("movie = %q (%d), want The Matrix (1999)", movie.Title, movie.ReleaseYear)
+			}
+			if movie.Director != "" || len(movie.Cast) != 0 {
+				t.Errorf("expected empty credits, got director %q cast %v", movie.Director, movie.Cast)
+			}
+		})
+	}
+}
+
+func TestGetMovieByID_NoFallback(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{"rate limited", http.StatusTooManyRequests, nil},
+		{"not found", http.StatusNotFound, ErrMovieNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int
+			client := NewClientWithConfig(ClientConfig{APIKey: "key", MaxAttempts: 1, MaxRateLimitRetries: -1})
+			defer client.Close()
+			client.httpClient.Transport = handlerTransport{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				http.Error(w, "nope", tc.status)
+			})}
+
+			_, err := client.GetMovieByID(603)
+			if err == nil || (tc.wantErr != nil && !errors.Is(err, tc.wantErr)) {
+				t.Errorf("GetMovieByID() error = %v, want %v", err, tc.wantErr)
+			}
+			if requests != 1 {
+				t.Errorf("requests = %d, want 1 (no separate details and credits requests)", requests)
+			}
+		})
+	}
+}
+
+func TestDoRequestWithRetry_SeparateTimeouts(t *testing.T) {
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", RequestTimeoutSeconds: 5, ImageTimeoutSeconds: 120})
+	defer client.Close()
+
+	if client.httpClient.Timeout != 5*time.Second || client.imageHTTPClient.Timeout != 120*time.Second {
+		t.Errorf("timeouts = %v / %v, want 5s / 2m", client.httpClient.Timeout, client.imageHTTPClient.Timeout)
+	}
+
+	defaults := NewClientWithConfig(ClientConfig{APIKey: "key"})
+	defer defaults.Close()
+	if defaults.httpClient.Timeout != DefaultRequestTimeout || defaults.imageHTTPClient.Timeout != DefaultImageTimeout {
+		t.Errorf("default timeouts = %v / %v", defaults.httpClient.Timeout, defaults.imageHTTPClient.Timeout)
+	}
+
+	// API calls and image downloads go through their own clients
+	var apiCalls, imageCalls int
+	client.httpClient.Transport = handlerTransport{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { apiCalls++ })}
+	client.imageHTTPClient.Transport = handlerTransport{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { imageCalls++ })}
+
+	for _, requestURL := range []string{tmdbAPIBaseURL + "/movie/603", tmdbImageBaseURL + "/w500/poster.jpg"} {
+		resp, err := client.doRequestWithRetry(requestURL)
+		if err != nil {
+			t.Fatalf("request to %s failed: %v", requestURL, err)
+		}
+		resp.Body.Close()
+	}
+	if apiCalls != 1 || imageCalls != 1 {
+		t.Errorf("api calls = %d, image calls = %d; want 1 each", apiCalls, imageCalls)
+	}
+}
+
+func TestThrottledReader_PacesReads(t *testing.T) {
+	limiter := newByteRateLimiter(80) // 10,000 bytes/s, burst 10,000
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+	limiter.last = clock
+	limiter.now = func() time.Time { return clock }
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+
+	data := bytes.Repeat([]byte("x"), 35000)
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, &throttledReader{r: bytes.NewReader(data), limiter: limiter}); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if out.Len() != len(data) {
+		t.Fatalf("copied %d bytes, want %d", out.Len(), len(data))
+	}
+	// The first 10,000 bytes come from the initial burst; the rest take 2.5s
+	if slept != 2500*time.Millisecond {
+		t.Errorf("slept %v, want 2.5s", slept)
+	}
+
+	if newByteRateLimiter(0) != nil {
+		t.Error("newByteRateLimiter(0) should be nil (unlimited)")
+	}
+}
+
+// blockingBody never returns data until it is closed
+type blockingBody struct {
+	closed chan struct{}
+}
+
+func (b *blockingBody) Read(p []byte) (int, error) {
+	<-b.closed
+	return 0, errors.New("read on closed body")
+}
+
+func (b *blockingBody) Close() error {
+	close(b.closed)
+	return nil
+}
+
+func TestIdleTimeoutReader_Stalled(t *testing.T) {
+	r := &idleTimeoutReader{body: &blockingBody{closed: make(chan struct{})}, timeout: 20 * time.Millisecond}
+	if _, err := r.Read(make([]byte, 10)); err == nil || !strings.Contains(err.Error(), "stalled") {
+		t.Errorf("Read() error = %v, want a stall error", err)
+	}
+}
+
+func TestDownloadImage_ThrottledLongerThanTimeout(t *testing.T) {
+	image := bytes.Repeat([]byte("x"), 2500)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(image)
+	}))
+	defer server.Close()
+
+	// 1000 bytes/s: the download takes about 1.5s, longer than the 1s timeout,
+	// but data never stops arriving for a whole second
+	client := NewClientWithConfig(ClientConfig{
+		APIKey:                "key",
+		ImageBaseURL:          server.URL,
+		ImageTimeoutSeconds:   1,
+		ImageDownloadRateKbps: 8,
+	})
+	defer client.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "backdrop.jpg")
+	if err := client.DownloadImage("/backdrop.jpg", outputPath, "backdrop"); err != nil {
+		t.Fatalf("DownloadImage() error: %v", err)
+	}
+	if data, err := os.ReadFile(outputPath); err != nil || len(data) != len(image) {
+		t.Errorf("downloaded %d bytes (err %v), want %d", len(data), err, len(image))
+	}
+}
+
+func TestClient_CustomBaseURLs(t *testing.T) {
+	var seen []string
+	var authHeaders []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.URL.Path)
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if strings.HasPrefix(r.URL.Path, "/images/") {
+			w.Write([]byte("image"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":603,"title":"The Matrix","poster_path":"/poster.jpg"}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	imageServer := httptest.NewServer(handler)
+	defer imageServer.Close()
+
+	client := NewClientWithConfig(ClientConfig{
+		AccessToken:  "token",
+		APIBaseURL:   server.URL + "/tmdb/3/",
+		ImageBaseURL: imageServer.URL + "/images/",
+	})
+	defer client.Close()
+
+	details, err := client.GetMovieDetails(603)
+	if err != nil {
+		t.Fatalf("GetMovieDetails() error: %v", err)
+	}
+	if got, want := client.ImageURL(details.PosterPath, "poster"), imageServer.URL+"/images/w500/poster.jpg"; got != want {
+		t.Errorf("ImageURL() = %q, want %q", got, want)
+	}
+	if err := client.DownloadImage(details.PosterPath, filepath.Join(t.TempDir(), "poster.jpg"), "poster"); err != nil {
+		t.Fatalf("DownloadImage() error: %v", err)
+	}
+
+	wantPaths := []string{"/tmdb/3/movie/603", "/images/w500/poster.jpg"}
+	if !reflect.DeepEqual(seen, wantPaths) {
+		t.Errorf("requested paths = %v, want %v", seen, wantPaths)
+	}
+	if authHeaders[0] != "Bearer token" || authHeaders[1] != "" {
+		t.Errorf("Authorization headers = %q, want bearer on the API request only", authHeaders)
+	}
+}
+
+func TestClient_SharedProxyBaseURLs(t *testing.T) {
+	var seen []string
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.URL.Path)
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if strings.HasPrefix(r.URL.Path, "/t/p/") {
+			w.Write([]byte("image"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":603,"title":"The Matrix","poster_path":"/poster.jpg"}`))
+	}))
+	defer server.Close()
+
+	// One reverse proxy serves both the API and the images
+	client := NewClientWithConfig(ClientConfig{
+		AccessToken:  "token",
+		APIBaseURL:   server.URL + "/3",
+		ImageBaseURL: server.URL + "/t/p",
+	})
+	defer client.Close()
+
+	details, err := client.GetMovieDetails(603)
+	if err != nil {
+		t.Fatalf("GetMovieDetails() error: %v", err)
+	}
+	if err := client.DownloadImage(details.PosterPath, filepath.Join(t.TempDir(), "poster.jpg"), "poster"); err != nil {
+		t.Fatalf("DownloadImage() error: %v", err)
+	}
+
+	wantPaths := []string{"/3/movie/603", "/t/p/w500/poster.jpg"}
+	if !reflect.DeepEqual(seen, wantPaths) {
+		t.Errorf("requested paths = %v, want %v", seen, wantPaths)
+	}
+	if authHeaders[0] != "Bearer token" || authHeaders[1] != "" {
+		t.Errorf("Authorization headers = %q, want bearer on the API request only", authHeaders)
+	}
+}
+
+func TestIsAPIRequest(t *testing.T) {
+	testCases := []struct {
+		apiBase, imageBase, url string
+		want                    bool
+	}{
+		{"https://api.themoviedb.org/3", "https://image.tmdb.org/t/p", "https://api.themoviedb.org/3/movie/603?api_key=k", true},
+		{"https://api.themoviedb.org/3", "https://image.tmdb.org/t/p", "https://image.tmdb.org/t/p/w500/a.jpg", false},
+		{"http://proxy.lan/3", "http://proxy.lan/t/p", "http://proxy.lan/3/search/movie?query=x", true},
+		{"http://proxy.lan/3", "http://proxy.lan/t/p", "http://proxy.lan/t/p/original/a.jpg", false},
+		{"http://proxy.lan/3", "http://proxy.lan/t/p", "http://proxy.lan/30/other", false},
+		{"http://proxy.lan", "http://proxy.lan/t/p", "http://proxy.lan/t/p/w500/a.jpg", false},
+		{"http://proxy.lan", "http://proxy.lan/t/p", "http://proxy.lan/movie/603", true},
+		{"https://api.themoviedb.org/3", "https://image.tmdb.org/t/p", "https://example.com/poster.jpg", false},
+	}
+
+	for _, tc := range testCases {
+		c := NewClientWithConfig(ClientConfig{APIKey: "k", APIBaseURL: tc.apiBase, ImageBaseURL: tc.imageBase})
+		if got := c.isAPIRequest(tc.url); got != tc.want {
+			t.Errorf("isAPIRequest(%q) with API %q, images %q = %v, want %v", tc.url, tc.apiBase, tc.imageBase, got, tc.want)
+		}
+		c.Close()
+	}
+}
+
+func TestGetCollection(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":8091,"name":"Alien Collection","parts":[{"id":348,"title":"Alien","release_date":"1979-05-25"},{"id":679,"title":"Aliens","release_date":"1986-07-18"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", APIBaseURL: server.URL})
+	defer client.Close()
+
+	collection, err := client.GetCollection(8091)
+	if err != nil {
+		t.Fatalf("GetCollection() error: %v", err)
+	}
+	if collection.Name != "Alien Collection" || len(collection.Parts) != 2 || collection.Parts[1].ID != 679 {
+		t.Errorf("GetCollection() = %+v", collection)
+	}
+	if len(paths) != 1 || paths[0] != "/collection/8091" {
+		t.Errorf("requested paths = %v, want [/collection/8091]", paths)
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/search/movie" {
+			w.Write([]byte(`{"results":[{"id":603,"title":"The Matrix"}]}`))
+			return
+		}
+		w.Write([]byte(`{"id":603,"title":"The Matrix","credits":{"cast":[],"crew":[]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db, APIBaseURL: server.URL})
+	defer client.Close()
+
+	cached, err := client.WarmCache("The Matrix", 1999)
+	if err != nil || cached {
+		t.Fatalf("first WarmCache() = %v, %v; want false, nil", cached, err)
+	}
+	if requests != 2 {
+		t.Errorf("first WarmCache made %d requests, want 2 (search + details)", requests)
+	}
+
+	cached, err = client.WarmCache("The Matrix", 1999)
+	if err != nil || !cached {
+		t.Fatalf("second WarmCache() = %v, %v; want true, nil", cached, err)
+	}
+	if requests != 2 {
+		t.Errorf("second WarmCache made %d more requests, want 0", requests-2)
+	}
+
+	if _, err := NewClient("key", "en-US", 0).WarmCache("The Matrix", 1999); err == nil {
+		t.Error("WarmCache without a cache should fail")
+	}
+}
+
+func TestSearchMovie_LanguageFallback(t *testing.T) {
+	var languages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language := r.URL.Query().Get("language")
+		languages = append(languages, language)
+		w.Header().Set("Content-Type", "application/json")
+		if language != "it" {
+			w.Write([]byte(`{"results":[]}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"id":637,"title":"La vita è bella"}]}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name             string
+		clientLanguage   string
+		originalLanguage string
+		wantID           int
+		wantLanguages    []string
+	}{
+		{"retried in the filename language", "en-US", "it", 637, []string{"en-US", "it"}},
+		{"no language in the filename", "en-US", "", 0, []string{"en-US"}},
+		{"filename language is the client language", "it-IT", "it", 0, []string{"it-IT"}},
+		{"english filename with the default language", "", "en", 0, []string{"en-US"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages = nil
+			client := NewClientWithConfig(ClientConfig{APIKey: "key", Language: tc.clientLanguage, APIBaseURL: server.URL})
+			defer client.Close()
+
+			result, err := client.searchMovie("La Vita E Bella", 1997, tc.originalLanguage)
+			if tc.wantID == 0 {
+				if err == nil {
+					t.Errorf("searchMovie() = %+v, want no results", result)
+				}
+			} else if err != nil || result.ID != tc.wantID {
+				t.Errorf("searchMovie() = %+v, %v, want ID %d", result, err, tc.wantID)
+			}
+			if strings.Join(languages, ",") != strings.Join(tc.wantLanguages, ",") {
+				t.Errorf("search languages = %q, want %q", languages, tc.wantLanguages)
+			}
+		})
+	}
+}
+
+func TestSelectSearchResult(t *testing.T) {
+	results := []TMDBMovie{
+		{ID: 1, Title: "Heat (fan edit)", VoteCount: 2},
+		{ID: 2, Title: "Heat", VoteCount: 7000},
+		{ID: 3, Title: "Heat", VoteCount: 40},
+	}
+
+	testCases := []struct {
+		name     string
+		results  []TMDBMovie
+		minVotes int
+		blocked  map[int]bool
+		want     int
+	}{
+		{"disabled", results, 0, nil, 0},
+		{"first above floor", results, 10, nil, 1},
+		{"floor above every result", results, 10000, nil, 0},
+		{"first result qualifies", results[1:], 10, nil, 0},
+		{"blocked first result", results, 0, map[int]bool{1: true}, 1},
+		{"blocked result above floor", results, 10, map[int]bool{2: true}, 2},
+		{"floor above every unblocked result", results, 10000, map[int]bool{1: true}, 1},
+		{"every result blocked", results, 0, map[int]bool{1: true, 2: true, 3: true}, -1},
+	}
+
+	for _, tc := range testCases {
+		if got := selectSearchResult(tc.results, tc.minVotes, tc.blocked); got != tc.want {
+			t.Errorf("%s: selectSearchResult(min %d) = %d, want %d", tc.name, tc.minVotes, got, tc.want)
+		}
+	}
+}
+
+func TestClosestRuntime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		runtimes []int
+		target   int
+		want     int
+	}{
+		{"closest wins", []int{98, 136, 120}, 137, 1},
+		{"tie keeps the earlier result", []int{100, 110}, 105, 0},
+		{"unknown runtime skipped", []int{0, 90}, 140, 1},
+		{"no runtime known", []int{0, 0}, 120, -1},
+		{"no candidates", nil, 120, -1},
+	}
+
+	for _, tc := range testCases {
+		if got := closestRuntime(tc.runtimes, tc.target); got != tc.want {
+			t.Errorf("%s: closestRuntime(%v, %d) = %d, want %d", tc.name, tc.runtimes, tc.target, got, tc.want)
+		}
+	}
+}
+
+// runtimeServer serves a search for "Solaris" with several candidates and
+// their details, counting requests by path
+func runtimeServer(t *testing.T, requests map[string]int) *httptest.Server {
+	t.Helper()
+	runtimes := map[string]int{"13": 167, "10": 167, "11": 99, "12": 160}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/search/movie" {
+			w.Write([]byte(`{"results":[
+				{"id":13,"title":"Solaris","vote_count":5000},
+				{"id":10,"title":"Solaris","vote_count":0},
+				{"id":11,"title":"Solaris","vote_count":900},
+				{"id":12,"title":"Solaris","vote_count":1200}
+			]}`))
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/movie/")
+		fmt.Fprintf(w, `{"id":%s,"title":"Solaris","runtime":%d,"credits":{"cast":[],"crew":[]}}`, id, runtimes[id])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetFullMovieDataByRuntime(t *testing.T) {
+	testCases := []struct {
+		name      string
+		minVotes  int
+		blocklist []int
+		runtime   int
+		wantID    int
+		wantFetch int // detail requests
+	}{
+		{"closest runtime", 0, nil, 167, 13, 4},
+		{"blocklisted candidate skipped", 0, []int{13}, 167, 10, 3},
+		{"vote floor before runtime", 10, []int{13}, 167, 12, 2},
+		{"single candidate above floor", 1000, []int{13}, 99, 12, 1},
+		{"unknown runtime", 0, []int{13}, 0, 10, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := make(map[string]int)
+			server := runtimeServer(t, requests)
+			client := NewClientWithConfig(ClientConfig{
+				APIKey:       "key",
+				APIBaseURL:   server.URL,
+				MinVoteCount: tc.minVotes,
+				Blocklist:    tc.blocklist,
+			})
+			defer client.Close()
+
+			movie, err := client.GetFullMovieDataByRuntime("Solaris", 0, tc.runtime)
+			if err != nil {
+				t.Fatalf("GetFullMovieDataByRuntime failed: %v", err)
+			}
+			if movie.TMDBID != tc.wantID {
+				t.Errorf("TMDBID = %d, want %d", movie.TMDBID, tc.wantID)
+			}
+			fetched := 0
+			for path, n := range requests {
+				if strings.HasPrefix(path, "/movie/") {
+					fetched += n
+				}
+			}
+			if fetched != tc.wantFetch {
+				t.Errorf("fetched details %d times, want %d: %v", fetched, tc.wantFetch, requests)
+			}
+		})
+	}
+}
+
+func TestSearchMovieCandidates(t *testing.T) {
+	db, err := cache.NewSQLiteCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer db.Close()
+
+	requests := make(map[string]int)
+	server := runtimeServer(t, requests)
+
+	client := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db, APIBaseURL: server.URL, Blocklist: []int{13}})
+	defer client.Close()
+
+	ids := func(movies []TMDBMovie) []int {
+		var out []int
+		for _, m := range movies {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	candidates, err := client.SearchMovieCandidates("Solaris", 0)
+	if err != nil {
+		t.Fatalf("SearchMovieCandidates failed: %v", err)
+	}
+	if got, want := ids(candidates), []int{10, 11, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+
+	// Served from the cache, with IDs blocklisted since then left out
+	blocking := NewClientWithConfig(ClientConfig{APIKey: "key", Cache: db, APIBaseURL: server.URL, Blocklist: []int{10}})
+	defer blocking.Close()
+	candidates, err = blocking.SearchMovieCandidates("Solaris", 0)
+	if err != nil {
+		t.Fatalf("cached SearchMovieCandidates failed: %v", err)
+	}
+	if got, want := ids(candidates), []int{11, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached candidates = %v, want %v", got, want)
+	}
+	if requests["/search/movie"] != 1 {
+		t.Errorf("searched %d times, want 1", requests["/search/movie"])
+	}
+
+	everything := NewClientWithConfig(ClientConfig{APIKey: "key", APIBaseURL: server.URL, Blocklist: []int{10, 11, 12, 13}})
+	defer everything.Close()
+	if _, err := everything.SearchMovieCandidates("Solaris", 0); err == nil {
+		t.Error("expected an error when every result is blocklisted")
+	}
+}
+
+// failingReader returns some data, then an error, like a download cut off by a full disk
+type failingReader struct {
+	sent bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("no space left on device")
+	}
+	r.sent = true
+	return copy(p, "partial image"), nil
+}
+
+func TestWriteImage_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "covers", "heat-1995.jpg")
+	client := &Client{}
+
+	if err := client.writeImage(outputPath, &failingReader{}); err == nil {
+		t.Fatal("writeImage succeeded with a failing reader")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(outputPath))
+	if len(entries) != 0 {
+		t.Errorf("failed write left files behind: %v", entries)
+	}
+
+	if err := client.writeImage(outputPath, strings.NewReader("image")); err != nil {
+		t.Fatalf("writeImage failed: %v", err)
+	}
+	if data, err := os.ReadFile(outputPath); err != nil || string(data) != "image" {
+		t.Errorf("image = %q, %v; want %q", data, err, "image")
+	}
+}
+
+func TestBuildMovie_Facets(t *testing.T) {
+	details := &TMDBMovieDetails{
+		ID:      27205,
+		Title:   "Inception",
+		Budget:  160000000,
+		Revenue: 839030630,
+		ProductionCompanies: []TMDBCompany{
+			{Name: "Legendary Pictures", OriginCountry: "US"},
+			{Name: ""},
+			{Name: "Syncopy", OriginCountry: "GB"},
+			{Name: "Warner Bros. Pictures"},
+			{Name: "A"}, {Name: "B"}, {Name: "C"},
+		},
+		ProductionCountries: []TMDBCountry{
+			{ISO31661: "GB", Name: "United Kingdom"},
+			{ISO31661: "US", Name: "United States of America"},
+		},
+	}
+
+	movie := (&Client{}).buildMovie(details, &TMDBCreditsResponse{})
+	wantStudios := []string{"Legendary Pictures", "Syncopy", "Warner Bros. Pictures", "A", "B"}
+	if !reflect.DeepEqual(movie.Studios, wantStudios) {
+		t.Errorf("Studios = %v, want %v", movie.Studios, wantStudios)
+	}
+	wantCountries := []string{"United Kingdom", "United States of America"}
+	if !reflect.DeepEqual(movie.Countries, wantCountries) {
+		t.Errorf("Countries = %v, want %v", movie.Countries, wantCountries)
+	}
+	if movie.Budget != 0 || movie.Revenue != 0 {
+		t.Errorf("financials set without include_financials: budget %d, revenue %d", movie.Budget, movie.Revenue)
+	}
+
+	movie = (&Client{includeFinancials: true}).buildMovie(details, &TMDBCreditsResponse{})
+	if movie.Budget != 160000000 || movie.Revenue != 839030630 {
+		t.Errorf("financials = budget %d, revenue %d; want 160000000, 839030630", movie.Budget, movie.Revenue)
+	}
+
+	movie = (&Client{}).buildMovie(&TMDBMovieDetails{ID: 1, Title: "Heat"}, &TMDBCreditsResponse{})
+	if movie.Studios != nil || movie.Countries != nil {
+		t.Errorf("expected no studios or countries, got %v, %v", movie.Studios, movie.Countries)
+	}
+}
@@ -44,6 +44,7 @@ type TMDBMovieDetails struct {
 	Revenue          int64                `json:"revenue"`
 	Genres           []TMDBGenre          `json:"genres"`
 	ProductionCompanies []TMDBCompany     `json:"production_companies"`
+	ProductionCountries []TMDBCountry     `json:"production_countries"`
 	SpokenLanguages  []TMDBLanguage       `json:"spoken_languages"`
 	Status           string               `json:"status"`
 	IMDbID           string               `json:"imdb_id"`
@@ -51,6 +52,25 @@ type TMDBMovieDetails struct {
 	Adult            bool                 `json:"adult"`
 	Video            bool                 `json:"video"`
 	OriginalLanguage string               `json:"original_language"`
+	BelongsToCollection *TMDBCollectionRef `json:"belongs_to_collection"`
+}
+
+// TMDBCollectionRef identifies the collection (franchise) a movie belongs to
+type TMDBCollectionRef struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+}
+
+// TMDBCollection represents a collection (franchise) and its member movies
+type TMDBCollection struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Overview     string      `json:"overview"`
+	PosterPath   string      `json:"poster_path"`
+	BackdropPath string      `json:"backdrop_path"`
+	Parts        []TMDBMovie `json:"parts"`
 }
 
 // TMDBGenre represents a movie genre
@@ -67,6 +87,12 @@ type TMDBCompany struct {
 	OriginCountry string `json:"origin_country"`
 }
 
+// TMDBCountry represents a production country
+type TMDBCountry struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Name     string `json:"name"`
+}
+
 // TMDBLanguage represents a spoken language
 type TMDBLanguage struct {
 	ISO6391     string `json:"iso_639_1"`
@@ -98,3 +124,53 @@ type TMDBCrewMember struct {
 	Department  string `json:"department"`
 	ProfilePath string `json:"profile_path"`
 }
+
+// TMDBMovieDetailsWithCredits represents a details response fetched with
+// append_to_response=credits,videos, combining three payloads in one request.
+// Keywords is only present when keywords were also appended.
+type TMDBMovieDetailsWithCredits struct {
+	TMDBMovieDetails
+	Credits  TMDBCreditsResponse   `json:"credits"`
+	Videos   TMDBVideosResponse    `json:"videos"`
+	Keywords *TMDBKeywordsResponse `json:"keywords,omitempty"`
+}
+
+// TMDBKeywordsResponse represents the keywords (tags) of a movie
+type TMDBKeywordsResponse struct {
+	ID       int           `json:"id"`
+	Keywords []TMDBKeyword `json:"keywords"`
+}
+
+// TMDBKeyword represents a single TMDB keyword
+type TMDBKeyword struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TMDBVideosResponse represents the videos (trailers, teasers, etc.) of a movie
+type TMDBVideosResponse struct {
+	Results []TMDBVideo `json:"results"`
+}
+
+// TMDBVideo represents a single video entry
+type TMDBVideo struct {
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Site     string `json:"site"`
+	Type     string `json:"type"`
+	Official bool   `json:"official"`
+}
+
+// TMDBAlternativeTitlesResponse represents the alternative titles of a movie
+type TMDBAlternativeTitlesResponse struct {
+	ID     int                    `json:"id"`
+	Titles []TMDBAlternativeTitle `json:"titles"`
+}
+
+// TMDBAlternativeTitle represents a single country-specific title
+type TMDBAlternativeTitle struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+}
@@ -0,0 +1,35 @@
+package retry
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+// LocalBackoff is the first wait between attempts in RetryLocal; it doubles
+// after each failure
+const LocalBackoff = 250 * time.Millisecond
+
+// RetryLocal runs fn, a local filesystem write, up to retries+1 times. Unlike
+// Retry it ignores network error classes: any failure is retried after a short
+// doubling backoff, for hiccups on network mounts (NFS, SMB), except
+// permission errors, which never heal on their own. The last error is
+// returned as is.
+func RetryLocal(fn func() error, retries int) error {
+	return RetryLocalWithLogger(fn, retries, slog.Default())
+}
+
+// RetryLocalWithLogger is RetryLocal, logging each retry to logger
+func RetryLocalWithLogger(fn func() error, retries int, logger *slog.Logger) error {
+	backoff := LocalBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || errors.Is(err, fs.ErrPermission) {
+			return err
+		}
+		logger.Warn("local write failed, retrying", "attempt", attempt+1, "retries", retries, "wait", backoff, "error", err)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,55 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestRetryLocal(t *testing.T) {
+	var sleeps []time.Duration
+	sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleep = time.Sleep }()
+
+	errIO := errors.New("input/output error")
+	testCases := []struct {
+		name      string
+		retries   int
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", 2, 0, errIO, 1, false},
+		{"recovers", 2, 2, errIO, 3, false},
+		{"exhausted", 2, 5, errIO, 3, true},
+		{"disabled", 0, 1, errIO, 1, true},
+		{"permission not retried", 2, 5, fmt.Errorf("write: %w", fs.ErrPermission), 1, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sleeps = nil
+			calls := 0
+			err := RetryLocal(func() error {
+				calls++
+				if calls <= tc.failures {
+					return tc.err
+				}
+				return nil
+			}, tc.retries)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("RetryLocal() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tc.wantCalls)
+			}
+			if len(sleeps) > 1 && sleeps[1] != 2*sleeps[0] {
+				t.Errorf("backoff should double, got %v", sleeps)
+			}
+		})
+	}
+}
@@ -2,21 +2,62 @@ package retry
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"net/url"
 	"strings"
 	"time"
 )
 
+// DefaultMaxBackoff caps the exponential backoff used by Retry, and by
+// RetryWithOptions when Options.MaxBackoff is not set
+const DefaultMaxBackoff = 30 * time.Second
+
+// sleep is replaced in tests to observe backoff durations
+var sleep = time.Sleep
+
+// capBackoff limits d to maxBackoff
+func capBackoff(d, maxBackoff time.Duration) time.Duration {
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// ExhaustedError is returned when every allowed attempt failed with a
+// retryable error. It records how many times fn was called and whether any
+// attempt was rate limited, and unwraps to the last error.
+type ExhaustedError struct {
+	Attempts    int
+	RateLimited bool
+	Err         error
+}
+
+func (e *ExhaustedError) Error() string {
+	attempts := "attempts"
+	if e.Attempts == 1 {
+		attempts = "attempt"
+	}
+	if e.RateLimited {
+		return fmt.Sprintf("retries exhausted after %d %s (rate-limited): %v", e.Attempts, attempts, e.Err)
+	}
+	return fmt.Sprintf("retries exhausted after %d %s: %v", e.Attempts, attempts, e.Err)
+}
+
+func (e *ExhaustedError) Unwrap() error { return e.Err }
+
 // Retry executes fn with exponential backoff until it succeeds or maxAttempts is reached.
-// The backoff doubles after each failed attempt starting from initialBackoff.
+// The backoff doubles after each failed attempt starting from initialBackoff, and
+// never exceeds DefaultMaxBackoff.
 // Non-retryable errors (like 401, 404) return immediately without retry.
+// When all attempts fail, the last error is wrapped in an *ExhaustedError.
 func Retry(fn func() error, maxAttempts int, initialBackoff time.Duration) error {
 	if maxAttempts <= 0 {
 		maxAttempts = 1
 	}
 
 	var lastErr error
+	rateLimited := false
 	backoff := initialBackoff
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
@@ -29,20 +70,121 @@ func Retry(fn func() error, maxAttempts int, initialBackoff time.Duration) error
 		if !IsRetryable(lastErr) && !IsRateLimited(lastErr) {
 			return lastErr
 		}
+		if IsRateLimited(lastErr) {
+			rateLimited = true
+		}
 
 		// Don't sleep after the last attempt
 		if attempt < maxAttempts {
 			// Use longer backoff for rate limited errors
 			sleepDuration := backoff
 			if IsRateLimited(lastErr) {
-				sleepDuration = backoff * 2
+				sleepDuration = capBackoff(backoff*2, DefaultMaxBackoff)
 			}
-			time.Sleep(sleepDuration)
-			backoff *= 2
+			sleep(sleepDuration)
+			backoff = capBackoff(backoff*2, DefaultMaxBackoff)
 		}
 	}
 
-	return lastErr
+	return &ExhaustedError{Attempts: maxAttempts, RateLimited: rateLimited, Err: lastErr}
+}
+
+// RateLimitError represents an HTTP 429 response. RetryAfter carries the
+// server's Retry-After hint, or zero when the header was absent.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string { return e.Err.Error() }
+func (e *RateLimitError) Unwrap() error { return e.Err }
+
+// Options configures RetryWithOptions.
+type Options struct {
+	MaxAttempts         int           // Attempts for transient errors (timeouts, 5xx)
+	MaxRateLimitRetries int           // Extra retries for 429s, not counted against MaxAttempts
+	InitialBackoff      time.Duration // First backoff; doubles after each transient failure
+	MaxBackoff          time.Duration // Upper bound for computed backoffs (default: DefaultMaxBackoff)
+
+	// OnRetry is called before sleeping. For rate-limit retries, attempt and
+	// maxAttempts refer to the rate-limit budget rather than MaxAttempts.
+	// When the budget is exhausted it is called once more with wait 0, attempt
+	// set to the total number of calls, and err an *ExhaustedError.
+	OnRetry func(attempt int, maxAttempts int, wait time.Duration, err error)
+}
+
+// RetryWithOptions executes fn like Retry, but handles rate limiting (HTTP 429)
+// separately: a 429 does not consume one of MaxAttempts. Instead it draws from
+// MaxRateLimitRetries and waits for the server's Retry-After when provided
+// (see RateLimitError), otherwise for a doubling rate-limit backoff. Both
+// backoffs stop growing at MaxBackoff, and a longer Retry-After is cut to
+// MaxBackoff so a server asking for hours cannot stall a scan.
+// When either budget runs out, the last error is wrapped in an *ExhaustedError.
+func RetryWithOptions(fn func() error, opts Options) error {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+
+	attempt := 0
+	calls := 0
+	rateLimitRetries := 0
+	backoff := capBackoff(opts.InitialBackoff, opts.MaxBackoff)
+	rateLimitBackoff := capBackoff(opts.InitialBackoff*2, opts.MaxBackoff)
+
+	exhausted := func(err error) error {
+		exhaustedErr := &ExhaustedError{Attempts: calls, RateLimited: rateLimitRetries > 0 || IsRateLimited(err), Err: err}
+		if opts.OnRetry != nil {
+			opts.OnRetry(calls, calls, 0, exhaustedErr)
+		}
+		return exhaustedErr
+	}
+
+	for {
+		err := fn()
+		calls++
+		if err == nil {
+			return nil
+		}
+
+		if IsRateLimited(err) {
+			if rateLimitRetries >= opts.MaxRateLimitRetries {
+				return exhausted(err)
+			}
+			rateLimitRetries++
+
+			wait := rateLimitBackoff
+			var rlErr *RateLimitError
+			if errors.As(err, &rlErr) && rlErr.RetryAfter > 0 {
+				wait = capBackoff(rlErr.RetryAfter, opts.MaxBackoff)
+			} else {
+				rateLimitBackoff = capBackoff(rateLimitBackoff*2, opts.MaxBackoff)
+			}
+			if opts.OnRetry != nil {
+				opts.OnRetry(rateLimitRetries, opts.MaxRateLimitRetries, wait, err)
+			}
+			sleep(wait)
+			continue
+		}
+
+		attempt++
+
+		// Don't retry non-retryable errors
+		if !IsRetryable(err) {
+			return err
+		}
+		if attempt >= opts.MaxAttempts {
+			return exhausted(err)
+		}
+
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt, opts.MaxAttempts, backoff, err)
+		}
+		sleep(backoff)
+		backoff = capBackoff(backoff*2, opts.MaxBackoff)
+	}
 }
 
 // IsRetryable returns true if the error is a transient error that should be retried.
@@ -96,6 +238,11 @@ func IsRateLimited(err error) bool {
 		return false
 	}
 
+	var rlErr *RateLimitError
+	if errors.As(err, &rlErr) {
+		return true
+	}
+
 	errStr := err.Error()
 	return strings.Contains(errStr, "status 429")
 }
@@ -0,0 +1,227 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryWithOptions_RateLimitDoesNotConsumeAttempts(t *testing.T) {
+	calls := 0
+	err := RetryWithOptions(func() error {
+		calls++
+		if calls <= 3 {
+			return &RateLimitError{Err: errors.New("TMDB API error (status 429)")}
+		}
+		return nil
+	}, Options{
+		MaxAttempts:         1,
+		MaxRateLimitRetries: 3,
+		InitialBackoff:      time.Millisecond,
+	})
+
+	if err != nil {
+		t.Fatalf("expected success after rate limits, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestRetryWithOptions_RateLimitBudgetExhausted(t *testing.T) {
+	calls := 0
+	err := RetryWithOptions(func() error {
+		calls++
+		return fmt.Errorf("TMDB API error (status 429): slow down")
+	}, Options{
+		MaxAttempts:         3,
+		MaxRateLimitRetries: 2,
+		InitialBackoff:      time.Millisecond,
+	})
+
+	if !IsRateLimited(err) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls (1 + 2 rate-limit retries), got %d", calls)
+	}
+}
+
+func TestRetryWithOptions_HonorsRetryAfter(t *testing.T) {
+	var waits []time.Duration
+	calls := 0
+	_ = RetryWithOptions(func() error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{RetryAfter: 2 * time.Millisecond, Err: errors.New("status 429")}
+		}
+		return nil
+	}, Options{
+		MaxAttempts:         1,
+		MaxRateLimitRetries: 1,
+		InitialBackoff:      time.Hour, // would hang if Retry-After were ignored
+		OnRetry: func(attempt int, maxAttempts int, wait time.Duration, err error) {
+			waits = append(waits, wait)
+		},
+	})
+
+	if len(waits) != 1 || waits[0] != 2*time.Millisecond {
+		t.Errorf("expected a single 2ms wait, got %v", waits)
+	}
+}
+
+func TestRetryWithOptions_CapsRetryAfter(t *testing.T) {
+	var sleeps []time.Duration
+	sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleep = time.Sleep }()
+
+	calls := 0
+	err := RetryWithOptions(func() error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{RetryAfter: 24 * time.Hour, Err: errors.New("status 429")}
+		}
+		return nil
+	}, Options{
+		MaxAttempts:         1,
+		MaxRateLimitRetries: 1,
+		InitialBackoff:      time.Second,
+		MaxBackoff:          30 * time.Second,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sleeps) != 1 || sleeps[0] != 30*time.Second {
+		t.Errorf("expected a single 30s wait, got %v", sleeps)
+	}
+}
+
+func TestRetryWithOptions_TransientErrorsUseMaxAttempts(t *testing.T) {
+	calls := 0
+	err := RetryWithOptions(func() error {
+		calls++
+		return errors.New("TMDB API error (status 503)")
+	}, Options{
+		MaxAttempts:         3,
+		MaxRateLimitRetries: 5,
+		InitialBackoff:      time.Millisecond,
+	})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryWithOptions_ExhaustedError(t *testing.T) {
+	var finalErr error
+	calls := 0
+	err := RetryWithOptions(func() error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{Err: errors.New("TMDB API error (status 429)")}
+		}
+		return errors.New("TMDB API error (status 503)")
+	}, Options{
+		MaxAttempts:         2,
+		MaxRateLimitRetries: 2,
+		InitialBackoff:      time.Millisecond,
+		OnRetry: func(attempt int, maxAttempts int, wait time.Duration, err error) {
+			finalErr = err
+		},
+	})
+
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("expected ExhaustedError, got %v", err)
+	}
+	if exhausted.Attempts != 3 || !exhausted.RateLimited {
+		t.Errorf("unexpected exhaustion details: %+v", exhausted)
+	}
+	if want := "retries exhausted after 3 attempts (rate-limited): TMDB API error (status 503)"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+	if finalErr != err {
+		t.Errorf("expected final OnRetry call with the exhausted error, got %v", finalErr)
+	}
+}
+
+func TestExhaustedError_Error(t *testing.T) {
+	testCases := []struct {
+		err      ExhaustedError
+		expected string
+	}{
+		{ExhaustedError{Attempts: 1, Err: errors.New("timeout")}, "retries exhausted after 1 attempt: timeout"},
+		{ExhaustedError{Attempts: 1, RateLimited: true, Err: errors.New("status 429")}, "retries exhausted after 1 attempt (rate-limited): status 429"},
+		{ExhaustedError{Attempts: 4, Err: errors.New("timeout")}, "retries exhausted after 4 attempts: timeout"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.err.Error(); got != tc.expected {
+			t.Errorf("Error() = %q, want %q", got, tc.expected)
+		}
+	}
+}
+
+func TestRetry_NonRetryableNotWrapped(t *testing.T) {
+	notFound := errors.New("TMDB API error (status 404)")
+	err := Retry(func() error { return notFound }, 3, time.Millisecond)
+
+	if err != notFound {
+		t.Errorf("expected non-retryable error returned as-is, got %v", err)
+	}
+}
+
+func TestBackoffNeverExceedsMaxBackoff(t *testing.T) {
+	var sleeps []time.Duration
+	sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleep = time.Sleep }()
+
+	const maxBackoff = 5 * time.Second
+	serverErr := errors.New("TMDB API error (status 500)")
+	rateLimitErr := errors.New("TMDB API error (status 429)")
+
+	testCases := []struct {
+		name       string
+		run        func() error
+		maxBackoff time.Duration
+	}{
+		{"transient errors", func() error {
+			return RetryWithOptions(func() error { return serverErr }, Options{
+				MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: maxBackoff,
+			})
+		}, maxBackoff},
+		{"rate limited", func() error {
+			return RetryWithOptions(func() error { return rateLimitErr }, Options{
+				MaxAttempts: 1, MaxRateLimitRetries: 10, InitialBackoff: time.Second, MaxBackoff: maxBackoff,
+			})
+		}, maxBackoff},
+		{"Retry uses the default cap", func() error {
+			return Retry(func() error { return rateLimitErr }, 12, time.Second)
+		}, DefaultMaxBackoff},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sleeps = nil
+			if err := tc.run(); err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(sleeps) == 0 {
+				t.Fatal("expected at least one sleep")
+			}
+			for i, d := range sleeps {
+				if d > tc.maxBackoff {
+					t.Errorf("sleep %d = %v, exceeds cap %v", i, d, tc.maxBackoff)
+				}
+			}
+			if last := sleeps[len(sleeps)-1]; last != tc.maxBackoff {
+				t.Errorf("expected backoff to reach the cap, last sleep = %v", last)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -16,6 +17,12 @@ var (
 	resolutionExtractPattern = regexp.MustCompile(`(?i)\b(2160p|1080p|1080i|720p|720i|480p|4K)\b`)
 	// Source quality patterns for quality extraction
 	sourceExtractPattern = regexp.MustCompile(`(?i)\b(BluRay|BRRip|BDRip|WEB-DL|WEBRip|HDRip|DVDRip|HDTV|WEB|CAM|TS|TC|DVDSCR|R5|SCREENER)\b`)
+	// Remux marker; takes precedence over the disc source it was ripped from
+	remuxExtractPattern = regexp.MustCompile(`(?i)\bREMUX\b`)
+	// Stereoscopic 3D markers, including half/full side-by-side and over-under
+	threeDExtractPattern = regexp.MustCompile(`(?i)\b(3D|H-?SBS|Half-SBS|F-?SBS|H-?OU|Half-OU)\b`)
+	// Re-release markers for fixed encodes of the same release
+	properExtractPattern = regexp.MustCompile(`(?i)\b(PROPER|REPACK)\b`)
 )
 
 // Resolution quality ranking (higher is better)
@@ -32,27 +39,28 @@ var resolutionRank = map[string]int{
 
 // Source quality ranking (higher is better)
 var sourceRank = map[string]int{
-	"bluray":  8,
-	"bdrip":   7,
-	"brrip":   7,
-	"web-dl":  6,
-	"webrip":  5,
-	"hdrip":   4,
-	"hdtv":    4,
-	"dvdrip":  3,
-	"dvdscr":  2,
+	"remux":    9, // Untouched disc streams, above a re-encoded BluRay rip
+	"bluray":   8,
+	"bdrip":    7,
+	"brrip":    7,
+	"web-dl":   6,
+	"webrip":   5,
+	"hdrip":    4,
+	"hdtv":     4,
+	"dvdrip":   3,
+	"dvdscr":   2,
 	"screener": 2,
-	"r5":      2,
-	"ts":      1,
-	"tc":      1,
-	"cam":     0,
-	"":        -1, // unknown
+	"r5":       2,
+	"ts":       1,
+	"tc":       1,
+	"cam":      0,
+	"":         -1, // unknown
 }
 
 // DuplicateSet represents a group of movies that are duplicates of each other
 type DuplicateSet struct {
-	Key     string         // The grouping key (TMDB ID or title+year)
-	KeyType string         // "tmdb_id" or "title_year"
+	Key     string         // The grouping key (TMDB ID, title+year, or file hash)
+	KeyType string         // "tmdb_id", "title_year", or "file_hash"
 	Movies  []DuplicateMovie
 }
 
@@ -65,13 +73,25 @@ type DuplicateMovie struct {
 	FileName    string
 	Slug        string
 	MDXPath     string
+	FileHash    string // Video fingerprint from options.compute_file_hash, if recorded
+	DiscNumber  int    // Disc/part number from the filename (0 = not a multi-disc file)
 	// Quality fields (US-025)
 	Resolution     string // e.g., "1080p", "2160p", "720p"
 	Source         string // e.g., "BluRay", "WEB-DL", "HDRip"
+	Is3D           bool   // Stereoscopic 3D release; not part of QualityScore
+	IsProper       bool   // PROPER or REPACK release; wins ties on QualityScore
 	QualityScore   int    // Combined quality score for ranking
 	IsRecommended  bool   // True if this is the recommended copy to keep
+	Status         string // DuplicateStatusRecommended, DuplicateStatusKeep, or DuplicateStatusRemovable
 }
 
+// Duplicate copy states assigned by quality ranking
+const (
+	DuplicateStatusRecommended = "recommended" // Best copy in the set
+	DuplicateStatusKeep        = "keep"        // Within the top N copies, but not the best
+	DuplicateStatusRemovable   = "removable"   // Lower quality than the top N copies
+)
+
 // mdxFrontmatter represents the YAML frontmatter structure in MDX files
 type mdxFrontmatter struct {
 	Title       string `yaml:"title"`
@@ -80,17 +100,35 @@ type mdxFrontmatter struct {
 	TMDBID      int    `yaml:"tmdbId"`
 	FilePath    string `yaml:"filePath"`
 	FileName    string `yaml:"fileName"`
+	FileHash    string `yaml:"fileHash"`
 }
 
 // DuplicateFinder handles finding duplicate movies in the library
 type DuplicateFinder struct {
-	mdxDir string
+	mdxDir  string
+	keepTop int
+}
+
+// DuplicateFinderOptions configures a DuplicateFinder
+type DuplicateFinderOptions struct {
+	// KeepTop is the number of highest-quality copies to keep per set; the
+	// rest are marked removable. Values below 1 are treated as 1.
+	KeepTop int
 }
 
 // NewDuplicateFinder creates a new DuplicateFinder instance
 func NewDuplicateFinder(mdxDir string) *DuplicateFinder {
+	return NewDuplicateFinderWithOptions(mdxDir, DuplicateFinderOptions{KeepTop: 1})
+}
+
+// NewDuplicateFinderWithOptions creates a new DuplicateFinder with the given options
+func NewDuplicateFinderWithOptions(mdxDir string, opts DuplicateFinderOptions) *DuplicateFinder {
+	if opts.KeepTop < 1 {
+		opts.KeepTop = 1
+	}
 	return &DuplicateFinder{
-		mdxDir: mdxDir,
+		mdxDir:  mdxDir,
+		keepTop: opts.KeepTop,
 	}
 }
 
@@ -112,11 +150,14 @@ func (df *DuplicateFinder) FindDuplicates() ([]DuplicateSet, error) {
 			tmdbGroups[movie.TMDBID] = append(tmdbGroups[movie.TMDBID], movie)
 		} else {
 			// Create key from lowercase title + year for matching
-			key := fmt.Sprintf("%s|%d", strings.ToLower(movie.Title), movie.ReleaseYear)
+			key := titleYearKey(movie.Title, movie.ReleaseYear)
 			titleYearGroups[key] = append(titleYearGroups[key], movie)
 		}
 	}
 
+	// Fold unmatched copies into the TMDB ID group they belong to
+	reconcileTitleYearGroups(tmdbGroups, titleYearGroups)
+
 	// Build duplicate sets (only groups with more than 1 movie)
 	var duplicates []DuplicateSet
 
@@ -124,7 +165,8 @@ func (df *DuplicateFinder) FindDuplicates() ([]DuplicateSet, error) {
 	for tmdbID, movieList := range tmdbGroups {
 		if len(movieList) > 1 {
 			// Mark recommended copy (US-025)
-			markRecommended(movieList)
+			sortDuplicateMovies(movieList)
+			markRecommended(movieList, df.keepTop)
 			duplicates = append(duplicates, DuplicateSet{
 				Key:     fmt.Sprintf("%d", tmdbID),
 				KeyType: "tmdb_id",
@@ -137,7 +179,8 @@ func (df *DuplicateFinder) FindDuplicates() ([]DuplicateSet, error) {
 	for key, movieList := range titleYearGroups {
 		if len(movieList) > 1 {
 			// Mark recommended copy (US-025)
-			markRecommended(movieList)
+			sortDuplicateMovies(movieList)
+			markRecommended(movieList, df.keepTop)
 			duplicates = append(duplicates, DuplicateSet{
 				Key:     key,
 				KeyType: "title_year",
@@ -146,28 +189,170 @@ func (df *DuplicateFinder) FindDuplicates() ([]DuplicateSet, error) {
 		}
 	}
 
+	// Byte-identical copies not already reported together, e.g. when one
+	// copy was matched to the wrong movie
+	duplicates = append(duplicates, identicalFileSets(movies, duplicates, df.keepTop)...)
+
+	sortDuplicateSets(duplicates)
 	return duplicates, nil
 }
 
-// markRecommended marks the highest quality copy as recommended (US-025)
-func markRecommended(movies []DuplicateMovie) {
-	if len(movies) == 0 {
+// identicalFileSets groups movies with the same fileHash into "file_hash"
+// sets. Groups whose copies all sit in one of the existing sets are skipped,
+// since they are already reported.
+func identicalFileSets(movies []DuplicateMovie, existing []DuplicateSet, keepTop int) []DuplicateSet {
+	setIndex := make(map[string]int)
+	for i, set := range existing {
+		for _, movie := range set.Movies {
+			setIndex[movie.MDXPath] = i
+		}
+	}
+
+	hashGroups := make(map[string][]DuplicateMovie)
+	for _, movie := range movies {
+		if movie.FileHash != "" {
+			hashGroups[movie.FileHash] = append(hashGroups[movie.FileHash], movie)
+		}
+	}
+
+	var sets []DuplicateSet
+	for hash, movieList := range hashGroups {
+		if len(movieList) < 2 || inOneSet(movieList, setIndex) {
+			continue
+		}
+		sortDuplicateMovies(movieList)
+		markRecommended(movieList, keepTop)
+		sets = append(sets, DuplicateSet{
+			Key:     hash,
+			KeyType: "file_hash",
+			Movies:  movieList,
+		})
+	}
+	return sets
+}
+
+// inOneSet reports whether every movie is already in the same duplicate set
+func inOneSet(movies []DuplicateMovie, setIndex map[string]int) bool {
+	first, ok := setIndex[movies[0].MDXPath]
+	if !ok {
+		return false
+	}
+	for _, movie := range movies[1:] {
+		if index, ok := setIndex[movie.MDXPath]; !ok || index != first {
+			return false
+		}
+	}
+	return true
+}
+
+// sortDuplicateMovies orders the copies in a set by quality score (highest
+// first), PROPER/REPACK before other releases, then file name and path, so
+// reports and exports are reproducible between runs
+func sortDuplicateMovies(movies []DuplicateMovie) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		a, b := movies[i], movies[j]
+		if a.QualityScore != b.QualityScore {
+			return a.QualityScore > b.QualityScore
+		}
+		if a.IsProper != b.IsProper {
+			return a.IsProper
+		}
+		if a.FileName != b.FileName {
+			return a.FileName < b.FileName
+		}
+		return a.FilePath < b.FilePath
+	})
+}
+
+// sortDuplicateSets orders duplicate sets by title (case-insensitive), then
+// release year, then grouping key. Sets are titled by their first copy, so
+// sortDuplicateMovies must run first.
+func sortDuplicateSets(sets []DuplicateSet) {
+	sort.SliceStable(sets, func(i, j int) bool {
+		a, b := sets[i], sets[j]
+		titleA, titleB := strings.ToLower(a.Movies[0].Title), strings.ToLower(b.Movies[0].Title)
+		if titleA != titleB {
+			return titleA < titleB
+		}
+		if a.Movies[0].ReleaseYear != b.Movies[0].ReleaseYear {
+			return a.Movies[0].ReleaseYear < b.Movies[0].ReleaseYear
+		}
+		return a.Key < b.Key
+	})
+}
+
+// titleYearKey builds the grouping key used for movies without a TMDB ID
+func titleYearKey(title string, year int) string {
+	return fmt.Sprintf("%s|%d", strings.ToLower(strings.TrimSpace(title)), year)
+}
+
+// reconcileTitleYearGroups cross-references title+year groups against TMDB ID
+// groups. When a title+year key resolves to exactly one TMDB ID (because a
+// matched copy has the same title and year), the unmatched copies are merged
+// into that TMDB ID group and the title+year group is removed. Keys that
+// resolve to several TMDB IDs are left alone since the match is ambiguous.
+func reconcileTitleYearGroups(tmdbGroups map[int][]DuplicateMovie, titleYearGroups map[string][]DuplicateMovie) {
+	if len(tmdbGroups) == 0 || len(titleYearGroups) == 0 {
 		return
 	}
 
-	// Find the movie with highest quality score
-	bestIdx := 0
-	bestScore := movies[0].QualityScore
+	// Resolve each title+year key to the TMDB IDs whose movies carry it
+	resolved := make(map[string]map[int]bool)
+	for tmdbID, movieList := range tmdbGroups {
+		for _, movie := range movieList {
+			key := titleYearKey(movie.Title, movie.ReleaseYear)
+			if resolved[key] == nil {
+				resolved[key] = make(map[int]bool)
+			}
+			resolved[key][tmdbID] = true
+		}
+	}
 
-	for i := 1; i < len(movies); i++ {
-		if movies[i].QualityScore > bestScore {
-			bestScore = movies[i].QualityScore
-			bestIdx = i
+	for key, movieList := range titleYearGroups {
+		ids := resolved[key]
+		if len(ids) != 1 {
+			continue
+		}
+		for tmdbID := range ids {
+			tmdbGroups[tmdbID] = append(tmdbGroups[tmdbID], movieList...)
 		}
+		delete(titleYearGroups, key)
 	}
+}
 
-	// Mark the best one
-	movies[bestIdx].IsRecommended = true
+// markRecommended marks the highest quality copy as recommended (US-025).
+// The next keepTop-1 copies by quality are marked keep and the rest removable.
+// PROPER/REPACK releases win ties; remaining ties keep their original order,
+// so the first of equally scored copies wins.
+func markRecommended(movies []DuplicateMovie, keepTop int) {
+	if len(movies) == 0 {
+		return
+	}
+
+	// Rank copies by quality score, highest first
+	ranked := make([]int, len(movies))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		ma, mb := movies[ranked[a]], movies[ranked[b]]
+		if ma.QualityScore != mb.QualityScore {
+			return ma.QualityScore > mb.QualityScore
+		}
+		return ma.IsProper && !mb.IsProper
+	})
+
+	for rank, idx := range ranked {
+		switch {
+		case rank == 0:
+			movies[idx].IsRecommended = true
+			movies[idx].Status = DuplicateStatusRecommended
+		case rank < keepTop:
+			movies[idx].Status = DuplicateStatusKeep
+		default:
+			movies[idx].Status = DuplicateStatusRemovable
+		}
+	}
 }
 
 // readAllMDXFiles reads all MDX files in the directory and extracts frontmatter
@@ -227,7 +412,8 @@ func (df *DuplicateFinder) parseMDXFile(mdxPath string) (DuplicateMovie, error)
 	}
 
 	// Extract quality info from filename (US-025)
-	resolution, source := extractQualityInfo(fm.FileName)
+	resolution, source := ExtractQualityInfo(fm.FileName)
+	is3D, isProper := ExtractReleaseFlags(fm.FileName)
 	qualityScore := calculateQualityScore(resolution, source)
 
 	return DuplicateMovie{
@@ -236,15 +422,19 @@ func (df *DuplicateFinder) parseMDXFile(mdxPath string) (DuplicateMovie, error)
 		TMDBID:       fm.TMDBID,
 		FilePath:     fm.FilePath,
 		FileName:     fm.FileName,
+		FileHash:     fm.FileHash,
+		DiscNumber:   ExtractDiscNumber(fm.FileName),
 		Slug:         fm.Slug,
 		Resolution:   resolution,
 		Source:       source,
+		Is3D:         is3D,
+		IsProper:     isProper,
 		QualityScore: qualityScore,
 	}, nil
 }
 
-// extractQualityInfo extracts resolution and source quality from a filename (US-025)
-func extractQualityInfo(filename string) (resolution string, source string) {
+// ExtractQualityInfo extracts resolution and source quality from a filename (US-025)
+func ExtractQualityInfo(filename string) (resolution string, source string) {
 	// Extract resolution
 	if match := resolutionExtractPattern.FindString(filename); match != "" {
 		resolution = strings.ToLower(match)
@@ -254,21 +444,30 @@ func extractQualityInfo(filename string) (resolution string, source string) {
 		}
 	}
 
-	// Extract source quality
-	if match := sourceExtractPattern.FindString(filename); match != "" {
+	// Extract source quality; a REMUX outranks the BluRay tag it usually comes with
+	if match := remuxExtractPattern.FindString(filename); match != "" {
 		source = match // Preserve original case for display
+	} else if match := sourceExtractPattern.FindString(filename); match != "" {
+		source = match
 	}
 
 	return resolution, source
 }
 
+// ExtractReleaseFlags reports whether a filename marks a 3D release and a
+// PROPER/REPACK re-release. Neither affects the quality score, so 3D copies
+// can be preferred or avoided independently of resolution and source.
+func ExtractReleaseFlags(filename string) (is3D bool, isProper bool) {
+	return threeDExtractPattern.MatchString(filename), properExtractPattern.MatchString(filename)
+}
+
 // calculateQualityScore computes a combined quality score (US-025)
 // Higher score = better quality
 func calculateQualityScore(resolution, source string) int {
 	// Get resolution rank (0-4)
 	resRank := resolutionRank[strings.ToLower(resolution)]
 
-	// Get source rank (0-8)
+	// Get source rank (0-9)
 	srcRank := sourceRank[strings.ToLower(source)]
 	if srcRank < 0 {
 		srcRank = 0 // Treat unknown as 0 for scoring
@@ -294,9 +493,12 @@ func PrintDuplicateReport(duplicates []DuplicateSet, detailed bool) {
 		fmt.Printf("━━━ Duplicate Set %d ━━━\n", i+1)
 
 		// Print grouping info
-		if set.KeyType == "tmdb_id" {
+		switch set.KeyType {
+		case "tmdb_id":
 			fmt.Printf("TMDB ID: %s\n", set.Key)
-		} else {
+		case "file_hash":
+			fmt.Printf("Identical files (fileHash: %s)\n", set.Key)
+		default:
 			parts := strings.Split(set.Key, "|")
 			if len(parts) == 2 {
 				fmt.Printf("Title: %s, Year: %s\n", parts[0], parts[1])
@@ -309,14 +511,19 @@ func PrintDuplicateReport(duplicates []DuplicateSet, detailed bool) {
 		for j, movie := range set.Movies {
 			// Show recommendation marker (US-025)
 			recommendMarker := ""
-			if movie.IsRecommended {
+			switch movie.Status {
+			case DuplicateStatusRecommended:
 				recommendMarker = " ★ RECOMMENDED"
+			case DuplicateStatusKeep:
+				recommendMarker = " ✓ KEEP"
+			case DuplicateStatusRemovable:
+				recommendMarker = " ✗ REMOVABLE"
 			}
 			fmt.Printf("  [%d] %s (%d)%s\n", j+1, movie.Title, movie.ReleaseYear, recommendMarker)
 			fmt.Printf("      File: %s\n", movie.FileName)
 
 			// Show quality info (US-025)
-			qualityStr := formatQualityString(movie.Resolution, movie.Source)
+			qualityStr := formatQualityString(movie.Resolution, movie.Source, movie.Is3D, movie.IsProper)
 			if qualityStr != "" {
 				fmt.Printf("      Quality: %s\n", qualityStr)
 			}
@@ -327,6 +534,8 @@ func PrintDuplicateReport(duplicates []DuplicateSet, detailed bool) {
 				fmt.Printf("      Slug: %s\n", movie.Slug)
 				fmt.Printf("      Resolution: %s (rank: %d)\n", displayResolution(movie.Resolution), resolutionRank[strings.ToLower(movie.Resolution)])
 				fmt.Printf("      Source: %s (rank: %d)\n", displaySource(movie.Source), sourceRank[strings.ToLower(movie.Source)])
+				fmt.Printf("      3D: %s\n", yesNo(movie.Is3D))
+				fmt.Printf("      Proper/Repack: %s (wins ties)\n", yesNo(movie.IsProper))
 				fmt.Printf("      Quality Score: %d\n", movie.QualityScore)
 			} else {
 				fmt.Printf("      Path: %s\n", movie.FilePath)
@@ -341,8 +550,9 @@ func PrintDuplicateReport(duplicates []DuplicateSet, detailed bool) {
 	}
 }
 
-// formatQualityString creates a display string for resolution and source (US-025)
-func formatQualityString(resolution, source string) string {
+// formatQualityString creates a display string for resolution, source, and
+// release flags (US-025)
+func formatQualityString(resolution, source string, is3D, isProper bool) string {
 	parts := []string{}
 	if resolution != "" {
 		parts = append(parts, strings.ToUpper(resolution))
@@ -350,6 +560,12 @@ func formatQualityString(resolution, source string) string {
 	if source != "" {
 		parts = append(parts, source)
 	}
+	if is3D {
+		parts = append(parts, "3D")
+	}
+	if isProper {
+		parts = append(parts, "PROPER")
+	}
 	if len(parts) == 0 {
 		return "Unknown"
 	}
@@ -364,6 +580,14 @@ func displayResolution(resolution string) string {
 	return strings.ToUpper(resolution)
 }
 
+// yesNo returns a display string for a boolean flag
+func yesNo(flag bool) string {
+	if flag {
+		return "yes"
+	}
+	return "no"
+}
+
 // displaySource returns a display string for source (US-025)
 func displaySource(source string) string {
 	if source == "" {
@@ -0,0 +1,209 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestMDX(t *testing.T, dir, slug, title string, year, tmdbID int, fileName string) {
+	t.Helper()
+	content := fmt.Sprintf("---\ntitle: %q\nslug: %q\nreleaseYear: %d\ntmdbId: %d\nfilePath: %q\nfileName: %q\n---\n",
+		title, slug, year, tmdbID, "/movies/"+fileName, fileName)
+	if err := os.WriteFile(filepath.Join(dir, slug+".mdx"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write MDX: %v", err)
+	}
+}
+
+func TestFindDuplicates_ReconcilesUnmatchedCopies(t *testing.T) {
+	dir := t.TempDir()
+	writeTestMDX(t, dir, "inception-2010", "Inception", 2010, 27205, "Inception.2010.1080p.BluRay.mkv")
+	writeTestMDX(t, dir, "inception-2010-2", "Inception", 2010, 0, "Inception.2010.720p.WEB-DL.mkv")
+	writeTestMDX(t, dir, "heat-1995", "Heat", 1995, 949, "Heat.1995.mkv")
+	writeTestMDX(t, dir, "unmatched-2001", "Unmatched", 2001, 0, "Unmatched.2001.mkv")
+
+	duplicates, err := NewDuplicateFinder(dir).FindDuplicates()
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+
+	if len(duplicates) != 1 {
+		t.Fatalf("expected 1 duplicate set, got %d", len(duplicates))
+	}
+	set := duplicates[0]
+	if set.KeyType != "tmdb_id" || set.Key != "27205" {
+		t.Errorf("expected tmdb_id set 27205, got %s %s", set.KeyType, set.Key)
+	}
+	if len(set.Movies) != 2 {
+		t.Errorf("expected 2 movies in set, got %d", len(set.Movies))
+	}
+	for _, movie := range set.Movies {
+		if movie.IsRecommended && movie.Resolution != "1080p" {
+			t.Errorf("expected 1080p copy to be recommended, got %s", movie.FileName)
+		}
+	}
+}
+
+func TestFindDuplicates_AmbiguousTitleYearNotReconciled(t *testing.T) {
+	dir := t.TempDir()
+	writeTestMDX(t, dir, "solaris-a", "Solaris", 2002, 100, "Solaris.A.mkv")
+	writeTestMDX(t, dir, "solaris-b", "Solaris", 2002, 200, "Solaris.B.mkv")
+	writeTestMDX(t, dir, "solaris-c", "Solaris", 2002, 0, "Solaris.C.mkv")
+
+	duplicates, err := NewDuplicateFinder(dir).FindDuplicates()
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+
+	if len(duplicates) != 0 {
+		t.Errorf("expected no duplicate sets for ambiguous title, got %d", len(duplicates))
+	}
+}
+
+func TestMarkRecommended_KeepTop(t *testing.T) {
+	testCases := []struct {
+		keepTop  int
+		expected []string
+	}{
+		{1, []string{DuplicateStatusRemovable, DuplicateStatusRecommended, DuplicateStatusRemovable}},
+		{2, []string{DuplicateStatusKeep, DuplicateStatusRecommended, DuplicateStatusRemovable}},
+		{5, []string{DuplicateStatusKeep, DuplicateStatusRecommended, DuplicateStatusKeep}},
+	}
+
+	for _, tc := range testCases {
+		movies := []DuplicateMovie{
+			{FileName: "1080p", QualityScore: 38},
+			{FileName: "2160p", QualityScore: 46},
+			{FileName: "720p", QualityScore: 25},
+		}
+		markRecommended(movies, tc.keepTop)
+
+		for i, movie := range movies {
+			if movie.Status != tc.expected[i] {
+				t.Errorf("keepTop=%d: %s status = %q, want %q", tc.keepTop, movie.FileName, movie.Status, tc.expected[i])
+			}
+			if movie.IsRecommended != (movie.Status == DuplicateStatusRecommended) {
+				t.Errorf("keepTop=%d: %s IsRecommended = %v inconsistent with status %q", tc.keepTop, movie.FileName, movie.IsRecommended, movie.Status)
+			}
+		}
+	}
+}
+
+func TestExtractQualityInfo_ReleaseTags(t *testing.T) {
+	testCases := []struct {
+		filename   string
+		source     string
+		is3D       bool
+		isProper   bool
+		wantBetter string // filename expected to outscore this one, if any
+	}{
+		{"Avatar.2009.1080p.BluRay.REMUX.AVC.mkv", "REMUX", false, false, ""},
+		{"Avatar.2009.1080p.BluRay.x264.mkv", "BluRay", false, false, "Avatar.2009.1080p.BluRay.REMUX.AVC.mkv"},
+		{"Avatar.2009.3D.1080p.BluRay.Half-SBS.mkv", "BluRay", true, false, ""},
+		{"Avatar.2009.1080p.WEB-DL.PROPER.mkv", "WEB-DL", false, true, ""},
+		{"Avatar.2009.1080p.WEB-DL.REPACK.mkv", "WEB-DL", false, true, ""},
+	}
+
+	for _, tc := range testCases {
+		resolution, source := ExtractQualityInfo(tc.filename)
+		if source != tc.source {
+			t.Errorf("ExtractQualityInfo(%q) source = %q, want %q", tc.filename, source, tc.source)
+		}
+		is3D, isProper := ExtractReleaseFlags(tc.filename)
+		if is3D != tc.is3D || isProper != tc.isProper {
+			t.Errorf("ExtractReleaseFlags(%q) = %v, %v; want %v, %v", tc.filename, is3D, isProper, tc.is3D, tc.isProper)
+		}
+		if tc.wantBetter != "" {
+			betterRes, betterSrc := ExtractQualityInfo(tc.wantBetter)
+			if calculateQualityScore(betterRes, betterSrc) <= calculateQualityScore(resolution, source) {
+				t.Errorf("expected %q to outscore %q", tc.wantBetter, tc.filename)
+			}
+		}
+	}
+}
+
+func TestMarkRecommended_ProperWinsTies(t *testing.T) {
+	movies := []DuplicateMovie{
+		{FileName: "original", QualityScore: 36},
+		{FileName: "repack", QualityScore: 36, IsProper: true},
+	}
+	markRecommended(movies, 1)
+	if !movies[1].IsRecommended {
+		t.Errorf("expected the REPACK copy to be recommended, got statuses %q, %q", movies[0].Status, movies[1].Status)
+	}
+}
+
+func TestFindDuplicates_StableOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeTestMDX(t, dir, "zodiac-a", "Zodiac", 2007, 1949, "Zodiac.2007.720p.mkv")
+	writeTestMDX(t, dir, "zodiac-b", "Zodiac", 2007, 1949, "Zodiac.2007.1080p.mkv")
+	writeTestMDX(t, dir, "alien-b", "Alien", 1979, 348, "Alien.1979.b.mkv")
+	writeTestMDX(t, dir, "alien-a", "Alien", 1979, 348, "Alien.1979.a.mkv")
+	writeTestMDX(t, dir, "heat-a", "heat", 1995, 0, "Heat.A.mkv")
+	writeTestMDX(t, dir, "heat-b", "heat", 1995, 0, "Heat.B.mkv")
+
+	for run := 0; run < 5; run++ {
+		duplicates, err := NewDuplicateFinder(dir).FindDuplicates()
+		if err != nil {
+			t.Fatalf("FindDuplicates failed: %v", err)
+		}
+
+		var got []string
+		for _, set := range duplicates {
+			for _, movie := range set.Movies {
+				got = append(got, movie.FileName)
+			}
+		}
+		want := []string{
+			"Alien.1979.a.mkv", "Alien.1979.b.mkv",
+			"Heat.A.mkv", "Heat.B.mkv",
+			"Zodiac.2007.1080p.mkv", "Zodiac.2007.720p.mkv",
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("run %d: order = %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestFindDuplicates_IdenticalFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestMDX(t, dir, "heat-1995", "Heat", 1995, 949, "Heat.1995.1080p.mkv")
+	writeTestMDX(t, dir, "heat-1986", "Heat", 1986, 31000, "Heat.Copy.mkv")
+	writeTestMDX(t, dir, "inception-2010", "Inception", 2010, 27205, "Inception.2010.mkv")
+	writeTestMDX(t, dir, "inception-2010-2", "Inception", 2010, 27205, "Inception.Copy.mkv")
+	writeTestMDX(t, dir, "solaris-2002", "Solaris", 2002, 100, "Solaris.mkv")
+	for slug, hash := range map[string]string{
+		"heat-1995":        "aaa",
+		"heat-1986":        "aaa",
+		"inception-2010":   "bbb",
+		"inception-2010-2": "bbb",
+	} {
+		path := filepath.Join(dir, slug+".mdx")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = append([]byte("---\nfileHash: "+hash), content[3:]...)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates, err := NewDuplicateFinder(dir).FindDuplicates()
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+
+	// Identical Inception copies are already grouped by TMDB ID; the Heat
+	// copies matched to different movies are only caught by their hash
+	if len(duplicates) != 2 {
+		t.Fatalf("expected 2 duplicate sets, got %d: %+v", len(duplicates), duplicates)
+	}
+	if set := duplicates[0]; set.KeyType != "file_hash" || set.Key != "aaa" || len(set.Movies) != 2 {
+		t.Errorf("first set = %s %s with %d movies, want file_hash aaa with 2", set.KeyType, set.Key, len(set.Movies))
+	}
+	if set := duplicates[1]; set.KeyType != "tmdb_id" || set.Key != "27205" {
+		t.Errorf("second set = %s %s, want tmdb_id 27205", set.KeyType, set.Key)
+	}
+}
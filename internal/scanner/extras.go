@@ -0,0 +1,201 @@
+package scanner
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Extras handling modes for the scanner.extras_handling option
+const (
+	ExtrasInclude = "include" // Scan every video as a movie
+	ExtrasSkip    = "skip"    // Skip trailers, featurettes, and other bonus videos
+)
+
+// extraSuffixPattern matches the Plex/Jellyfin extras suffixes, e.g.
+// "The Matrix (1999)-featurette.mkv" or "Heat - trailer.mp4"
+var extraSuffixPattern = regexp.MustCompile(`(?i)\s*-\s*(featurette|behindthescenes|deleted|deletedscenes?|interview|scene|short|trailer|teaser|sample|other|extra)$`)
+
+// extraNamePattern matches files that are named only after their extra type,
+// e.g. "behindthescenes.mkv", "deleted-scenes.mkv", "Trailer 2.mp4"
+var extraNamePattern = regexp.MustCompile(`(?i)^(behind[\s._-]?the[\s._-]?scenes|deleted[\s._-]?scenes?|featurettes?|interviews?|trailers?|teasers?|sample|extras?|making[\s._-]?of|bonus)([\s._-]*\d+)?$`)
+
+// extraDirNames are folder names whose videos are always extras
+var extraDirNames = map[string]bool{
+	"extras":            true,
+	"featurettes":       true,
+	"behind the scenes": true,
+	"deleted scenes":    true,
+	"interviews":        true,
+	"trailers":          true,
+	"samples":           true,
+}
+
+// SkippedExtra records a video that FilterExtras treated as bonus material
+type SkippedExtra struct {
+	FileName string
+	Reason   string // "name" (extras naming convention or folder) or "not_primary"
+	KeptFile string // FileName of the primary feature, when one was chosen
+}
+
+// IsExtraFile reports whether a video path follows a common extras naming
+// convention: a "-trailer"/"-featurette" style suffix, a name made of only the
+// extra type (e.g. "deleted-scenes.mkv"), or a parent folder such as "Extras"
+func IsExtraFile(path string) bool {
+	if extraDirNames[strings.ToLower(filepath.Base(filepath.Dir(path)))] {
+		return true
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return extraNamePattern.MatchString(name) || extraSuffixPattern.MatchString(name)
+}
+
+// FilterExtras removes bonus videos so only the main feature of each movie
+// folder is scanned. A file is an extra when it follows an extras naming
+// convention, or when it shares a movie folder (not a configured root or a
+// boxset) with other videos and is not the primary feature: the video whose
+// title matches the folder, or the largest one. Multi-disc files are left to
+// FilterMultiDiscDuplicates. Original order is preserved.
+func FilterExtras(files []FileInfo) ([]FileInfo, []SkippedExtra) {
+	byDir := make(map[string][]FileInfo)
+	for _, f := range files {
+		dir := filepath.Dir(f.Path)
+		byDir[dir] = append(byDir[dir], f)
+	}
+
+	extras := make(map[string]SkippedExtra) // keyed by Path
+	for dir, group := range byDir {
+		isRoot := len(group) > 0 && filepath.Clean(group[0].SourceDir) == filepath.Clean(dir)
+		for path, skip := range selectExtras(dir, group, isRoot) {
+			extras[path] = skip
+		}
+	}
+
+	if len(extras) == 0 {
+		return files, nil
+	}
+
+	var result []FileInfo
+	var skipped []SkippedExtra
+	for _, f := range files {
+		if skip, ok := extras[f.Path]; ok {
+			skipped = append(skipped, skip)
+			continue
+		}
+		result = append(result, f)
+	}
+	return result, skipped
+}
+
+// IsExtra reports whether file is bonus material, looking at its siblings on
+// the local disk. Used by watch mode, which processes files one at a time;
+// roots are the configured scan directories, whose videos are never compared
+// by folder.
+func IsExtra(file FileInfo, extensions []string, roots []string) bool {
+	return isExtra(OSFileSystem{}, file, extensions, roots)
+}
+
+// IsExtra is the package-level IsExtra, listing siblings through the
+// scanner's FileSystem
+func (s *Scanner) IsExtra(file FileInfo, extensions []string, roots []string) bool {
+	return isExtra(s.fs, file, extensions, roots)
+}
+
+// isExtra implements IsExtra on top of fsys
+func isExtra(fsys FileSystem, file FileInfo, extensions []string, roots []string) bool {
+	if IsExtraFile(file.Path) {
+		return true
+	}
+
+	dir := filepath.Dir(file.Path)
+	for _, root := range roots {
+		if filepath.Clean(root) == filepath.Clean(dir) {
+			return false
+		}
+	}
+
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	group := []FileInfo{file}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == file.FileName || !hasExtension(entry.Name(), extensions) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		title, year := ExtractTitleAndYear(entry.Name())
+		group = append(group, FileInfo{
+			Path:       filepath.Join(dir, entry.Name()),
+			FileName:   entry.Name(),
+			Title:      title,
+			Year:       year,
+			Size:       info.Size(),
+			DiscNumber: ExtractDiscNumber(entry.Name()),
+		})
+	}
+
+	_, extra := selectExtras(dir, group, false)[file.Path]
+	return extra
+}
+
+// selectExtras returns the extras among the videos of one directory, keyed by path
+func selectExtras(dir string, group []FileInfo, isRoot bool) map[string]SkippedExtra {
+	extras := make(map[string]SkippedExtra)
+	var candidates []FileInfo
+	for _, f := range group {
+		if IsExtraFile(f.Path) {
+			extras[f.Path] = SkippedExtra{FileName: f.FileName, Reason: "name"}
+		} else if f.DiscNumber == 0 {
+			candidates = append(candidates, f)
+		}
+	}
+
+	// Flat libraries and boxsets legitimately hold several movies per folder
+	dirName := filepath.Base(dir)
+	if isRoot || len(candidates) < 2 || IsBoxsetDir(dirName) {
+		return extras
+	}
+	// Only folders named after a single movie, e.g. "The Matrix (1999)"
+	// (the extension keeps dots in the folder name from being stripped as one)
+	folderTitle, folderYear := ExtractTitleAndYear(dirName + ".dir")
+	if folderYear == 0 {
+		return extras
+	}
+
+	primary := primaryFeature(candidates, normalizeTitle(folderTitle))
+	for _, f := range candidates {
+		if f.Path == primary.Path || normalizeTitle(f.Title) == normalizeTitle(primary.Title) {
+			continue
+		}
+		extras[f.Path] = SkippedExtra{FileName: f.FileName, Reason: "not_primary", KeptFile: primary.FileName}
+	}
+	return extras
+}
+
+// primaryFeature picks the main movie among candidates: the largest video whose
+// title matches the folder title, or the largest video overall
+func primaryFeature(candidates []FileInfo, folderTitle string) FileInfo {
+	sorted := append([]FileInfo(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Size > sorted[j].Size })
+	for _, f := range sorted {
+		if normalizeTitle(f.Title) == folderTitle {
+			return f
+		}
+	}
+	return sorted[0]
+}
+
+// hasExtension reports whether filename has one of extensions (case-insensitive)
+func hasExtension(filename string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range extensions {
+		if ext == strings.ToLower(e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsExtraFile(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"/movies/The Matrix (1999)/The Matrix (1999)-featurette.mkv", true},
+		{"/movies/Heat (1995)/Heat - trailer.mp4", true},
+		{"/movies/Heat (1995)/behindthescenes.mkv", true},
+		{"/movies/Heat (1995)/deleted-scenes.mkv", true},
+		{"/movies/Heat (1995)/Trailer 2.mp4", true},
+		{"/movies/Heat (1995)/Extras/Interview with Michael Mann.mkv", true},
+		{"/movies/Heat (1995)/Heat (1995).mkv", false},
+		{"/movies/The Other Side of the Wind (2018).mkv", false},
+		{"/movies/Shorts/Paperman (2012).mkv", false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsExtraFile(tc.path); got != tc.expected {
+			t.Errorf("IsExtraFile(%q) = %v, want %v", tc.path, got, tc.expected)
+		}
+	}
+}
+
+func TestFilterExtras(t *testing.T) {
+	file := func(path string, size int64) FileInfo {
+		title, year := ExtractTitleAndYear(filepath.Base(path))
+		return FileInfo{Path: path, FileName: filepath.Base(path), Title: title, Year: year, Size: size, SourceDir: "/movies"}
+	}
+
+	testCases := []struct {
+		name     string
+		files    []FileInfo
+		expected []string
+	}{
+		{
+			"named extras skipped",
+			[]FileInfo{file("/movies/Heat (1995)/Heat (1995).mkv", 100), file("/movies/Heat (1995)/Heat-trailer.mkv", 5)},
+			[]string{"Heat (1995).mkv"},
+		},
+		{
+			"folder title match beats size",
+			[]FileInfo{file("/movies/Heat (1995)/Making Heat.mkv", 900), file("/movies/Heat (1995)/Heat.1995.1080p.mkv", 500)},
+			[]string{"Heat.1995.1080p.mkv"},
+		},
+		{
+			"largest wins without a title match",
+			[]FileInfo{file("/movies/Heat (1995)/clip.mkv", 10), file("/movies/Heat (1995)/feature.mkv", 800)},
+			[]string{"feature.mkv"},
+		},
+		{
+			"flat root keeps every movie",
+			[]FileInfo{file("/movies/Heat (1995).mkv", 100), file("/movies/Alien (1979).mkv", 5)},
+			[]string{"Heat (1995).mkv", "Alien (1979).mkv"},
+		},
+		{
+			"boxset keeps every movie",
+			[]FileInfo{file("/movies/Alien Collection (1979)/Alien (1979).mkv", 100), file("/movies/Alien Collection (1979)/Aliens (1986).mkv", 5)},
+			[]string{"Alien (1979).mkv", "Aliens (1986).mkv"},
+		},
+		{
+			"same movie in two qualities is kept",
+			[]FileInfo{file("/movies/Heat (1995)/Heat (1995) 1080p.mkv", 100), file("/movies/Heat (1995)/Heat (1995) 720p.mkv", 50)},
+			[]string{"Heat (1995) 1080p.mkv", "Heat (1995) 720p.mkv"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kept, _ := FilterExtras(tc.files)
+			var names []string
+			for _, f := range kept {
+				names = append(names, f.FileName)
+			}
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("kept %v, want %v", names, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrFFprobeNotFound is returned by ProbeMediaInfo when ffprobe is not on PATH
+var ErrFFprobeNotFound = errors.New("ffprobe not found in PATH")
+
+// ffprobeTimeout bounds a single ffprobe run, which only reads the container
+// header; a file on a stale network mount would otherwise block forever
+const ffprobeTimeout = 30 * time.Second
+
+// MediaInfo is the technical description of a video file. Codec names follow
+// ffprobe's (e.g. "hevc", "h264", "truehd"); empty or zero fields are unknown.
+type MediaInfo struct {
+	Duration   time.Duration `json:"duration"`
+	Resolution string        `json:"resolution"` // e.g. "1080p", as in filenames
+	VideoCodec string        `json:"videoCodec"`
+	AudioCodec string        `json:"audioCodec"`
+	Channels   int           `json:"channels"` // Of the first audio stream
+}
+
+// ProbeMediaInfo reads the duration and first video and audio streams of a
+// file with ffprobe. Returns ErrFFprobeNotFound when ffprobe is not installed.
+func ProbeMediaInfo(path string) (*MediaInfo, error) {
+	ffprobe, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return nil, ErrFFprobeNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ffprobeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ffprobe,
+		"-v", "error",
+		"-show_entries", "format=duration:stream=codec_type,codec_name,width,height,channels:stream_disposition=attached_pic",
+		"-of", "json",
+		path,
+	)
+	// A process stuck in uninterruptible I/O may outlive the kill; stop
+	// waiting for its output shortly after
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("ffprobe timed out after %s for %s: %w", ffprobeTimeout, path, ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed for %s: %w", path, err)
+	}
+	return parseFFprobeOutput(out)
+}
+
+// ffprobeOutput is the subset of ffprobe's JSON output ProbeMediaInfo requests
+type ffprobeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType   string `json:"codec_type"`
+		CodecName   string `json:"codec_name"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		Channels    int    `json:"channels"`
+		Disposition struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+}
+
+// parseFFprobeOutput builds a MediaInfo from ffprobe's JSON output. Embedded
+// cover art, which ffprobe lists as a video stream, is skipped.
+func parseFFprobeOutput(out []byte) (*MediaInfo, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("failed to decode ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{}
+	if seconds, err := strconv.ParseFloat(strings.TrimSpace(probe.Format.Duration), 64); err == nil && seconds > 0 {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, s := range probe.Streams {
+		switch {
+		case s.CodecType == "video" && s.Disposition.AttachedPic == 0 && info.VideoCodec == "":
+			info.VideoCodec = s.CodecName
+			info.Resolution = resolutionLabel(s.Width, s.Height)
+		case s.CodecType == "audio" && info.AudioCodec == "":
+			info.AudioCodec = s.CodecName
+			info.Channels = s.Channels
+		}
+	}
+	if info.Duration == 0 && info.VideoCodec == "" {
+		return nil, fmt.Errorf("ffprobe found no duration or video stream")
+	}
+	return info, nil
+}
+
+// resolutionLabel names a frame size the way release filenames do. Width
+// decides first so letterboxed encodes (1920x800) still count as 1080p.
+func resolutionLabel(width, height int) string {
+	switch {
+	case width >= 3200 || height >= 2000:
+		return "2160p"
+	case width >= 1800 || height >= 1000:
+		return "1080p"
+	case width >= 1200 || height >= 700:
+		return "720p"
+	case height > 0:
+		return fmt.Sprintf("%dp", height)
+	}
+	return ""
+}
+
+var (
+	videoCodecExtractPattern = regexp.MustCompile(`(?i)\b(x264|x265|H\.?264|H\.?265|HEVC|AVC|XviD|DivX)\b`)
+	audioCodecExtractPattern = regexp.MustCompile(`(?i)\b(DTS-HD|DTS|TrueHD|AAC|AC3|E-?AC3|DDP|DD|FLAC|MP3)(?:\d|\b)`)
+	channelsExtractPattern   = regexp.MustCompile(`(?:^|\D)([1-7])\.([01])(?:\D|$)`)
+)
+
+// ExtractMediaInfo guesses technical information from release tags in a
+// filename, for when ffprobe is not available. Codec names are normalized to
+// ffprobe's so both sources read the same. Duration is always unknown.
+func ExtractMediaInfo(filename string) *MediaInfo {
+	info := &MediaInfo{}
+	info.Resolution, _ = ExtractQualityInfo(filename)
+
+	switch codec := strings.ToLower(videoCodecExtractPattern.FindString(filename)); codec {
+	case "x264", "h264", "h.264", "avc":
+		info.VideoCodec = "h264"
+	case "x265", "h265", "h.265", "hevc":
+		info.VideoCodec = "hevc"
+	case "xvid", "divx":
+		info.VideoCodec = "mpeg4"
+	}
+
+	var audio string
+	if m := audioCodecExtractPattern.FindStringSubmatch(filename); m != nil {
+		audio = strings.ToLower(m[1])
+	}
+	switch audio {
+	case "dts-hd", "dts":
+		info.AudioCodec = "dts"
+	case "dd", "ac3":
+		info.AudioCodec = "ac3"
+	case "ddp", "eac3", "e-ac3":
+		info.AudioCodec = "eac3"
+	case "":
+	default:
+		info.AudioCodec = audio
+	}
+
+	if m := channelsExtractPattern.FindStringSubmatch(filename); m != nil {
+		main, _ := strconv.Atoi(m[1])
+		lfe, _ := strconv.Atoi(m[2])
+		info.Channels = main + lfe
+	}
+	return info
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFFprobeOutput(t *testing.T) {
+	out := []byte(`{
+		"streams": [
+			{"codec_name": "mjpeg", "codec_type": "video", "width": 600, "height": 900, "disposition": {"attached_pic": 1}},
+			{"codec_name": "hevc", "codec_type": "video", "width": 1920, "height": 800, "disposition": {"attached_pic": 0}},
+			{"codec_name": "truehd", "codec_type": "audio", "channels": 8, "disposition": {"attached_pic": 0}},
+			{"codec_name": "ac3", "codec_type": "audio", "channels": 6, "disposition": {"attached_pic": 0}}
+		],
+		"format": {"duration": "8160.042000"}
+	}`)
+
+	info, err := parseFFprobeOutput(out)
+	if err != nil {
+		t.Fatalf("parseFFprobeOutput failed: %v", err)
+	}
+	want := MediaInfo{
+		Duration:   8160042 * time.Millisecond,
+		Resolution: "1080p",
+		VideoCodec: "hevc",
+		AudioCodec: "truehd",
+		Channels:   8,
+	}
+	if *info != want {
+		t.Errorf("parseFFprobeOutput = %+v, want %+v", *info, want)
+	}
+
+	for _, bad := range []string{`not json`, `{"streams": [], "format": {"duration": "N/A"}}`} {
+		if _, err := parseFFprobeOutput([]byte(bad)); err == nil {
+			t.Errorf("parseFFprobeOutput(%q) returned no error", bad)
+		}
+	}
+}
+
+func TestResolutionLabel(t *testing.T) {
+	testCases := []struct {
+		width, height int
+		want          string
+	}{
+		{3840, 2160, "2160p"},
+		{3840, 1600, "2160p"},
+		{1920, 1080, "1080p"},
+		{1920, 800, "1080p"},
+		{1280, 720, "720p"},
+		{720, 576, "576p"},
+		{0, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		if got := resolutionLabel(tc.width, tc.height); got != tc.want {
+			t.Errorf("resolutionLabel(%d, %d) = %q, want %q", tc.width, tc.height, got, tc.want)
+		}
+	}
+}
+
+func TestExtractMediaInfo(t *testing.T) {
+	testCases := []struct {
+		filename string
+		want     MediaInfo
+	}{
+		{"Heat.1995.1080p.BluRay.x264.DTS-HD.MA.5.1-GRP.mkv", MediaInfo{Resolution: "1080p", VideoCodec: "h264", AudioCodec: "dts", Channels: 6}},
+		{"Dune.2021.2160p.WEB-DL.DDP5.1.Atmos.HEVC-GRP.mkv", MediaInfo{Resolution: "2160p", VideoCodec: "hevc", AudioCodec: "eac3", Channels: 6}},
+		{"Alien (1979) [720p] AAC 2.0.mp4", MediaInfo{Resolution: "720p", AudioCodec: "aac", Channels: 2}},
+		{"Aachen Stories (2001).avi", MediaInfo{}},
+	}
+
+	for _, tc := range testCases {
+		if got := ExtractMediaInfo(tc.filename); *got != tc.want {
+			t.Errorf("ExtractMediaInfo(%q) = %+v, want %+v", tc.filename, *got, tc.want)
+		}
+	}
+}
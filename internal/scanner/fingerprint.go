@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// fingerprintChunk is how much FileFingerprint reads from the start and from
+// the end of a file
+const fingerprintChunk = 64 * 1024
+
+// FileFingerprint returns a fast fingerprint of a video file: the hex SHA-256
+// of its size and its first and last 64KB. Byte-identical copies always share
+// a fingerprint and different videos practically never do, without reading
+// whole multi-gigabyte files. path is read from the local disk; see
+// (*Scanner).FileFingerprint for the scanner's FileSystem.
+func FileFingerprint(path string) (string, error) {
+	return fileFingerprint(OSFileSystem{}, path)
+}
+
+// FileFingerprint is the package-level FileFingerprint read through the
+// scanner's FileSystem, which must implement FileOpener and return files
+// that support random access (io.ReaderAt)
+func (s *Scanner) FileFingerprint(path string) (string, error) {
+	return fileFingerprint(s.fs, path)
+}
+
+// fileFingerprint implements FileFingerprint on top of fsys
+func fileFingerprint(fsys FileSystem, path string) (string, error) {
+	opener, ok := fsys.(FileOpener)
+	if !ok {
+		return "", errors.New("filesystem cannot open files")
+	}
+	file, err := opener.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	f, ok := file.(io.ReaderAt)
+	if !ok {
+		return "", fmt.Errorf("failed to read %s: filesystem does not support random access", path)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", size)
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, fingerprintChunk)); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if size > fingerprintChunk {
+		if _, err := io.Copy(h, io.NewSectionReader(f, size-fingerprintChunk, fingerprintChunk)); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
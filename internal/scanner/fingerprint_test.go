@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	large := bytes.Repeat([]byte("0123456789abcdef"), 3*fingerprintChunk/16)
+	changedTail := append([]byte(nil), large...)
+	changedTail[len(changedTail)-1] = 'X'
+
+	files := map[string][]byte{
+		"Heat.1995.mkv":        large,
+		"copy/Heat (1995).mp4": large,
+		"tail.mkv":             changedTail,
+		"longer.mkv":           append(append([]byte(nil), large...), '!'),
+		"small.mkv":            []byte("tiny"),
+		"small-copy.mkv":       []byte("tiny"),
+	}
+	sums := make(map[string]string)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		sum, err := FileFingerprint(path)
+		if err != nil {
+			t.Fatalf("FileFingerprint(%s) failed: %v", name, err)
+		}
+		sums[name] = sum
+	}
+
+	testCases := []struct {
+		a, b string
+		same bool
+	}{
+		{"Heat.1995.mkv", "copy/Heat (1995).mp4", true},
+		{"small.mkv", "small-copy.mkv", true},
+		{"Heat.1995.mkv", "tail.mkv", false},
+		{"Heat.1995.mkv", "longer.mkv", false},
+		{"Heat.1995.mkv", "small.mkv", false},
+	}
+	for _, tc := range testCases {
+		if same := sums[tc.a] == sums[tc.b]; same != tc.same {
+			t.Errorf("fingerprints of %s and %s equal = %v, want %v", tc.a, tc.b, same, tc.same)
+		}
+	}
+
+	if _, err := FileFingerprint(filepath.Join(dir, "missing.mkv")); err == nil {
+		t.Error("FileFingerprint of a missing file returned no error")
+	}
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem is the minimal filesystem the scanner needs. Paths are passed
+// through unchanged, so a backend decides how to interpret them (e.g. a
+// WebDAV or SFTP backend can map them onto a remote root). Implementations
+// must be safe for concurrent use.
+type FileSystem interface {
+	// Stat returns file info for the named path
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir returns the entries of the named directory sorted by filename
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// ReadFile returns the contents of the named file
+	ReadFile(name string) ([]byte, error)
+}
+
+// FileOpener is implemented by FileSystems that can open a file for reading.
+// The scanner uses it to probe that a video is readable; backends without it
+// are only checked for zero-byte files.
+type FileOpener interface {
+	Open(name string) (fs.File, error)
+}
+
+// OSFileSystem is the default FileSystem backed by the local disk
+type OSFileSystem struct{}
+
+// Stat implements FileSystem using os.Stat
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadDir implements FileSystem using os.ReadDir
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// ReadFile implements FileSystem using os.ReadFile
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// Open implements FileOpener using os.Open
+func (OSFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// probeReadable reads the first byte of name when fsys can open files, so
+// permission and I/O errors surface before any metadata lookup
+func probeReadable(fsys FileSystem, name string) error {
+	opener, ok := fsys.(FileOpener)
+	if !ok {
+		return nil
+	}
+	f, err := opener.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Read(make([]byte, 1)); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// walkFileSystem walks the tree rooted at root like filepath.Walk, but through
+// fsys. walkFn is called for every file and directory in lexical order;
+// returning filepath.SkipDir from a directory skips its contents.
+func walkFileSystem(fsys FileSystem, root string, walkFn filepath.WalkFunc) error {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = walkPath(fsys, root, info, walkFn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkPath recursively descends path, calling walkFn
+func walkPath(fsys FileSystem, path string, info fs.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(path, info, nil)
+	}
+
+	entries, err := fsys.ReadDir(path)
+	err1 := walkFn(path, info, err)
+	// If err != nil, walk can't descend into this directory; walkFn decides
+	// whether that is fatal via err1
+	if err != nil || err1 != nil {
+		return err1
+	}
+
+	for _, entry := range entries {
+		filename := filepath.Join(path, entry.Name())
+		entryInfo, err := entry.Info()
+		if err != nil {
+			if err := walkFn(filename, entryInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := walkPath(fsys, filename, entryInfo, walkFn); err != nil {
+			if !entryInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,507 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/marco/movieVault/internal/writer"
+)
+
+// mapFileSystem adapts an in-memory fstest.MapFS to FileSystem, standing in
+// for a remote backend. Absolute paths are mapped onto the map's root.
+type mapFileSystem struct {
+	fsys fstest.MapFS
+}
+
+func (m mapFileSystem) name(path string) string {
+	name := strings.TrimPrefix(path, "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (m mapFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(m.fsys, m.name(name))
+}
+
+func (m mapFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(m.fsys, m.name(name))
+}
+
+func (m mapFileSystem) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(m.fsys, m.name(name))
+}
+
+func TestScanAll_CustomFileSystem(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"library/Inception.2010.1080p.mkv":     {Data: []byte("video")},
+		"library/Inception.2010.1080p.eng.srt": {},
+		"library/Sample/Heat.1995.mkv":         {Data: []byte("video")},
+		"library/Sub/Heat.1995.mkv":            {Data: []byte("video")},
+		"library/notes.txt":                    {},
+	}}
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx": {},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		ExcludeDirs:   []string{"sample"},
+		FileSystem:    media,
+		MDXFileSystem: mdx,
+	})
+
+	files, err := s.ScanAll([]string{"/library", "/missing"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d: %+v", len(files), files)
+	}
+
+	inception, heat := files[0], files[1]
+	if inception.Slug != "inception-2010" || !inception.ShouldScan || inception.Size != 5 {
+		t.Errorf("unexpected inception file info: %+v", inception)
+	}
+	if len(inception.Subtitles) != 1 || inception.Subtitles[0] != "eng" {
+		t.Errorf("expected eng subtitles, got %v", inception.Subtitles)
+	}
+	if heat.Path != "/library/Sub/Heat.1995.mkv" || heat.ShouldScan {
+		t.Errorf("expected existing MDX to disable scan for heat: %+v", heat)
+	}
+}
+
+func TestScanAll_DiscsKeptAcrossDirectories(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"disk1/Heat.1995.CD1.mkv": {Data: []byte("video")},
+		"disk2/Heat.1995.CD2.mkv": {Data: []byte("video")},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		FileSystem:    media,
+		MDXFileSystem: mapFileSystem{fstest.MapFS{}},
+	})
+
+	files, err := s.ScanAll([]string{"/disk1", "/disk2"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	// Discs in different directories are not filtered as duplicates
+	kept, skipped := FilterMultiDiscDuplicates(files)
+	if len(kept) != 2 || len(skipped) != 0 {
+		t.Fatalf("expected both discs kept, got kept=%+v skipped=%+v", kept, skipped)
+	}
+
+	// Each disc writes its own MDX instead of overwriting the other's
+	mdxDir := t.TempDir()
+	mdxWriter := writer.NewMDXWriter(mdxDir, t.TempDir())
+	for _, f := range kept {
+		movie := &writer.Movie{Title: f.Title, ReleaseYear: f.Year, Slug: f.Slug}
+		if err := mdxWriter.WriteMDXFile(movie); err != nil {
+			t.Fatalf("WriteMDXFile(%s) failed: %v", f.Slug, err)
+		}
+	}
+
+	for _, slug := range []string{"heat-1995", "heat-1995-cd2"} {
+		if !mdxWriter.MDXExists(slug) {
+			t.Errorf("expected MDX for %s", slug)
+		}
+	}
+}
+
+func TestScanAll_DirExtensions(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"anime/Akira.1988.mkv":    {Data: []byte("video")},
+		"anime/Akira.1988.avi":    {Data: []byte("video")},
+		"old/Metropolis.1927.avi": {Data: []byte("video")},
+		"old/Metropolis.1927.mpg": {Data: []byte("video")},
+		"old/Nosferatu.1922.mkv":  {Data: []byte("video")},
+		"other/Heat.1995.mkv":     {Data: []byte("video")},
+		"other/Heat.1995.mpg":     {Data: []byte("video")},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		FileSystem:    media,
+		MDXFileSystem: mapFileSystem{fstest.MapFS{}},
+		DirExtensions: map[string][]string{
+			"/old": {".avi", ".mpg"},
+		},
+	})
+
+	files, err := s.ScanAll([]string{"/anime", "/old", "/other"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, f := range files {
+		got[f.FileName] = true
+	}
+	want := []string{"Akira.1988.mkv", "Metropolis.1927.avi", "Metropolis.1927.mpg", "Heat.1995.mkv"}
+	if len(got) != len(want) {
+		t.Errorf("found %d files, want %d: %v", len(got), len(want), got)
+	}
+	for _, name := range want {
+		if !got[name] {
+			t.Errorf("expected %s to be scanned", name)
+		}
+	}
+}
+
+func TestScanAll_SkipsOutputDirs(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"library/Heat.1995.mkv":                      {Data: []byte("video")},
+		"library/website/content/Alien.1979.mkv":     {Data: []byte("video")},
+		"library/website/public/covers/Ran.1985.mkv": {Data: []byte("video")},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/library/website/content", Options{
+		FileSystem:    media,
+		MDXFileSystem: mapFileSystem{fstest.MapFS{}},
+		OutputDirs:    []string{"/library/website/public/covers"},
+	})
+
+	files, err := s.ScanAll([]string{"/library"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+	if len(files) != 1 || files[0].FileName != "Heat.1995.mkv" {
+		t.Errorf("expected only Heat.1995.mkv, got %+v", files)
+	}
+}
+
+// lockedFileSystem is a mapFileSystem whose files cannot be opened, like
+// videos without read permission
+type lockedFileSystem struct {
+	mapFileSystem
+	locked map[string]bool
+}
+
+func (l lockedFileSystem) Open(name string) (fs.File, error) {
+	if l.locked[name] {
+		return nil, fs.ErrPermission
+	}
+	return l.fsys.Open(l.name(name))
+}
+
+func TestScanAll_SkipsBrokenFiles(t *testing.T) {
+	media := lockedFileSystem{
+		mapFileSystem: mapFileSystem{fstest.MapFS{
+			"library/Heat.1995.mkv":      {Data: []byte("video")},
+			"library/Alien.1979.mkv":     {},
+			"library/Inception.2010.mkv": {Data: []byte("video")},
+		}},
+		locked: map[string]bool{"/library/Inception.2010.mkv": true},
+	}
+
+	testCases := []struct {
+		name          string
+		includeBroken bool
+		want          int
+	}{
+		{"skipped", false, 1},
+		{"included", true, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+				FileSystem:         media,
+				MDXFileSystem:      mapFileSystem{fstest.MapFS{}},
+				IncludeBrokenFiles: tc.includeBroken,
+			})
+			files, err := s.ScanAll([]string{"/library"})
+			if err != nil {
+				t.Fatalf("ScanAll failed: %v", err)
+			}
+			if len(files) != tc.want {
+				t.Errorf("expected %d files, got %d: %+v", tc.want, len(files), files)
+			}
+			if !tc.includeBroken && len(files) == 1 && files[0].FileName != "Heat.1995.mkv" {
+				t.Errorf("expected only Heat.1995.mkv, got %s", files[0].FileName)
+			}
+		})
+	}
+}
+
+// openCountingFileSystem is a lockedFileSystem that records which files were opened
+type openCountingFileSystem struct {
+	lockedFileSystem
+	opened map[string]bool
+}
+
+func (o openCountingFileSystem) Open(name string) (fs.File, error) {
+	o.opened[name] = true
+	return o.lockedFileSystem.Open(name)
+}
+
+func TestScanAll_ProbesOnlyFilesToScan(t *testing.T) {
+	media := openCountingFileSystem{
+		lockedFileSystem: lockedFileSystem{
+			mapFileSystem: mapFileSystem{fstest.MapFS{
+				"library/Heat.1995.mkv":      {Data: []byte("video")},
+				"library/Alien.1979.mkv":     {},
+				"library/Inception.2010.mkv": {Data: []byte("video")},
+				"library/Ran.1985.mkv":       {Data: []byte("video")},
+			}},
+			locked: map[string]bool{"/library/Inception.2010.mkv": true, "/library/Ran.1985.mkv": true},
+		},
+		opened: make(map[string]bool),
+	}
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx": {},
+		"mdx/ran-1985.mdx":  {},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		FileSystem:    media,
+		MDXFileSystem: mdx,
+	})
+	files, err := s.ScanAll([]string{"/library"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	// Heat and Ran have an MDX: kept without being opened, even though Ran is locked
+	if media.opened["/library/Heat.1995.mkv"] || media.opened["/library/Ran.1985.mkv"] {
+		t.Errorf("files with an MDX were opened: %v", media.opened)
+	}
+	if !media.opened["/library/Inception.2010.mkv"] {
+		t.Error("expected the file to scan to be probed")
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected heat and ran, got %+v", files)
+	}
+	if got := s.BrokenFiles(); got != 2 {
+		t.Errorf("BrokenFiles() = %d, want 2 (zero-byte alien, locked inception)", got)
+	}
+
+	// --force-refresh processes both; only then is Ran found unreadable
+	kept, dropped := s.FilterUnreadable(files)
+	if len(kept) != 1 || kept[0].FileName != "Heat.1995.mkv" || dropped != 1 {
+		t.Errorf("FilterUnreadable() = %+v, %d; want only Heat.1995.mkv, 1", kept, dropped)
+	}
+}
+
+func TestScanAll_Logger(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"library/Alien.1979.mkv": {},
+	}}
+
+	var logs bytes.Buffer
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		FileSystem:    media,
+		MDXFileSystem: mapFileSystem{fstest.MapFS{}},
+		Logger:        slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if _, err := s.ScanAll([]string{"/library", "/missing"}); err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	for _, want := range []string{"skipping zero-byte video file", "scan directory does not exist"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestScanAll_RefreshOlderThan(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"library/Heat.1995.mkv":      {Data: []byte("video")},
+		"library/Inception.2010.mkv": {Data: []byte("video")},
+		"library/Alien.1979.mkv":     {Data: []byte("video")},
+	}}
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx":      {Data: []byte("---\ntitle: Heat\nscannedAt: 2020-01-01T00:00:00Z\n---\n")},
+		"mdx/inception-2010.mdx": {Data: []byte("---\ntitle: Inception\nscannedAt: " + recent + "\n---\n")},
+		"mdx/alien-1979.mdx":     {Data: []byte("---\ntitle: Alien\n---\n")},
+	}}
+
+	testCases := []struct {
+		name    string
+		refresh time.Duration
+		want    map[string]bool // slug -> ShouldScan
+	}{
+		{"disabled", 0, map[string]bool{"heat-1995": false, "inception-2010": false, "alien-1979": false}},
+		{"thirty days", 30 * 24 * time.Hour, map[string]bool{"heat-1995": true, "inception-2010": false, "alien-1979": false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+				FileSystem:       media,
+				MDXFileSystem:    mdx,
+				RefreshOlderThan: tc.refresh,
+			})
+			files, err := s.ScanAll([]string{"/library"})
+			if err != nil {
+				t.Fatalf("ScanAll failed: %v", err)
+			}
+			for _, f := range files {
+				if f.ShouldScan != tc.want[f.Slug] {
+					t.Errorf("%s ShouldScan = %v, want %v", f.Slug, f.ShouldScan, tc.want[f.Slug])
+				}
+			}
+		})
+	}
+}
+
+func TestScanAll_RescanUnmatched(t *testing.T) {
+	media := mapFileSystem{fstest.MapFS{
+		"library/Heat.1995.mkv":         {Data: []byte("video")},
+		"library/Obscure.Film.2003.mkv": {Data: []byte("video")},
+	}}
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx":         {Data: []byte("---\ntitle: Heat\nscannedAt: 2020-01-01T00:00:00Z\n---\n")},
+		"mdx/obscure-film-2003.mdx": {Data: []byte("---\ntitle: Obscure Film\nscannedAt: 2020-01-01T00:00:00Z\nunmatched: true\n---\n")},
+	}}
+
+	testCases := []struct {
+		name    string
+		rescan  bool
+		refresh time.Duration
+		want    map[string]bool // slug -> ShouldScan
+	}{
+		{"disabled", false, 0, map[string]bool{"heat-1995": false, "obscure-film-2003": false}},
+		{"rescan unmatched", true, 0, map[string]bool{"heat-1995": false, "obscure-film-2003": true}},
+		{"with refresh", true, 30 * 24 * time.Hour, map[string]bool{"heat-1995": true, "obscure-film-2003": true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+				FileSystem:       media,
+				MDXFileSystem:    mdx,
+				RefreshOlderThan: tc.refresh,
+				RescanUnmatched:  tc.rescan,
+			})
+			files, err := s.ScanAll([]string{"/library"})
+			if err != nil {
+				t.Fatalf("ScanAll failed: %v", err)
+			}
+			for _, f := range files {
+				if f.ShouldScan != tc.want[f.Slug] {
+					t.Errorf("%s ShouldScan = %v, want %v", f.Slug, f.ShouldScan, tc.want[f.Slug])
+				}
+			}
+		})
+	}
+}
+
+func TestScanAll_TrackNFOChanges(t *testing.T) {
+	hash := func(data string) string {
+		sum := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(sum[:])
+	}
+	edited := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	media := mapFileSystem{fstest.MapFS{
+		"library/Heat/Heat.1995.mkv":     {Data: []byte("video")},
+		"library/Heat/movie.nfo":         {Data: []byte("<movie>Heat</movie>")},
+		"library/Alien/Alien.1979.mkv":   {Data: []byte("video")},
+		"library/Alien/Alien.1979.nfo":   {Data: []byte("<movie>Alien, fixed</movie>")},
+		"library/Ran/Ran.1985.mkv":       {Data: []byte("video")},
+		"library/Ran/movie.nfo":          {Data: []byte("<movie>Ran</movie>"), ModTime: edited},
+		"library/Brazil/Brazil.1985.mkv": {Data: []byte("video")},
+		"library/Brazil/movie.nfo":       {Data: []byte("<movie>Brazil</movie>"), ModTime: edited},
+		"library/Up/Up.2009.mkv":         {Data: []byte("video")},
+	}}
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx":   {Data: []byte("---\ntitle: Heat\nnfoHash: " + hash("<movie>Heat</movie>") + "\n---\n")},
+		"mdx/alien-1979.mdx":  {Data: []byte("---\ntitle: Alien\nnfoHash: " + hash("<movie>Alien</movie>") + "\n---\n")},
+		"mdx/ran-1985.mdx":    {Data: []byte("---\ntitle: Ran\nscannedAt: 2020-01-01T00:00:00Z\n---\n")},
+		"mdx/brazil-1985.mdx": {Data: []byte("---\ntitle: Brazil\nscannedAt: 2025-01-01T00:00:00Z\n---\n")},
+		"mdx/up-2009.mdx":     {Data: []byte("---\ntitle: Up\nscannedAt: 2020-01-01T00:00:00Z\n---\n")},
+	}}
+
+	all := func(string) bool { return true }
+	onlyRan := func(path string) bool { return strings.HasPrefix(path, "/library/Ran/") }
+
+	testCases := []struct {
+		name  string
+		track func(string) bool
+		want  map[string]bool // slug -> ShouldScan
+	}{
+		{"disabled", nil, map[string]bool{}},
+		{"tracked", all, map[string]bool{"alien-1979": true, "ran-1985": true}},
+		{"tracked for some paths", onlyRan, map[string]bool{"ran-1985": true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+				FileSystem:      media,
+				MDXFileSystem:   mdx,
+				TrackNFOChanges: tc.track,
+			})
+			files, err := s.ScanAll([]string{"/library"})
+			if err != nil {
+				t.Fatalf("ScanAll failed: %v", err)
+			}
+			if len(files) != 5 {
+				t.Fatalf("expected 5 files, got %d", len(files))
+			}
+			for _, f := range files {
+				if f.ShouldScan != tc.want[f.Slug] {
+					t.Errorf("%s ShouldScan = %v, want %v", f.Slug, f.ShouldScan, tc.want[f.Slug])
+				}
+			}
+		})
+	}
+}
+
+// openableFileSystem is a mapFileSystem that can open files for reading
+type openableFileSystem struct {
+	mapFileSystem
+}
+
+func (o openableFileSystem) Open(name string) (fs.File, error) {
+	return o.fsys.Open(o.name(name))
+}
+
+func TestScanner_FileSystemSiblingsAndFingerprint(t *testing.T) {
+	feature := bytes.Repeat([]byte("feature"), 20000)
+	media := openableFileSystem{mapFileSystem{fstest.MapFS{
+		"movies/Heat (1995)/Heat.1995.mkv":               {Data: feature},
+		"movies/Heat (1995)/Los.Angeles.Tour.mkv":        {Data: []byte("extra")},
+		"movies/Kill Bill (2003)/Kill.Bill.2003.CD1.mkv": {Data: []byte("disc one")},
+		"movies/Kill Bill (2003)/Kill.Bill.2003.CD2.mkv": {Data: []byte("disc two")},
+	}}}
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{FileSystem: media, MDXFileSystem: mapFileSystem{fstest.MapFS{}}})
+	extensions, roots := []string{".mkv"}, []string{"/movies"}
+
+	tour := FileInfo{Path: "/movies/Heat (1995)/Los.Angeles.Tour.mkv", FileName: "Los.Angeles.Tour.mkv", Title: "Los Angeles Tour", Size: 5}
+	heat := FileInfo{Path: "/movies/Heat (1995)/Heat.1995.mkv", FileName: "Heat.1995.mkv", Title: "Heat", Year: 1995, Size: int64(len(feature))}
+	if !s.IsExtra(tour, extensions, roots) || s.IsExtra(heat, extensions, roots) {
+		t.Error("IsExtra did not list the directory through the scanner's FileSystem")
+	}
+
+	disc2 := FileInfo{Path: "/movies/Kill Bill (2003)/Kill.Bill.2003.CD2.mkv", FileName: "Kill.Bill.2003.CD2.mkv", Title: "Kill Bill", Year: 2003, DiscNumber: 2}
+	if !s.PrimarySiblingExists(disc2, extensions) {
+		t.Error("PrimarySiblingExists did not find disc 1 through the scanner's FileSystem")
+	}
+
+	h := sha256.New()
+	h.Write([]byte("140000\n"))
+	h.Write(feature[:fingerprintChunk])
+	h.Write(feature[len(feature)-fingerprintChunk:])
+	if sum, err := s.FileFingerprint(heat.Path); err != nil || sum != hex.EncodeToString(h.Sum(nil)) {
+		t.Errorf("FileFingerprint() = %q, %v", sum, err)
+	}
+
+	// Backends that cannot open files report an error instead of a wrong hash
+	plain := NewWithOptions([]string{".mkv"}, "/mdx", Options{FileSystem: media.mapFileSystem})
+	if _, err := plain.FileFingerprint(heat.Path); err == nil {
+		t.Error("FileFingerprint without FileOpener returned no error")
+	}
+}
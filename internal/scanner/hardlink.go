@@ -0,0 +1,35 @@
+package scanner
+
+import "log/slog"
+
+// fileID identifies a physical file by device and inode, so hardlinks to the
+// same movie in several directories are recognized as one file. The zero value
+// means the platform or filesystem did not provide inode information.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// collapseHardlinks keeps one FileInfo per physical file. The first path wins,
+// which is the one under the directory listed first in scanner.directories.
+// Files without inode information (non-Unix platforms, or filesystems such as
+// SFTP/WebDAV that don't expose it) are never collapsed; they are still
+// deduplicated later by the per-run slug guard. Skipped paths are logged to logger.
+func collapseHardlinks(files []FileInfo, logger *slog.Logger) []FileInfo {
+	seen := make(map[fileID]FileInfo)
+	result := files[:0:0]
+	for _, f := range files {
+		if f.fileID == (fileID{}) {
+			result = append(result, f)
+			continue
+		}
+		if kept, ok := seen[f.fileID]; ok {
+			logger.Info("hardlink: skipping duplicate path of an already scanned file",
+				"file", f.Path, "kept", kept.Path)
+			continue
+		}
+		seen[f.fileID] = f
+		result = append(result, f)
+	}
+	return result
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// fileIdentity has no inode information to offer on this platform, so
+// hardlinked copies are scanned separately and left to the slug guard
+func fileIdentity(info fs.FileInfo) fileID {
+	return fileID{}
+}
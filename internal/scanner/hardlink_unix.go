@@ -0,0 +1,17 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileIdentity returns the device and inode of info, when available
+func fileIdentity(info fs.FileInfo) fileID {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st == nil {
+		return fileID{}
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+}
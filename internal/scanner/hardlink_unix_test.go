@@ -0,0 +1,47 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanAll_CollapsesHardlinks(t *testing.T) {
+	root := t.TempDir()
+	primary := filepath.Join(root, "movies")
+	secondary := filepath.Join(root, "4k")
+	for _, dir := range []string{primary, secondary} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	original := filepath.Join(secondary, "Heat.1995.mkv")
+	if err := os.WriteFile(original, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(original, filepath.Join(primary, "Heat (1995).mkv")); err != nil {
+		t.Skipf("hardlinks not supported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secondary, "Alien.1979.mkv"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New([]string{".mkv"}, filepath.Join(root, "mdx"))
+	files, err := s.ScanAll([]string{primary, secondary})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if len(files) != 2 {
+		t.Fatalf("ScanAll returned %v, want the hardlink collapsed to 2 files", paths)
+	}
+	if files[0].Path != filepath.Join(primary, "Heat (1995).mkv") {
+		t.Errorf("kept %s, want the path in the first directory", files[0].Path)
+	}
+}
@@ -0,0 +1,25 @@
+package scanner
+
+import "strings"
+
+// languageCodes maps the language tokens matched by languagePattern to ISO
+// 639-1 codes. "multi" and "dual" name no single language and are left out.
+var languageCodes = map[string]string{
+	"ita": "it", "eng": "en", "spa": "es", "fra": "fr", "deu": "de",
+	"jpn": "ja", "kor": "ko", "rus": "ru", "chi": "zh", "por": "pt",
+	"pol": "pl", "nld": "nl", "swe": "sv", "nor": "no", "dan": "da",
+	"fin": "fi", "tur": "tr", "ara": "ar", "heb": "he", "tha": "th",
+	"vie": "vi", "ind": "id", "msa": "ms", "hindi": "hi", "tamil": "ta",
+}
+
+// DetectLanguage returns the ISO 639-1 code of the first language token in
+// filename (e.g. "it" for "La.Vita.E.Bella.1997.ITA.mkv"), or "" when the
+// name has none. It hints at the original language of the title.
+func DetectLanguage(filename string) string {
+	for _, token := range languagePattern.FindAllString(filename, -1) {
+		if code, ok := languageCodes[strings.ToLower(token)]; ok {
+			return code
+		}
+	}
+	return ""
+}
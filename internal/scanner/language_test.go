@@ -0,0 +1,22 @@
+package scanner
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		filename string
+		expected string
+	}{
+		{"La.Vita.E.Bella.1997.ITA.1080p.mkv", "it"},
+		{"Amelie.2001.FRA.ENG.720p.mkv", "fr"},
+		{"Oldboy.2003.MULTi.KOR.1080p.mkv", "ko"},
+		{"Run.Lola.Run.1998.DUAL.1080p.mkv", ""},
+		{"Heat.1995.1080p.BluRay.mkv", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := DetectLanguage(tc.filename); got != tc.expected {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tc.filename, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// nfoCandidates returns the .nfo paths for a video in the order the NFO
+// parser checks them: the shared movie.nfo, then {filename}.nfo
+func nfoCandidates(videoPath string) []string {
+	dir := filepath.Dir(videoPath)
+	base := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	return []string{
+		filepath.Join(dir, "movie.nfo"),
+		filepath.Join(dir, base+".nfo"),
+	}
+}
+
+// NFOHash returns the hex SHA-256 of the .nfo used for videoPath, or "" when
+// the video has no .nfo. The hash is stored as nfoHash in the MDX frontmatter
+// so later scans can tell when the NFO was edited.
+func NFOHash(videoPath string) string {
+	hash, _ := nfoHash(OSFileSystem{}, videoPath, slog.Default())
+	return hash
+}
+
+// nfoHash hashes the first .nfo found for videoPath on fsys and returns it
+// with the file's modification time
+func nfoHash(fsys FileSystem, videoPath string, logger *slog.Logger) (string, time.Time) {
+	for _, candidate := range nfoCandidates(videoPath) {
+		info, err := fsys.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		content, err := fsys.ReadFile(candidate)
+		if err != nil {
+			logger.Debug("failed to read nfo for change check", "path", candidate, "error", err)
+			return "", time.Time{}
+		}
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]), info.ModTime()
+	}
+	return "", time.Time{}
+}
+
+// nfoChanged reports whether the .nfo for videoPath differs from the one the
+// MDX content was generated from. MDX files written before hashes were
+// recorded fall back to comparing the NFO modification time with scannedAt.
+func nfoChanged(fsys FileSystem, videoPath string, content []byte, logger *slog.Logger) bool {
+	hash, modTime := nfoHash(fsys, videoPath, logger)
+	if hash == "" {
+		return false
+	}
+
+	var fm struct {
+		NFOHash   string    `yaml:"nfoHash"`
+		ScannedAt time.Time `yaml:"scannedAt"`
+	}
+	if !parseFrontmatter(content, &fm) {
+		return false
+	}
+	if fm.NFOHash != "" {
+		return fm.NFOHash != hash
+	}
+	return !fm.ScannedAt.IsZero() && modTime.After(fm.ScannedAt)
+}
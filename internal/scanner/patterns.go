@@ -46,20 +46,159 @@ var (
 	// multiDiscPattern detects CD/Disc/Disk/Part/Pt markers in filenames.
 	// Requires a separator before the keyword to avoid matching embedded words like "ACDC".
 	// Captures the disc number as group 1.
-	multiDiscPattern = regexp.MustCompile(`(?i)[\.\s_-](?:CD|Disc|Disk|Part|Pt)[\.\s_-]?(\d+)(?:[\.\s_-]|$)`)
+	multiDiscPattern = regexp.MustCompile(`(?i)[\.\s_-](CD|Disc|Disk|Part|Pt)[\.\s_-]?(\d+)(?:[\.\s_-]|$)`)
+	// releaseYearInNamePattern finds a release year preceded by a separator, so a
+	// year that starts the title (e.g. "2001.A.Space.Odyssey") is not counted
+	releaseYearInNamePattern = regexp.MustCompile(`[\.\s_\(\[-](19|20)\d{2}\b`)
 	// discMarkerInTitle strips disc markers from a title string (used for grouping normalization)
 	discMarkerInTitle = regexp.MustCompile(`(?i)\b(cd|disc|disk|part|pt)\s*\d+\b`)
+	// tvSeasonDirPattern detects TV season directory names: "Show S01", "Season 2",
+	// "Stagione 3", "S01E01-E10", "Complete Series"
+	tvSeasonDirPattern = regexp.MustCompile(`(?i)(\bS\d{1,2}(E\d{1,3})?\b|\b(season|stagione|saison|temporada|staffel)[\s._-]*\d{1,2}\b|\bcomplete[\s._-]+series\b)`)
+	// canonicalNamePattern matches already-clean names, "Title (Year)" optionally
+	// followed by {tags} (e.g. "{imdb-tt0133093}" or "{edition-Director's Cut}").
+	// The title may not contain brackets or underscores.
+	canonicalNamePattern = regexp.MustCompile(`^([^\[\]\(\)\{\}_]+?) \((\d{4})\)(?:\s*\{[^{}]*\})*$`)
+	// dottedNamePattern detects dot-separated words ("The.Matrix"), which mark a
+	// scene-style name rather than a clean title; "Dr. Strangelove" is fine
+	dottedNamePattern = regexp.MustCompile(`\.\S`)
+	// boxsetDirPattern detects movie boxset/collection directory names: "Trilogy", "Box Set", "Collection"
+	boxsetDirPattern = regexp.MustCompile(`(?i)\b(duology|trilogy|quadrilogy|pentalogy|hexalogy|saga|collection|anthology|box[\s._-]?set)\b`)
 )
 
+// reservedSlugs holds slugs that would shadow site routes (see ConfigureReservedSlugs)
+var reservedSlugs map[string]bool
+
+// reservedSlugSuffix is appended to generated slugs that are reserved
+const reservedSlugSuffix = "-movie"
+
+// slugArticles holds leading articles GenerateSlug drops (see ConfigureSlugArticles)
+var slugArticles []string
+
+// Built-in patterns, kept so ConfigureTokens always extends the defaults
+var (
+	defaultQualityPattern            = qualityPattern
+	defaultQualityMarkerCheckPattern = qualityMarkerCheckPattern
+	defaultReleaseGroupPattern       = releaseGroupPattern
+	defaultBracketedGroupPattern     = bracketedGroupPattern
+)
+
+// ConfigureTokens merges user-supplied release groups (e.g. "NovaRip") and
+// quality tokens (e.g. "DLMux") into the built-in filename patterns. Tokens
+// match literally and case-insensitively. Each call starts again from the
+// built-in defaults. Not safe for concurrent use: call once at startup,
+// before scanning.
+func ConfigureTokens(extraReleaseGroups, extraQualityTokens []string) {
+	groups := tokenAlternation(extraReleaseGroups)
+	quality := tokenAlternation(extraQualityTokens)
+
+	releaseGroupPattern = withExtraTokens(defaultReleaseGroupPattern, `[-\.](`, groups)
+	bracketedGroupPattern = withExtraTokens(defaultBracketedGroupPattern, `\[(`, groups)
+	qualityPattern = withExtraTokens(defaultQualityPattern, `\b(`, quality)
+	qualityMarkerCheckPattern = withExtraTokens(defaultQualityMarkerCheckPattern, `^[\.\s]?(`, quality)
+}
+
+// ConfigureReservedSlugs sets slugs that GenerateSlug never returns as is,
+// such as site routes ("about", "index"). A generated slug matching one gets
+// reservedSlugSuffix ("index" becomes "index-movie"). Matching is
+// case-insensitive. Not safe for concurrent use: call once at startup.
+func ConfigureReservedSlugs(slugs []string) {
+	reservedSlugs = make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		if slug = strings.ToLower(strings.TrimSpace(slug)); slug != "" {
+			reservedSlugs[slug] = true
+		}
+	}
+}
+
+// ConfigureSlugArticles sets leading articles that GenerateSlug drops from
+// titles ("The Matrix" becomes "matrix-1999"). Articles match
+// case-insensitively and must be followed by a space, except ones ending in an
+// apostrophe ("L'Avventura"). nil keeps articles. Not safe for concurrent use:
+// call once at startup.
+func ConfigureSlugArticles(articles []string) {
+	slugArticles = articles
+}
+
+// stripLeadingArticle removes the first of slugArticles that starts title,
+// unless nothing would be left
+func stripLeadingArticle(title string) string {
+	for _, article := range slugArticles {
+		article = strings.TrimSpace(article)
+		if article == "" || len(title) <= len(article) || !strings.EqualFold(title[:len(article)], article) {
+			continue
+		}
+		rest := title[len(article):]
+		if !strings.HasSuffix(article, "'") && rest[0] != ' ' {
+			continue
+		}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return rest
+		}
+	}
+	return title
+}
+
+// tokenAlternation quotes tokens and joins them into a regexp alternation,
+// skipping blank entries
+func tokenAlternation(tokens []string) string {
+	var quoted []string
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			quoted = append(quoted, regexp.QuoteMeta(token))
+		}
+	}
+	return strings.Join(quoted, "|")
+}
+
+// withExtraTokens returns base with alternation inserted at the start of the
+// token group that follows groupPrefix, or base unchanged when there is nothing to add
+func withExtraTokens(base *regexp.Regexp, groupPrefix, alternation string) *regexp.Regexp {
+	if alternation == "" {
+		return base
+	}
+	return regexp.MustCompile(strings.Replace(base.String(), groupPrefix, groupPrefix+alternation+"|", 1))
+}
+
 // ExtractTitleAndYear extracts the movie title and year from a filename
 func ExtractTitleAndYear(filename string) (title string, year int) {
+	return extractTitleAndYear(filename, nil)
+}
+
+// ParseStep is the name left after one step of ExtractTitleAndYear
+type ParseStep struct {
+	Step   string `json:"step"`
+	Result string `json:"result"`
+}
+
+// TraceTitleAndYear works like ExtractTitleAndYear and also returns the name
+// after every step, to see which pattern removed what
+func TraceTitleAndYear(filename string) (title string, year int, steps []ParseStep) {
+	title, year = extractTitleAndYear(filename, func(step, name string) {
+		steps = append(steps, ParseStep{Step: step, Result: name})
+	})
+	return title, year, steps
+}
+
+// extractTitleAndYear implements ExtractTitleAndYear, passing the name after
+// each step to trace when it is not nil
+func extractTitleAndYear(filename string, trace func(step, name string)) (title string, year int) {
+	step := func(name, value string) {
+		if trace != nil {
+			trace(name, value)
+		}
+	}
+
 	// Remove file extension
 	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	step("extension", name)
 
-	// Remove resolution markers FIRST (US-010)
-	// This must happen before year extraction to prevent "1080p" from being
-	// parsed as year "1080" with leftover "p"
-	name = resolutionPattern.ReplaceAllString(name, " ")
+	// Fast path: clean "Title (Year)" names are used as is, so words that look
+	// like tags ("DC League of Super-Pets", "Ma") are not stripped from the title
+	if title, year, ok := parseCanonicalName(name); ok {
+		step("canonical", title)
+		return title, year
+	}
 
 	// US-016: Smart year extraction for titles starting with years
 	// Priority 1: Year in parentheses/brackets - definitely release year (e.g., "(2020)" or "[2020]")
@@ -68,109 +207,181 @@ func ExtractTitleAndYear(filename string) (title string, year int) {
 		year, _ = strconv.Atoi(yearMatches[1])
 		name = yearInBracketsPattern.ReplaceAllString(name, "")
 	} else {
-		// Priority 2: Find the LAST year that is followed by quality markers or at end
+		// Priority 2: Prefer a year followed by quality markers, then one at the end
 		// This handles: "2001.A.Space.Odyssey.1968.BluRay" -> 1968 is the release year
 		// This prevents "2001" from being extracted as the year when it's part of the title
 		year, name = extractLastValidYear(name)
 	}
+	step("year", name)
+
+	// Remove resolution markers (US-010)
+	// Done after year extraction so "1080p" anchors the year before it; resolution
+	// numbers (480, 720, 1080, 2160) never pass the release year range check
+	name = resolutionPattern.ReplaceAllString(name, " ")
+	step("resolution", name)
 
 	// Remove quality markers
 	name = qualityPattern.ReplaceAllString(name, " ")
+	step("quality", name)
 
 	// Remove codec info
 	name = codecPattern.ReplaceAllString(name, " ")
+	step("codec", name)
 
 	// Remove audio info
 	name = audioPattern.ReplaceAllString(name, " ")
+	step("audio", name)
 
 	// Remove language codes
 	name = languagePattern.ReplaceAllString(name, " ")
+	step("language", name)
 
 	// Remove subtitle markers
 	name = subtitlePattern.ReplaceAllString(name, " ")
+	step("subtitle", name)
 
 	// Remove edition markers (US-015)
 	name = editionPattern.ReplaceAllString(name, " ")
+	step("edition", name)
 
 	// Remove bracketed release groups first (US-014)
 	// e.g., [YTS], [YIFY], [RARBG], [EVO], [FGT]
 	name = bracketedGroupPattern.ReplaceAllString(name, " ")
+	step("bracketed-group", name)
 
 	// Remove release group (usually after a dash at the end) (US-014)
 	// e.g., -SPARKS, -GECKOS, -FGT, -YIFY
 	name = releaseGroupPattern.ReplaceAllString(name, "")
+	step("release-group", name)
 
 	// Remove any remaining content in brackets
 	name = bracketPattern.ReplaceAllString(name, " ")
+	step("brackets", name)
 
 	// Replace dots and underscores with spaces
 	name = strings.ReplaceAll(name, ".", " ")
 	name = strings.ReplaceAll(name, "_", " ")
+	step("separators", name)
 
 	// Remove multiple spaces
 	name = regexp.MustCompile(`\s+`).ReplaceAllString(name, " ")
 
 	// Trim whitespace
 	title = strings.TrimSpace(name)
+	step("whitespace", title)
 
 	return title, year
 }
 
-// extractLastValidYear finds the last 4-digit year in the filename that is likely a release year
-// A year is considered valid if:
-// 1. It's at the very end of the filename (after extension removal)
-// 2. It's followed by quality markers (BluRay, x264, etc.)
+// parseCanonicalName extracts title and year from a name already in the
+// canonical "Title (Year)" or "Title (Year) {tags}" form. ok is false for any
+// other name, including scene-style dotted names and implausible years.
+func parseCanonicalName(name string) (title string, year int, ok bool) {
+	matches := canonicalNamePattern.FindStringSubmatch(strings.TrimSpace(name))
+	if matches == nil || dottedNamePattern.MatchString(matches[1]) {
+		return "", 0, false
+	}
+	year, _ = strconv.Atoi(matches[2])
+	if year < 1888 || year > 2050 {
+		return "", 0, false
+	}
+	title = strings.Join(strings.Fields(matches[1]), " ")
+	return title, year, title != ""
+}
+
+// extractLastValidYear finds the 4-digit year in the filename that is most likely the release year.
+// Candidates must be plausible years (1888-2050) that stand alone: numbers inside
+// longer digit runs or resolutions ("1920x1080") are ignored, and years glued to
+// letters (e.g. a "-GRP2049" release group) are used only when nothing else is found.
+// Among candidates, in order of preference:
+//  1. The last year followed by quality markers (BluRay, 1080p, x264, etc.), so
+//     "2017.1080p.Blade.Runner.2049" is 2017 even though 2049 ends the name
+//  2. A year at the very end of the filename (after extension removal)
+//  3. The last candidate
+//
 // Returns the year and the name with the year removed
 func extractLastValidYear(name string) (int, string) {
 	// Find all 4-digit numbers and their positions
 	matches := allYearsPattern.FindAllStringSubmatchIndex(name, -1)
-	if len(matches) == 0 {
-		return 0, name
-	}
-
-	// Iterate from the last match backward to find a valid release year
-	for i := len(matches) - 1; i >= 0; i-- {
-		match := matches[i]
-		yearStart := match[0]
-		yearEnd := match[1]
-		yearStr := name[yearStart:yearEnd]
-		yearVal, _ := strconv.Atoi(yearStr)
 
+	var standalone, embedded [][]int
+	for _, match := range matches {
+		yearVal, _ := strconv.Atoi(name[match[0]:match[1]])
 		// Validate it's a reasonable movie year (1888 was first film, future limit ~2030)
 		if yearVal < 1888 || yearVal > 2050 {
 			continue
 		}
-
-		// Check what follows the year
-		afterYear := name[yearEnd:]
-
-		// If nothing follows (year at end), it's likely the release year
-		if strings.TrimSpace(afterYear) == "" {
-			return yearVal, name[:yearStart] + name[yearEnd:]
+		switch yearContext(name, match[0], match[1]) {
+		case yearStandalone:
+			standalone = append(standalone, match)
+		case yearEmbedded:
+			embedded = append(embedded, match)
 		}
+	}
+	candidates := standalone
+	if len(candidates) == 0 {
+		candidates = embedded
+	}
+	if len(candidates) == 0 {
+		return 0, name
+	}
+
+	removeYear := func(match []int) (int, string) {
+		yearVal, _ := strconv.Atoi(name[match[0]:match[1]])
+		return yearVal, name[:match[0]] + name[match[1]:]
+	}
 
-		// If followed by quality markers, it's likely the release year
-		if qualityMarkerCheckPattern.MatchString(afterYear) {
-			return yearVal, name[:yearStart] + name[yearEnd:]
+	// A year followed by quality markers is the release year, even when a later
+	// year is part of the title
+	for i := len(candidates) - 1; i >= 0; i-- {
+		afterYear := strings.TrimLeft(name[candidates[i][1]:], " ._-")
+		if afterYear != "" && qualityMarkerCheckPattern.MatchString(afterYear) {
+			return removeYear(candidates[i])
 		}
 	}
 
-	// Fallback: if no year was followed by quality markers, use the last one anyway
-	// This maintains backwards compatibility with "Movie.2020.mkv" style names
-	lastMatch := matches[len(matches)-1]
-	yearStr := name[lastMatch[0]:lastMatch[1]]
-	yearVal, _ := strconv.Atoi(yearStr)
-	if yearVal >= 1888 && yearVal <= 2050 {
-		return yearVal, name[:lastMatch[0]] + name[lastMatch[1]:]
+	// Otherwise a year at the end, or failing that the last candidate. This
+	// maintains backwards compatibility with "Movie.2020.mkv" style names
+	return removeYear(candidates[len(candidates)-1])
+}
+
+// Kinds of 4-digit numbers found by extractLastValidYear
+const (
+	yearStandalone = iota // Separated from its neighbours, e.g. ".2017."
+	yearEmbedded          // Glued to letters, e.g. "GRP2049" or "Movie2017"
+	yearNotAYear          // Part of a longer number or a resolution such as "1920x1080"
+)
+
+// yearContext classifies the 4-digit number at name[start:end] by its neighbours
+func yearContext(name string, start, end int) int {
+	var before, after byte
+	if start > 0 {
+		before = name[start-1]
+	}
+	if end < len(name) {
+		after = name[end]
 	}
 
-	return 0, name
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isLetter := func(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+	switch {
+	case isDigit(before) || isDigit(after):
+		return yearNotAYear
+	case (after == 'x' || after == 'X') && end+1 < len(name) && isDigit(name[end+1]):
+		return yearNotAYear
+	case (before == 'x' || before == 'X') && start >= 2 && isDigit(name[start-2]):
+		return yearNotAYear
+	case isLetter(before) || isLetter(after):
+		return yearEmbedded
+	}
+	return yearStandalone
 }
 
 // GenerateSlug creates a URL-friendly slug from title and year
 func GenerateSlug(title string, year int) string {
-	// Convert to lowercase
-	slug := strings.ToLower(title)
+	// Convert to lowercase, without a leading article when configured
+	slug := strings.ToLower(stripLeadingArticle(strings.TrimSpace(title)))
 
 	// Replace spaces with hyphens
 	slug = strings.ReplaceAll(slug, " ", "-")
@@ -189,6 +400,22 @@ func GenerateSlug(title string, year int) string {
 		slug = slug + "-" + strconv.Itoa(year)
 	}
 
+	// Keep clear of reserved site routes
+	if reservedSlugs[slug] {
+		slug += reservedSlugSuffix
+	}
+
+	return slug
+}
+
+// GenerateDiscSlug creates a slug like GenerateSlug, suffixed with "-cdN" for
+// secondary discs (disc > 1). Disc 1 and non-disc files keep the plain slug, so
+// a secondary disc kept alongside its primary never overwrites the primary's MDX.
+func GenerateDiscSlug(title string, year int, disc int) string {
+	slug := GenerateSlug(title, year)
+	if disc > 1 {
+		slug = slug + "-cd" + strconv.Itoa(disc)
+	}
 	return slug
 }
 
@@ -210,20 +437,53 @@ func CleanTitle(title string) string {
 
 // ExtractDiscNumber returns the disc/part number from a filename, or 0 if none found.
 // Examples: "Movie.CD1.avi" → 1, "Movie.Part2.avi" → 2, "Movie.avi" → 0
+//
+// CD/Disc/Disk markers always count. "Part"/"Pt" often belongs to the title
+// ("Deathly Hallows Part 2" is its own film), so it only counts as a disc
+// marker when it trails the name or follows the release year or a quality tag:
+// "Movie.2010.1080p.Part2.mkv" → 2, "Movie.Part.2.2011.mkv" → 0
 func ExtractDiscNumber(filename string) int {
 	name := strings.TrimSuffix(filename, filepath.Ext(filename))
-	match := multiDiscPattern.FindStringSubmatch(name)
-	if len(match) < 2 {
-		return 0
+	for _, match := range multiDiscPattern.FindAllStringSubmatchIndex(name, -1) {
+		keyword := strings.ToLower(name[match[2]:match[3]])
+		if (keyword == "part" || keyword == "pt") && isEditorialPart(name, match[0], match[1]) {
+			continue
+		}
+		n, err := strconv.Atoi(name[match[4]:match[5]])
+		if err != nil {
+			return 0
+		}
+		return n
 	}
-	n, err := strconv.Atoi(match[1])
-	if err != nil {
-		return 0
+	return 0
+}
+
+// isEditorialPart reports whether the Part/Pt marker at name[start:end] is part
+// of the title rather than a disc marker: more of the name follows it, and no
+// release year or quality tag precedes it
+func isEditorialPart(name string, start, end int) bool {
+	if strings.Trim(name[end:], ". _-") == "" {
+		return false
 	}
-	return n
+	before := name[:start]
+	return !releaseYearInNamePattern.MatchString(before) &&
+		!resolutionPattern.MatchString(before) &&
+		!qualityPattern.MatchString(before)
 }
 
 // normalizeTitle lowercases and strips disc markers for grouping purposes.
 func normalizeTitle(title string) string {
 	return strings.TrimSpace(discMarkerInTitle.ReplaceAllString(strings.ToLower(title), ""))
 }
+
+// IsTVSeasonDir reports whether a directory name looks like a TV season folder
+// (e.g. "Breaking Bad S01", "Season 2")
+func IsTVSeasonDir(dirName string) bool {
+	return tvSeasonDirPattern.MatchString(dirName)
+}
+
+// IsBoxsetDir reports whether a directory name looks like a movie boxset
+// (e.g. "The Godfather Trilogy", "Alien Quadrilogy Box Set")
+func IsBoxsetDir(dirName string) bool {
+	return boxsetDirPattern.MatchString(dirName)
+}
@@ -31,6 +31,15 @@ func TestYearStartingTitles(t *testing.T) {
 		{"The.Matrix.1999.1080p.mkv", "The Matrix", 1999},
 		{"Inception.2010.BluRay.mkv", "Inception", 2010},
 		{"Movie.2020.mkv", "Movie", 2020},
+		// Titles containing a plausible year next to the release year
+		{"Blade Runner 2049 (2017).mkv", "Blade Runner 2049", 2017},
+		{"2012 (2009).mkv", "2012", 2009},
+		{"1917 (2019).mkv", "1917", 2019},
+		{"Blade.Runner.2049.2017.mkv", "Blade Runner 2049", 2017},
+		{"Blade Runner 2049 2017 1080p BluRay.mkv", "Blade Runner 2049", 2017},
+		{"Blade.Runner.2049.2017.1920x1080.mkv", "Blade Runner 2049", 2017}, // resolution digits are not years
+		{"Blade.Runner.2049.2017.BluRay.x264-GRP2049.mkv", "Blade Runner 2049", 2017},
+		{"Movie2017.mkv", "Movie", 2017}, // glued year still used when it is the only one
 	}
 
 	for _, tc := range testCases {
@@ -42,6 +51,29 @@ func TestYearStartingTitles(t *testing.T) {
 	}
 }
 
+func TestExtractLastValidYear(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expectedYear int
+		expectedName string
+	}{
+		// The quality-anchored year wins over a later year that ends the name
+		{"2017.1080p.Blade.Runner.2049", 2017, ".1080p.Blade.Runner.2049"},
+		{"Blade.Runner.2049.2017", 2017, "Blade.Runner.2049."},
+		{"Blade.Runner.2049", 2049, "Blade.Runner."},
+		{"Movie.1920x1080", 0, "Movie.1920x1080"},
+		{"Movie.12345", 0, "Movie.12345"},
+	}
+
+	for _, tc := range testCases {
+		year, name := extractLastValidYear(tc.name)
+		if year != tc.expectedYear || name != tc.expectedName {
+			t.Errorf("extractLastValidYear(%q) = (%d, %q), want (%d, %q)",
+				tc.name, year, name, tc.expectedYear, tc.expectedName)
+		}
+	}
+}
+
 func TestExtractDiscNumber(t *testing.T) {
 	testCases := []struct {
 		filename string
@@ -78,6 +110,14 @@ func TestExtractDiscNumber(t *testing.T) {
 		// "ACDC" should NOT match — no separator before "CD"
 		{"ACDC.Greatest.Hits.2020.avi", 0},
 		{"The.ACDC.Story.2019.mkv", 0},
+		// Editorial "Part N" in the title is a separate film, not a disc
+		{"Harry.Potter.and.the.Deathly.Hallows.Part.1.2010.1080p.BluRay.x264.mkv", 0},
+		{"Harry Potter and the Deathly Hallows - Part 2 (2011).mkv", 0},
+		{"Harry.Potter.and.the.Deathly.Hallows.Pt.2.2011.mkv", 0},
+		// Part markers after the year or quality tags are still discs
+		{"Kill.Bill.Vol.1.2003.1080p.BluRay.Part1.mkv", 1},
+		{"Kill.Bill.Vol.2.2004.DVDRip.Part.2.avi", 2},
+		{"Harry.Potter.and.the.Deathly.Hallows.Part.2.2011.CD2.avi", 2},
 	}
 
 	for _, tc := range testCases {
@@ -182,6 +222,39 @@ func TestFilterMultiDiscDuplicates(t *testing.T) {
 	}
 }
 
+func TestParseCanonicalName(t *testing.T) {
+	testCases := []struct {
+		filename      string
+		expectedTitle string
+		expectedYear  int
+		canonical     bool
+	}{
+		{"Blade Runner 2049 (2017).mkv", "Blade Runner 2049", 2017, true},
+		{"DC League of Super-Pets (2022).mkv", "DC League of Super-Pets", 2022, true},
+		{"Ma (2019).mkv", "Ma", 2019, true},
+		{"Dr. Strangelove (1964).mkv", "Dr. Strangelove", 1964, true},
+		{"The Matrix (1999) {imdb-tt0133093}.mkv", "The Matrix", 1999, true},
+		{"Blade Runner (1982) {edition-Final Cut}.mkv", "Blade Runner", 1982, true},
+		// Not canonical: handled by the full pipeline
+		{"The.Matrix.(1999).mkv", "The Matrix", 1999, false},
+		{"The Matrix (1999) 1080p.mkv", "The Matrix", 1999, false},
+		{"[YTS] The Matrix (1999).mkv", "The Matrix", 1999, false},
+		{"Metropolis (1026).mkv", "Metropolis", 1026, false}, // bracketed years skip the range check
+	}
+
+	for _, tc := range testCases {
+		name := tc.filename[:len(tc.filename)-len(".mkv")]
+		if _, _, ok := parseCanonicalName(name); ok != tc.canonical {
+			t.Errorf("parseCanonicalName(%q) ok = %v, want %v", name, ok, tc.canonical)
+		}
+		title, year := ExtractTitleAndYear(tc.filename)
+		if title != tc.expectedTitle || year != tc.expectedYear {
+			t.Errorf("ExtractTitleAndYear(%q) = (%q, %d), want (%q, %d)",
+				tc.filename, title, year, tc.expectedTitle, tc.expectedYear)
+		}
+	}
+}
+
 func TestEditionMarkers(t *testing.T) {
 	testCases := []struct {
 		filename     string
@@ -213,3 +286,147 @@ func TestEditionMarkers(t *testing.T) {
 		}
 	}
 }
+
+func TestDirectoryHeuristics(t *testing.T) {
+	testCases := []struct {
+		dirName  string
+		isTV     bool
+		isBoxset bool
+	}{
+		{"Breaking Bad S01", true, false},
+		{"Breaking.Bad.S02.1080p.BluRay", true, false},
+		{"The Office Season 3", true, false},
+		{"Stagione 2", true, false},
+		{"Friends Complete Series", true, false},
+		{"The Godfather Trilogy", false, true},
+		{"Alien Quadrilogy Box Set", false, true},
+		{"Star Wars Saga", false, true},
+		{"Inception (2010)", false, false},
+		{"Se7en (1995)", false, false},
+		{"Seasons of Love", false, false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsTVSeasonDir(tc.dirName); got != tc.isTV {
+			t.Errorf("IsTVSeasonDir(%q) = %v, want %v", tc.dirName, got, tc.isTV)
+		}
+		if got := IsBoxsetDir(tc.dirName); got != tc.isBoxset {
+			t.Errorf("IsBoxsetDir(%q) = %v, want %v", tc.dirName, got, tc.isBoxset)
+		}
+	}
+}
+
+func TestFilterMultiDiscDuplicates_EditorialParts(t *testing.T) {
+	fileNames := []string{
+		"Harry.Potter.and.the.Deathly.Hallows.Part.1.2010.1080p.BluRay.mkv",
+		"Harry.Potter.and.the.Deathly.Hallows.Part.2.2011.1080p.BluRay.mkv",
+		"Kill.Bill.Vol.1.2003.DVDRip.Part1.avi",
+		"Kill.Bill.Vol.1.2003.DVDRip.Part2.avi",
+	}
+
+	var files []FileInfo
+	for _, name := range fileNames {
+		title, year := ExtractTitleAndYear(name)
+		files = append(files, FileInfo{
+			Path:       "/movies/" + name,
+			FileName:   name,
+			Title:      title,
+			Year:       year,
+			DiscNumber: ExtractDiscNumber(name),
+		})
+	}
+
+	kept, skipped := FilterMultiDiscDuplicates(files)
+
+	// Both Deathly Hallows films survive; only Kill Bill's second disc is dropped
+	if len(kept) != 3 {
+		t.Errorf("kept %d files, want 3: %+v", len(kept), kept)
+	}
+	if len(skipped) != 1 || skipped[0].FileName != "Kill.Bill.Vol.1.2003.DVDRip.Part2.avi" {
+		t.Errorf("unexpected skipped discs: %+v", skipped)
+	}
+}
+
+func TestConfigureTokens(t *testing.T) {
+	defer ConfigureTokens(nil, nil)
+
+	testCases := []struct {
+		filename      string
+		defaultTitle  string
+		expectedTitle string
+		expectedYear  int
+	}{
+		{"Il.Traditore.2019.DLMux.1080p.x264.mkv", "Il Traditore DLMux", "Il Traditore", 2019},
+		{"Il.Traditore.DLMux.2019.mkv", "Il Traditore DLMux", "Il Traditore", 2019},
+		{"Gomorra.2008.720p-iDN_CreW.mkv", "Gomorra -iDN CreW", "Gomorra", 2008},
+		{"La Grande Bellezza (2013) [iDN_CreW].mkv", "La Grande Bellezza", "La Grande Bellezza", 2013},
+		// Built-in tokens keep working alongside the extras
+		{"Inception.2010.BluRay.x264-SPARKS.mkv", "Inception", "Inception", 2010},
+	}
+
+	for _, tc := range testCases {
+		if title, _ := ExtractTitleAndYear(tc.filename); title != tc.defaultTitle {
+			t.Errorf("default ExtractTitleAndYear(%q) title = %q, want %q", tc.filename, title, tc.defaultTitle)
+		}
+	}
+
+	ConfigureTokens([]string{"iDN_CreW", " "}, []string{"dlmux"})
+
+	for _, tc := range testCases {
+		title, year := ExtractTitleAndYear(tc.filename)
+		if title != tc.expectedTitle || year != tc.expectedYear {
+			t.Errorf("ExtractTitleAndYear(%q) = (%q, %d), want (%q, %d)", tc.filename, title, year, tc.expectedTitle, tc.expectedYear)
+		}
+	}
+}
+
+func TestConfigureReservedSlugs(t *testing.T) {
+	defer ConfigureReservedSlugs(nil)
+	ConfigureReservedSlugs([]string{"About", " index ", "api", "", "heat-1995"})
+
+	testCases := []struct {
+		title    string
+		year     int
+		disc     int
+		expected string
+	}{
+		{"Index", 0, 0, "index-movie"},
+		{"About", 0, 0, "about-movie"},
+		{"Index", 2020, 0, "index-2020"},
+		{"Heat", 1995, 0, "heat-1995-movie"},
+		{"Index", 0, 2, "index-movie-cd2"},
+		{"The Matrix", 1999, 0, "the-matrix-1999"},
+	}
+
+	for _, tc := range testCases {
+		if slug := GenerateDiscSlug(tc.title, tc.year, tc.disc); slug != tc.expected {
+			t.Errorf("GenerateDiscSlug(%q, %d, %d) = %q, want %q", tc.title, tc.year, tc.disc, slug, tc.expected)
+		}
+	}
+}
+
+func TestConfigureSlugArticles(t *testing.T) {
+	defer ConfigureSlugArticles(nil)
+	ConfigureSlugArticles([]string{"The", "A", "An", "L'"})
+
+	testCases := []struct {
+		title    string
+		year     int
+		expected string
+	}{
+		{"The Matrix", 1999, "matrix-1999"},
+		{"the matrix", 1999, "matrix-1999"},
+		{"An American Werewolf in London", 1981, "american-werewolf-in-london-1981"},
+		{"L'Avventura", 1960, "avventura-1960"},
+		{"Theodora Goes Wild", 1936, "theodora-goes-wild-1936"},
+		{"Amelie", 2001, "amelie-2001"},
+		{"The", 0, "the"},
+		{"Heat", 1995, "heat-1995"},
+	}
+
+	for _, tc := range testCases {
+		if slug := GenerateSlug(tc.title, tc.year); slug != tc.expected {
+			t.Errorf("GenerateSlug(%q, %d) = %q, want %q", tc.title, tc.year, slug, tc.expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 )
@@ -18,6 +19,10 @@ type ProcessResult struct {
 // generated slug, and any error encountered.
 type ProcessFunc func(ctx context.Context, file FileInfo) (metadataSource string, slug string, err error)
 
+// ErrDuplicateSlug is returned by a ProcessFunc when the file resolved to a slug
+// that another file already claimed this run, so no MDX was written for it.
+var ErrDuplicateSlug = errors.New("slug already produced this run")
+
 // SlugGuard provides thread-safe slug deduplication. Multiple goroutines can
 // safely call TryClaimSlug; only the first caller for a given slug succeeds.
 type SlugGuard struct {
@@ -106,3 +111,43 @@ func ProcessFilesConcurrently(
 	}
 	return out
 }
+
+// TaskPool runs submitted tasks on a fixed number of worker goroutines.
+// It is used for work that should be bounded separately from the main
+// per-file pipeline, such as image downloads.
+type TaskPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+}
+
+// NewTaskPool creates a TaskPool and starts its workers.
+func NewTaskPool(workers int) *TaskPool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	p := &TaskPool{tasks: make(chan func(), workers*2)}
+	for w := 0; w < workers; w++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues a task for execution. It blocks when the queue is full,
+// applying backpressure to the caller.
+func (p *TaskPool) Submit(task func()) {
+	p.tasks <- task
+}
+
+// Wait stops accepting tasks and blocks until all queued tasks have run.
+// Submit must not be called after Wait.
+func (p *TaskPool) Wait() {
+	close(p.tasks)
+	p.wg.Wait()
+}
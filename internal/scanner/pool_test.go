@@ -227,3 +227,32 @@ func TestProcessFilesConcurrently_ZeroWorkers(t *testing.T) {
 		t.Errorf("expected 1 result, got %d", len(results))
 	}
 }
+
+func TestTaskPool_RunsAllTasksWithBoundedConcurrency(t *testing.T) {
+	const workers = 3
+	pool := NewTaskPool(workers)
+
+	var completed, active, maxActive int64
+	for i := 0; i < 20; i++ {
+		pool.Submit(func() {
+			current := atomic.AddInt64(&active, 1)
+			for {
+				prev := atomic.LoadInt64(&maxActive)
+				if current <= prev || atomic.CompareAndSwapInt64(&maxActive, prev, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&active, -1)
+			atomic.AddInt64(&completed, 1)
+		})
+	}
+	pool.Wait()
+
+	if completed != 20 {
+		t.Errorf("expected 20 completed tasks, got %d", completed)
+	}
+	if maxActive > workers {
+		t.Errorf("expected at most %d concurrent tasks, got %d", workers, maxActive)
+	}
+}
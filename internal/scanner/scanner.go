@@ -2,10 +2,14 @@ package scanner
 
 import (
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // FileInfo represents a scanned video file with extracted information
@@ -15,10 +19,17 @@ type FileInfo struct {
 	Title      string
 	Year       int
 	Size       int64
+	ModTime    time.Time // Last modification time of the video file
 	Slug       string
-	DiscNumber int    // Disc/part number extracted from filename (0 = not a multi-disc file)
-	ShouldScan bool   // Whether to scan this file (false if MDX already exists)
-	SourceDir  string // Configured root directory that contains this file
+	DiscNumber int      // Disc/part number extracted from filename (0 = not a multi-disc file)
+	ShouldScan bool     // Whether to scan this file (false if a fresh MDX already exists)
+	SourceDir  string   // Configured root directory that contains this file
+	Subtitles  []string // Languages of external subtitle files next to the video; only looked up for files with ShouldScan, see AttachSubtitles
+	Language   string   // ISO 639-1 code of the language token in the filename ("" = none), see DetectLanguage
+	fileID     fileID   // Device and inode, used to collapse hardlinked duplicates
+
+	subtitlesChecked bool // Subtitles has been looked up
+	readChecked      bool // The video has been probed for readability
 }
 
 // SkippedDisc records a secondary disc that was filtered out by FilterMultiDiscDuplicates.
@@ -30,26 +41,105 @@ type SkippedDisc struct {
 
 // Scanner handles file system scanning for video files
 type Scanner struct {
-	extensions  []string
-	mdxDir      string
-	excludeDirs []string
+	extensions      []string
+	dirExtensions   map[string][]string // cleaned scan directory -> extensions replacing the global list
+	mdxDir          string
+	excludeDirs     []string
+	fs              FileSystem // media library filesystem
+	mdxFS           FileSystem // filesystem holding mdxDir
+	skipTV          bool       // skip directories that look like TV seasons
+	refreshAge      time.Duration
+	rescanUnmatched bool                        // rescan files whose MDX is an unmatched placeholder
+	trackNFOChanges func(videoPath string) bool // rescan files whose .nfo changed since the MDX was written
+	outputDirs      map[string]bool             // absolute mdxDir and Options.OutputDirs, never walked
+	completed       map[string]bool             // video paths that need no scan (Options.Completed)
+	includeBroken   bool                        // keep zero-byte and unreadable videos
+	broken          int                         // videos skipped as broken by the last ScanAll
+	logger          *slog.Logger
+}
+
+// Options configures a Scanner
+type Options struct {
+	ExcludeDirs []string
+	// FileSystem is used to walk and stat the media directories (default: local disk)
+	FileSystem FileSystem
+	// MDXFileSystem is used to check for existing MDX files (default: local disk)
+	MDXFileSystem FileSystem
+	// SkipTVFolders skips directories whose names look like TV seasons (e.g. "Show S01")
+	SkipTVFolders bool
+	// RefreshOlderThan marks files for scanning when their existing MDX was
+	// last scanned longer ago than this (0 = existing MDX files are never rescanned)
+	RefreshOlderThan time.Duration
+	// RescanUnmatched marks files for scanning when their existing MDX is an
+	// "unmatched: true" placeholder, so a later lookup can replace it
+	RescanUnmatched bool
+	// TrackNFOChanges reports whether a video's metadata comes from its .nfo.
+	// Those files are marked for scanning when the .nfo content differs from
+	// the nfoHash recorded in the existing MDX, so NFO edits propagate (nil =
+	// no video)
+	TrackNFOChanges func(videoPath string) bool
+	// DirExtensions replaces the extension list for files under specific scan
+	// directories, keyed by the cleaned directory path
+	DirExtensions map[string][]string
+	// Completed holds video paths finished by an interrupted scan (--resume);
+	// they get ShouldScan false without looking for their MDX
+	Completed map[string]bool
+	// OutputDirs are skipped while walking, like mdxDir, so output placed inside
+	// a scan directory (e.g. covers_dir) is never read back as input
+	OutputDirs []string
+	// IncludeBrokenFiles keeps zero-byte and unreadable video files (e.g.
+	// failed downloads), which are otherwise skipped with a warning
+	IncludeBrokenFiles bool
+	// Logger receives the scanner's log messages (default: slog.Default())
+	Logger *slog.Logger
 }
 
 // New creates a new Scanner instance
 func New(extensions []string, mdxDir string) *Scanner {
-	return &Scanner{
-		extensions:  extensions,
-		mdxDir:      mdxDir,
-		excludeDirs: []string{},
-	}
+	return NewWithOptions(extensions, mdxDir, Options{ExcludeDirs: []string{}})
 }
 
 // NewWithExclusions creates a new Scanner instance with directory exclusions
 func NewWithExclusions(extensions []string, mdxDir string, excludeDirs []string) *Scanner {
+	return NewWithOptions(extensions, mdxDir, Options{ExcludeDirs: excludeDirs})
+}
+
+// NewWithOptions creates a new Scanner instance with full configuration, including
+// pluggable filesystems for remote media libraries
+func NewWithOptions(extensions []string, mdxDir string, opts Options) *Scanner {
+	if opts.FileSystem == nil {
+		opts.FileSystem = OSFileSystem{}
+	}
+	if opts.MDXFileSystem == nil {
+		opts.MDXFileSystem = OSFileSystem{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	outputDirs := make(map[string]bool)
+	for _, dir := range append([]string{mdxDir}, opts.OutputDirs...) {
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			outputDirs[abs] = true
+		}
+	}
 	return &Scanner{
-		extensions:  extensions,
-		mdxDir:      mdxDir,
-		excludeDirs: excludeDirs,
+		extensions:      extensions,
+		dirExtensions:   opts.DirExtensions,
+		mdxDir:          mdxDir,
+		excludeDirs:     opts.ExcludeDirs,
+		fs:              opts.FileSystem,
+		mdxFS:           opts.MDXFileSystem,
+		skipTV:          opts.SkipTVFolders,
+		refreshAge:      opts.RefreshOlderThan,
+		rescanUnmatched: opts.RescanUnmatched,
+		trackNFOChanges: opts.TrackNFOChanges,
+		outputDirs:      outputDirs,
+		completed:       opts.Completed,
+		includeBroken:   opts.IncludeBrokenFiles,
+		logger:          opts.Logger,
 	}
 }
 
@@ -67,11 +157,25 @@ func (s *Scanner) IsExcludedDir(dirPath string) bool {
 	return false
 }
 
+// IsOutputDir reports whether dirPath is the MDX directory or another
+// configured output directory, which is never scanned for videos
+func (s *Scanner) IsOutputDir(dirPath string) bool {
+	abs, err := filepath.Abs(dirPath)
+	return err == nil && s.outputDirs[abs]
+}
+
+// IsSkippedTVDir reports whether a directory should be skipped as a TV season
+// folder. Always false unless SkipTVFolders is enabled.
+func (s *Scanner) IsSkippedTVDir(dirPath string) bool {
+	return s.skipTV && IsTVSeasonDir(filepath.Base(dirPath))
+}
+
 // ScanDirectory recursively scans a directory for video files
 func (s *Scanner) ScanDirectory(path string) ([]FileInfo, error) {
 	var files []FileInfo
+	listings := make(map[string][]fs.DirEntry) // directory -> entries, for subtitle lookups
 
-	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+	err := walkFileSystem(s.fs, path, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
 			// Skip directories we can't read
 			if os.IsPermission(err) {
@@ -83,21 +187,38 @@ func (s *Scanner) ScanDirectory(path string) ([]FileInfo, error) {
 		// Skip excluded directories
 		if info.IsDir() {
 			if s.IsExcludedDir(p) {
-				fmt.Printf("Skipping excluded directory: %s\n", p)
+				s.logger.Info("skipping excluded directory", "path", p)
 				return filepath.SkipDir
 			}
+			if s.IsSkippedTVDir(p) {
+				s.logger.Info("skipping tv season directory", "path", p)
+				return filepath.SkipDir
+			}
+			if s.IsOutputDir(p) {
+				s.logger.Info("skipping output directory inside scan directory", "path", p)
+				return filepath.SkipDir
+			}
+			if IsBoxsetDir(info.Name()) {
+				s.logger.Debug("boxset directory detected, processing each video individually", "path", p)
+			}
 			return nil
 		}
 
 		// Check if it's a media file
-		if !s.IsMediaFile(info.Name()) {
+		if !s.IsMediaFileIn(path, info.Name()) {
+			return nil
+		}
+		// Only the cheap size check here; reading is left to files that are
+		// processed, so a rescan does not spin up every disk of the library
+		if s.isEmptyFile(p, info.Size()) {
+			s.broken++
 			return nil
 		}
 
 		// Extract movie information from filename
 		title, year := ExtractTitleAndYear(info.Name())
-		slug := GenerateSlug(title, year)
 		discNumber := ExtractDiscNumber(info.Name())
+		slug := GenerateDiscSlug(title, year, discNumber)
 
 		fileInfo := FileInfo{
 			Path:       p,
@@ -105,10 +226,24 @@ func (s *Scanner) ScanDirectory(path string) ([]FileInfo, error) {
 			Title:      title,
 			Year:       year,
 			Size:       info.Size(),
+			ModTime:    info.ModTime(),
 			Slug:       slug,
 			DiscNumber: discNumber,
-			ShouldScan: !s.MDXExists(slug),
+			ShouldScan: !s.completed[p] && s.needsScan(slug, p),
 			SourceDir:  path,
+			Language:   DetectLanguage(info.Name()),
+			fileID:     fileIdentity(info),
+		}
+		// Files with an MDX are usually not processed; skip reading them and
+		// listing their directories for subtitles
+		if fileInfo.ShouldScan {
+			if s.isUnreadableFile(p) {
+				s.broken++
+				return nil
+			}
+			fileInfo.readChecked = true
+			fileInfo.Subtitles = s.subtitlesFromListing(listings, p)
+			fileInfo.subtitlesChecked = true
 		}
 
 		files = append(files, fileInfo)
@@ -122,10 +257,70 @@ func (s *Scanner) ScanDirectory(path string) ([]FileInfo, error) {
 	return files, nil
 }
 
+// IsBrokenFile reports whether the video at path should be skipped because it
+// is empty or cannot be read, logging a warning. Always false when
+// IncludeBrokenFiles is set.
+func (s *Scanner) IsBrokenFile(path string, size int64) bool {
+	return s.isEmptyFile(path, size) || s.isUnreadableFile(path)
+}
+
+// isEmptyFile is the size half of IsBrokenFile
+func (s *Scanner) isEmptyFile(path string, size int64) bool {
+	if s.includeBroken || size != 0 {
+		return false
+	}
+	s.logger.Warn("skipping zero-byte video file, e.g. a failed download", "path", path)
+	return true
+}
+
+// isUnreadableFile is the read half of IsBrokenFile
+func (s *Scanner) isUnreadableFile(path string) bool {
+	if s.includeBroken {
+		return false
+	}
+	if err := probeReadable(s.fs, path); err != nil {
+		s.logger.Warn("skipping unreadable video file", "path", path, "error", err)
+		return true
+	}
+	return false
+}
+
+// FilterUnreadable drops the files that cannot be read, probing only those
+// ScanAll did not probe because they were not due for a scan (e.g. picked by
+// --force-refresh). Returns the kept files and the number dropped.
+func (s *Scanner) FilterUnreadable(files []FileInfo) ([]FileInfo, int) {
+	kept := files[:0]
+	for _, f := range files {
+		if !f.readChecked && s.isUnreadableFile(f.Path) {
+			continue
+		}
+		f.readChecked = true
+		kept = append(kept, f)
+	}
+	return kept, len(files) - len(kept)
+}
+
+// BrokenFiles returns the number of zero-byte or unreadable videos the last
+// ScanAll skipped
+func (s *Scanner) BrokenFiles() int {
+	return s.broken
+}
+
 // IsMediaFile checks if a filename has a supported video extension
 func (s *Scanner) IsMediaFile(filename string) bool {
+	return s.IsMediaFileIn("", filename)
+}
+
+// IsMediaFileIn checks if a filename found under the scan directory sourceDir
+// has a supported video extension, using that directory's own extension list
+// when it has one
+func (s *Scanner) IsMediaFileIn(sourceDir, filename string) bool {
+	extensions := s.extensions
+	if dirExts, ok := s.dirExtensions[filepath.Clean(sourceDir)]; ok && sourceDir != "" {
+		extensions = dirExts
+	}
 	ext := strings.ToLower(filepath.Ext(filename))
-	for _, validExt := range s.extensions {
+	for _, validExt := range extensions {
 		if ext == strings.ToLower(validExt) {
 			return true
 		}
@@ -136,31 +331,106 @@ func (s *Scanner) IsMediaFile(filename string) bool {
 // MDXExists checks if an MDX file already exists for a given slug
 func (s *Scanner) MDXExists(slug string) bool {
 	mdxPath := filepath.Join(s.mdxDir, slug+".mdx")
-	_, err := os.Stat(mdxPath)
+	_, err := s.mdxFS.Stat(mdxPath)
 	return err == nil
 }
 
-// ScanAll scans all directories and returns combined results
+// needsScan reports whether the video at videoPath, with the given slug,
+// should be processed: when no MDX exists yet, when the MDX is an unmatched
+// placeholder (if enabled), when its .nfo changed (if enabled), or when it was
+// last scanned before the refresh threshold
+func (s *Scanner) needsScan(slug, videoPath string) bool {
+	if !s.MDXExists(slug) {
+		return true
+	}
+	if s.refreshAge <= 0 && !s.rescanUnmatched && s.trackNFOChanges == nil {
+		return false
+	}
+
+	mdxPath := filepath.Join(s.mdxDir, slug+".mdx")
+	content, err := s.mdxFS.ReadFile(mdxPath)
+	if err != nil {
+		s.logger.Debug("failed to read mdx for refresh check", "path", mdxPath, "error", err)
+		return false
+	}
+	if s.rescanUnmatched && IsUnmatchedMDX(content) {
+		s.logger.Debug("mdx is an unmatched placeholder, retrying lookup", "slug", slug)
+		return true
+	}
+	if s.trackNFOChanges != nil && s.trackNFOChanges(videoPath) && nfoChanged(s.fs, videoPath, content, s.logger) {
+		s.logger.Debug("nfo changed since last scan, refreshing", "slug", slug)
+		return true
+	}
+	if s.refreshAge <= 0 {
+		return false
+	}
+	scannedAt, ok := mdxScannedAt(content)
+	if !ok {
+		s.logger.Debug("mdx has no scannedAt, not refreshing", "path", mdxPath)
+		return false
+	}
+	if time.Since(scannedAt) <= s.refreshAge {
+		return false
+	}
+	s.logger.Debug("mdx is stale, refreshing", "slug", slug, "scanned_at", scannedAt)
+	return true
+}
+
+// mdxScannedAt returns the scannedAt timestamp from MDX frontmatter
+func mdxScannedAt(content []byte) (time.Time, bool) {
+	var fm struct {
+		ScannedAt time.Time `yaml:"scannedAt"`
+	}
+	if !parseFrontmatter(content, &fm) || fm.ScannedAt.IsZero() {
+		return time.Time{}, false
+	}
+	return fm.ScannedAt, true
+}
+
+// IsUnmatchedMDX reports whether MDX frontmatter is tagged "unmatched: true"
+func IsUnmatchedMDX(content []byte) bool {
+	var fm struct {
+		Unmatched bool `yaml:"unmatched"`
+	}
+	return parseFrontmatter(content, &fm) && fm.Unmatched
+}
+
+// parseFrontmatter decodes the YAML frontmatter of MDX content into v
+func parseFrontmatter(content []byte, v any) bool {
+	contentStr := string(content)
+	if !strings.HasPrefix(contentStr, "---") {
+		return false
+	}
+	endIndex := strings.Index(contentStr[3:], "---")
+	if endIndex == -1 {
+		return false
+	}
+	return yaml.Unmarshal([]byte(contentStr[3:endIndex+3]), v) == nil
+}
+
+// ScanAll scans all directories and returns combined results. Hardlinks to the
+// same physical file are collapsed to the path found first (see collapseHardlinks).
 func (s *Scanner) ScanAll(directories []string) ([]FileInfo, error) {
 	var allFiles []FileInfo
+	s.broken = 0
 
 	for _, dir := range directories {
 		// Check if directory exists
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			fmt.Printf("Warning: Directory does not exist: %s\n", dir)
+		if _, err := s.fs.Stat(dir); os.IsNotExist(err) {
+			s.logger.Warn("scan directory does not exist", "dir", dir)
 			continue
 		}
 
 		files, err := s.ScanDirectory(dir)
 		if err != nil {
-			slog.Warn("failed to scan directory, skipping", "dir", dir, "error", err)
+			s.logger.Warn("failed to scan directory, skipping", "dir", dir, "error", err)
 			continue
 		}
 
 		allFiles = append(allFiles, files...)
 	}
 
-	return allFiles, nil
+	return collapseHardlinks(allFiles, s.logger), nil
 }
 
 // discGroupKey is the grouping key for multi-disc files: same directory + same movie.
@@ -170,6 +440,18 @@ type discGroupKey struct {
 	Year  int
 }
 
+// FilterModifiedSince returns the files whose modification time is after since.
+// Original order is preserved.
+func FilterModifiedSince(files []FileInfo, since time.Time) []FileInfo {
+	var result []FileInfo
+	for _, f := range files {
+		if f.ModTime.After(since) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 // FilterMultiDiscDuplicates removes secondary discs (CD2+) when a CD1 sibling exists
 // in the same directory for the same movie. Non-disc files and lone secondary discs
 // (no CD1 present) are left untouched. Original order is preserved.
@@ -244,9 +526,21 @@ func FilterMultiDiscDuplicates(files []FileInfo) ([]FileInfo, []SkippedDisc) {
 }
 
 // PrimarySiblingExists checks whether a disc-1 sibling for the given file exists
-// in the same directory. Used by watch mode, which processes files one at a time.
+// in the same directory on the local disk. Used by watch mode, which processes
+// files one at a time.
 func PrimarySiblingExists(file FileInfo, extensions []string) bool {
-	entries, err := os.ReadDir(filepath.Dir(file.Path))
+	return primarySiblingExists(OSFileSystem{}, file, extensions)
+}
+
+// PrimarySiblingExists is the package-level PrimarySiblingExists, listing the
+// directory through the scanner's FileSystem
+func (s *Scanner) PrimarySiblingExists(file FileInfo, extensions []string) bool {
+	return primarySiblingExists(s.fs, file, extensions)
+}
+
+// primarySiblingExists implements PrimarySiblingExists on top of fsys
+func primarySiblingExists(fsys FileSystem, file FileInfo, extensions []string) bool {
+	entries, err := fsys.ReadDir(filepath.Dir(file.Path))
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterModifiedSince(t *testing.T) {
+	since := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	files := []FileInfo{
+		{FileName: "old.mkv", ModTime: since.Add(-24 * time.Hour)},
+		{FileName: "boundary.mkv", ModTime: since},
+		{FileName: "just-after.mkv", ModTime: since.Add(time.Second)},
+		{FileName: "new.mkv", ModTime: since.Add(48 * time.Hour)},
+	}
+
+	testCases := []struct {
+		name  string
+		since time.Time
+		want  []string
+	}{
+		{"boundary is excluded", since, []string{"just-after.mkv", "new.mkv"}},
+		{"zero time keeps everything", time.Time{}, []string{"old.mkv", "boundary.mkv", "just-after.mkv", "new.mkv"}},
+		{"future keeps nothing", since.Add(72 * time.Hour), nil},
+		{"other time zone", since.In(time.FixedZone("CEST", 2*60*60)), []string{"just-after.mkv", "new.mkv"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FilterModifiedSince(files, tc.since)
+			if len(got) != len(tc.want) {
+				t.Fatalf("FilterModifiedSince() returned %d files, want %v", len(got), tc.want)
+			}
+			for i, f := range got {
+				if f.FileName != tc.want[i] {
+					t.Errorf("file %d = %s, want %s", i, f.FileName, tc.want[i])
+				}
+			}
+		})
+	}
+}
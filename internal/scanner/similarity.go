@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TitleSimilarity scores how alike two movie titles are, from 0 (nothing in
+// common) to 1 (identical once case, punctuation, "&"/"and", and a leading
+// article are ignored). The score is the better of a character-level ratio
+// (edit distance, tolerant of typos) and a word-level Dice coefficient
+// (tolerant of reordered or extra words).
+func TitleSimilarity(a, b string) float64 {
+	wordsA, wordsB := titleWords(a), titleWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		if len(wordsA) == len(wordsB) {
+			return 1
+		}
+		return 0
+	}
+
+	joinedA, joinedB := []rune(strings.Join(wordsA, " ")), []rune(strings.Join(wordsB, " "))
+	longest := max(len(joinedA), len(joinedB))
+	charScore := 1 - float64(levenshtein(joinedA, joinedB))/float64(longest)
+
+	counts := make(map[string]int, len(wordsA))
+	for _, w := range wordsA {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range wordsB {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	wordScore := 2 * float64(common) / float64(len(wordsA)+len(wordsB))
+
+	return max(charScore, wordScore)
+}
+
+// titleWords lowercases a title and splits it into words, dropping
+// punctuation and a leading (or library-style trailing ", The") article and
+// spelling out "&"
+func titleWords(title string) []string {
+	title = strings.ReplaceAll(strings.ToLower(title), "&", " and ")
+	for _, article := range []string{", the", ", a", ", an"} {
+		title = strings.TrimSuffix(strings.TrimSpace(title), article)
+	}
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 {
+		switch words[0] {
+		case "the", "a", "an":
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
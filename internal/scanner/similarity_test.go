@@ -0,0 +1,29 @@
+package scanner
+
+import "testing"
+
+func TestTitleSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{"The Matrix", "The Matrix", 1, 1.01},
+		{"the matrix", "Matrix, The", 1, 1.01},
+		{"Fast & Furious", "Fast and Furious", 1, 1.01},
+		{"Spider Man No Way Home", "Spider-Man: No Way Home", 1, 1.01},
+		{"Amelie", "Amélie", 0.8, 1},
+		{"Lord of the Rings", "The Lord of the Rings: The Fellowship of the Ring", 0.5, 1},
+		{"Frozen", "Frozen River", 0.6, 0.8},
+		{"Heat", "The Matrix", 0, 0.3},
+		{"", "", 1, 1.01},
+		{"Heat", "", 0, 0.01},
+	}
+
+	for _, tc := range testCases {
+		got := TitleSimilarity(tc.a, tc.b)
+		if got < tc.atLeast || got >= tc.below {
+			t.Errorf("TitleSimilarity(%q, %q) = %.2f, want in [%.2f, %.2f)", tc.a, tc.b, got, tc.atLeast, tc.below)
+		}
+	}
+}
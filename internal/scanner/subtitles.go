@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// subtitleExtensions lists the external subtitle formats detected next to a video
+var subtitleExtensions = []string{".srt", ".ass", ".sub"}
+
+// unknownSubtitleLanguage is recorded for subtitle files without a recognizable language code
+const unknownSubtitleLanguage = "unknown"
+
+// FindSubtitles returns the languages of external subtitle files that sit next to
+// videoPath and share its basename (e.g. "Movie.2010.ita.srt" for "Movie.2010.mkv").
+// Languages are parsed from the part of the subtitle name after the video basename;
+// files without a recognizable code are reported as "unknown". The result is sorted
+// and de-duplicated, and nil when no subtitle files are found.
+func FindSubtitles(videoPath string) []string {
+	return findSubtitles(OSFileSystem{}, videoPath)
+}
+
+// findSubtitles implements FindSubtitles on top of fsys
+func findSubtitles(fsys FileSystem, videoPath string) []string {
+	// ReadDir instead of Glob: release names often contain brackets, which are glob metacharacters
+	entries, err := fsys.ReadDir(filepath.Dir(videoPath))
+	if err != nil {
+		return nil
+	}
+	return subtitleLanguages(entries, videoPath)
+}
+
+// subtitleLanguages returns the languages of the subtitle files among the
+// directory entries of videoPath that belong to it: the name must be the video
+// basename followed by a dot, so "Aliens.eng.srt" is not taken for "Alien.mkv".
+func subtitleLanguages(entries []fs.DirEntry, videoPath string) []string {
+	base := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !isSubtitleFile(entry.Name()) {
+			continue
+		}
+
+		name := entry.Name()
+		if !strings.HasPrefix(name, base+".") {
+			continue
+		}
+
+		// Only inspect the suffix so language-like words in the title are ignored
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, base), filepath.Ext(name))
+		matches := languagePattern.FindAllString(suffix, -1)
+		if len(matches) == 0 {
+			seen[unknownSubtitleLanguage] = true
+			continue
+		}
+		for _, match := range matches {
+			seen[strings.ToLower(match)] = true
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	languages := make([]string, 0, len(seen))
+	for lang := range seen {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// AttachSubtitles fills in the subtitle languages of files that ScanAll left
+// out because they were not due for a scan, e.g. when --force-refresh
+// processes them anyway. Each directory is listed once.
+func (s *Scanner) AttachSubtitles(files []FileInfo) {
+	listings := make(map[string][]fs.DirEntry)
+	for i := range files {
+		if files[i].subtitlesChecked {
+			continue
+		}
+		files[i].Subtitles = s.subtitlesFromListing(listings, files[i].Path)
+		files[i].subtitlesChecked = true
+	}
+}
+
+// subtitlesFromListing returns the subtitle languages of videoPath, listing
+// its directory through listings so each directory is read only once
+func (s *Scanner) subtitlesFromListing(listings map[string][]fs.DirEntry, videoPath string) []string {
+	dir := filepath.Dir(videoPath)
+	entries, ok := listings[dir]
+	if !ok {
+		// ReadDir instead of Glob: release names often contain brackets, which are glob metacharacters
+		entries, _ = s.fs.ReadDir(dir)
+		listings[dir] = entries
+	}
+	return subtitleLanguages(entries, videoPath)
+}
+
+// isSubtitleFile checks if a filename has a supported subtitle extension
+func isSubtitleFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, subExt := range subtitleExtensions {
+		if ext == subExt {
+			return true
+		}
+	}
+	return false
+}
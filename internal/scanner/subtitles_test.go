@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFindSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"Inception [2010] ita.mkv",
+		"Inception [2010] ita.eng.srt",
+		"Inception [2010] ita.ita.forced.ass",
+		"Inception [2010] ita.sub",
+		"Inception [2010] ita.nfo",
+		"Other Movie.spa.srt",
+		"Lonely.mkv",
+		"Alien.mkv",
+		"Aliens.eng.srt",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	got := FindSubtitles(filepath.Join(dir, "Inception [2010] ita.mkv"))
+	expected := []string{"eng", "ita", "unknown"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("FindSubtitles() = %v, want %v", got, expected)
+	}
+
+	if got := FindSubtitles(filepath.Join(dir, "Lonely.mkv")); got != nil {
+		t.Errorf("FindSubtitles() for video without subs = %v, want nil", got)
+	}
+
+	// A longer title sharing the basename as a prefix is another movie
+	if got := FindSubtitles(filepath.Join(dir, "Alien.mkv")); got != nil {
+		t.Errorf("FindSubtitles() for Alien.mkv = %v, want nil", got)
+	}
+}
+
+// countingFileSystem counts ReadDir calls per directory
+type countingFileSystem struct {
+	mapFileSystem
+	reads map[string]int
+}
+
+func (c countingFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.reads[name]++
+	return c.mapFileSystem.ReadDir(name)
+}
+
+func TestScanAll_SubtitlesOnlyForFilesToScan(t *testing.T) {
+	media := countingFileSystem{mapFileSystem: mapFileSystem{fstest.MapFS{
+		"library/Alien.1979.mkv":      {Data: []byte("video")},
+		"library/Alien.1979.eng.srt":  {},
+		"library/Aliens.1986.mkv":     {Data: []byte("video")},
+		"library/Aliens.1986.ita.srt": {},
+		"library/Heat.1995.mkv":       {Data: []byte("video")},
+		"library/Heat.1995.spa.srt":   {},
+	}}, reads: make(map[string]int)}
+	mdx := mapFileSystem{fstest.MapFS{
+		"mdx/heat-1995.mdx": {},
+	}}
+
+	s := NewWithOptions([]string{".mkv"}, "/mdx", Options{
+		FileSystem:    media,
+		MDXFileSystem: mdx,
+	})
+
+	files, err := s.ScanAll([]string{"/library"})
+	if err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d: %+v", len(files), files)
+	}
+
+	// The walk lists the directory once; subtitle lookups reuse that listing
+	walkReads := media.reads["/library"]
+	if walkReads != 2 {
+		t.Errorf("expected the walk and one subtitle listing of /library, got %d ReadDir calls", walkReads)
+	}
+
+	want := map[string][]string{
+		"alien-1979":  {"eng"},
+		"aliens-1986": {"ita"},
+		"heat-1995":   nil, // has an MDX, not looked up
+	}
+	for _, f := range files {
+		if !reflect.DeepEqual(f.Subtitles, want[f.Slug]) {
+			t.Errorf("%s: Subtitles = %v, want %v", f.Slug, f.Subtitles, want[f.Slug])
+		}
+	}
+
+	// --force-refresh processes heat too; its subtitles are filled in then
+	s.AttachSubtitles(files)
+	want["heat-1995"] = []string{"spa"}
+	for _, f := range files {
+		if !reflect.DeepEqual(f.Subtitles, want[f.Slug]) {
+			t.Errorf("after AttachSubtitles, %s: Subtitles = %v, want %v", f.Slug, f.Subtitles, want[f.Slug])
+		}
+	}
+	if got := media.reads["/library"]; got != walkReads+1 {
+		t.Errorf("expected AttachSubtitles to list /library once, got %d extra ReadDir calls", got-walkReads)
+	}
+}
@@ -25,6 +25,8 @@ type Watcher struct {
 	watcher       *fsnotify.Watcher
 	stopChan      chan struct{}
 	doneChan      chan struct{}
+	slots         chan struct{} // semaphore bounding concurrent handler calls
+	logger        *slog.Logger
 
 	// Debouncing state
 	mu            sync.Mutex
@@ -36,10 +38,16 @@ type Watcher struct {
 type WatcherConfig struct {
 	Directories   []string
 	Extensions    []string
+	DirExtensions map[string][]string // Per-directory extension lists (see Options.DirExtensions)
 	MDXDir        string
+	OutputDirs    []string // Output directories besides MDXDir that are never watched (e.g. covers)
 	ExcludeDirs   []string
 	DebounceDelay time.Duration // How long to wait after last event before processing
 	Recursive     bool          // Watch subdirectories
+	SkipTVFolders bool          // Don't watch directories that look like TV seasons
+	MaxConcurrent int           // Files processed at once; the rest wait their turn (default: 1)
+	IncludeBroken bool          // Process zero-byte and unreadable files (see Options.IncludeBrokenFiles)
+	Logger        *slog.Logger  // Receives watcher and scanner log messages (default: slog.Default())
 }
 
 // NewWatcher creates a new directory watcher
@@ -49,7 +57,22 @@ func NewWatcher(cfg WatcherConfig, handler FileHandler) (*Watcher, error) {
 		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
 	}
 
-	s := NewWithExclusions(cfg.Extensions, cfg.MDXDir, cfg.ExcludeDirs)
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	s := NewWithOptions(cfg.Extensions, cfg.MDXDir, Options{
+		ExcludeDirs:        cfg.ExcludeDirs,
+		SkipTVFolders:      cfg.SkipTVFolders,
+		DirExtensions:      cfg.DirExtensions,
+		OutputDirs:         cfg.OutputDirs,
+		IncludeBrokenFiles: cfg.IncludeBroken,
+		Logger:             cfg.Logger,
+	})
+
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
 
 	return &Watcher{
 		scanner:       s,
@@ -60,8 +83,10 @@ func NewWatcher(cfg WatcherConfig, handler FileHandler) (*Watcher, error) {
 		watcher:       fsWatcher,
 		stopChan:      make(chan struct{}),
 		doneChan:      make(chan struct{}),
+		slots:         make(chan struct{}, cfg.MaxConcurrent),
 		pendingFiles:  make(map[string]time.Time),
 		pendingTimers: make(map[string]*time.Timer),
+		logger:        cfg.Logger,
 	}, nil
 }
 
@@ -70,17 +95,18 @@ func (w *Watcher) Start() error {
 	// Add all configured directories to watch
 	for _, dir := range w.directories {
 		if err := w.addDirectory(dir); err != nil {
-			slog.Warn("failed to watch directory", "path", dir, "error", err)
+			w.logger.Warn("failed to watch directory", "path", dir, "error", err)
 		}
 	}
 
 	// Start event processing goroutine
 	go w.processEvents()
 
-	slog.Info("file watcher started",
+	w.logger.Info("file watcher started",
 		"directories", len(w.directories),
 		"debounce_seconds", w.debounceDelay.Seconds(),
 		"recursive", w.recursive,
+		"max_concurrent", cap(w.slots),
 	)
 
 	return nil
@@ -126,13 +152,21 @@ func (w *Watcher) addDirectory(path string) error {
 			if info.IsDir() {
 				// Skip excluded directories
 				if w.scanner.IsExcludedDir(p) {
-					slog.Debug("skipping excluded directory", "path", p)
+					w.logger.Debug("skipping excluded directory", "path", p)
+					return filepath.SkipDir
+				}
+				if w.scanner.IsSkippedTVDir(p) {
+					w.logger.Debug("skipping tv season directory", "path", p)
+					return filepath.SkipDir
+				}
+				if w.scanner.IsOutputDir(p) {
+					w.logger.Debug("skipping output directory", "path", p)
 					return filepath.SkipDir
 				}
 				if err := w.watcher.Add(p); err != nil {
-					slog.Warn("failed to add directory to watch", "path", p, "error", err)
+					w.logger.Warn("failed to add directory to watch", "path", p, "error", err)
 				} else {
-					slog.Debug("watching directory", "path", p)
+					w.logger.Debug("watching directory", "path", p)
 				}
 			}
 			return nil
@@ -143,7 +177,7 @@ func (w *Watcher) addDirectory(path string) error {
 	if err := w.watcher.Add(path); err != nil {
 		return fmt.Errorf("failed to add directory to watch: %w", err)
 	}
-	slog.Debug("watching directory", "path", path)
+	w.logger.Debug("watching directory", "path", path)
 	return nil
 }
 
@@ -166,7 +200,7 @@ func (w *Watcher) processEvents() {
 			if !ok {
 				return
 			}
-			slog.Error("watcher error", "error", err)
+			w.logger.Error("watcher error", "error", err)
 		}
 	}
 }
@@ -178,11 +212,11 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	// Handle directory events
 	if event.Has(fsnotify.Create) {
 		if info, err := os.Stat(path); err == nil && info.IsDir() {
-			if w.recursive && !w.scanner.IsExcludedDir(path) {
+			if w.recursive && !w.scanner.IsExcludedDir(path) && !w.scanner.IsSkippedTVDir(path) && !w.scanner.IsOutputDir(path) {
 				if err := w.addDirectory(path); err != nil {
-					slog.Warn("failed to add new directory to watch", "path", path, "error", err)
+					w.logger.Warn("failed to add new directory to watch", "path", path, "error", err)
 				} else {
-					slog.Info("new directory detected, now watching", "path", path)
+					w.logger.Info("new directory detected, now watching", "path", path)
 				}
 			}
 			return
@@ -195,19 +229,19 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		// fsnotify auto-removes deleted directories from watch list
 		// but we should log this for visibility
 		if w.wasWatchedDirectory(path) {
-			slog.Info("watched directory removed", "path", path)
+			w.logger.Info("watched directory removed", "path", path)
 		}
 	}
 
 	// Only process files with matching extensions
 	filename := filepath.Base(path)
-	if !w.scanner.IsMediaFile(filename) {
+	if !w.scanner.IsMediaFileIn(w.sourceDir(path), filename) {
 		return
 	}
 
 	// Handle file deletion - log warning but don't delete MDX (US-023)
 	if event.Has(fsnotify.Remove) {
-		slog.Warn("media file deleted",
+		w.logger.Warn("media file deleted",
 			"file", filename,
 			"path", path,
 			"note", "MDX file not deleted - manual cleanup may be needed",
@@ -221,7 +255,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	// fsnotify sends Rename for both the old path (file disappearing) and sometimes
 	// Create for the new path (file appearing). On some systems, only Rename is sent.
 	if event.Has(fsnotify.Rename) {
-		slog.Info("media file renamed/moved",
+		w.logger.Info("media file renamed/moved",
 			"file", filename,
 			"path", path,
 			"note", "file may have been moved out of watched directory",
@@ -232,14 +266,14 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		// Check if the file still exists at this path (it usually won't after rename)
 		// The new location, if within watched dirs, will trigger a Create event
 		if _, err := os.Stat(path); os.IsNotExist(err) {
-			slog.Debug("file no longer at original path after rename", "path", path)
+			w.logger.Debug("file no longer at original path after rename", "path", path)
 		}
 		return
 	}
 
 	// Handle file creation and write events (new files or file modifications)
 	if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
-		slog.Debug("file event detected",
+		w.logger.Debug("file event detected",
 			"event", event.Op.String(),
 			"file", filename,
 		)
@@ -265,27 +299,37 @@ func (w *Watcher) scheduleProcessing(path string) {
 		w.processFile(path)
 	})
 
-	slog.Debug("file scheduled for processing",
+	w.logger.Debug("file scheduled for processing",
 		"file", filepath.Base(path),
 		"debounce_seconds", w.debounceDelay.Seconds(),
 	)
 }
 
-// processFile processes a single file after debounce period
+// processFile processes a single file after debounce period. When many
+// debounce timers fire together, at most MaxConcurrent files are processed at
+// once and the rest wait here for a free slot.
 func (w *Watcher) processFile(path string) {
 	w.mu.Lock()
 	delete(w.pendingFiles, path)
 	delete(w.pendingTimers, path)
 	w.mu.Unlock()
 
+	select {
+	case w.slots <- struct{}{}:
+		defer func() { <-w.slots }()
+	case <-w.stopChan:
+		w.logger.Debug("watcher stopped, dropping queued file", "path", path)
+		return
+	}
+
 	// Verify file still exists (might have been moved/deleted)
 	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			slog.Debug("file no longer exists, skipping", "path", path)
+			w.logger.Debug("file no longer exists, skipping", "path", path)
 			return
 		}
-		slog.Error("failed to stat file", "path", path, "error", err)
+		w.logger.Error("failed to stat file", "path", path, "error", err)
 		return
 	}
 
@@ -294,10 +338,16 @@ func (w *Watcher) processFile(path string) {
 		return
 	}
 
+	// Skip failed downloads and files we cannot read
+	if w.scanner.IsBrokenFile(path, info.Size()) {
+		return
+	}
+
 	// Extract movie information from filename
 	filename := filepath.Base(path)
 	title, year := ExtractTitleAndYear(filename)
-	slug := GenerateSlug(title, year)
+	discNumber := ExtractDiscNumber(filename)
+	slug := GenerateDiscSlug(title, year, discNumber)
 
 	fileInfo := FileInfo{
 		Path:       path,
@@ -305,28 +355,49 @@ func (w *Watcher) processFile(path string) {
 		Title:      title,
 		Year:       year,
 		Size:       info.Size(),
+		ModTime:    info.ModTime(),
 		Slug:       slug,
-		DiscNumber: ExtractDiscNumber(filename),
+		DiscNumber: discNumber,
 		ShouldScan: !w.scanner.MDXExists(slug),
+		SourceDir:  w.sourceDir(path),
+		Subtitles:  FindSubtitles(path),
+		Language:   DetectLanguage(filename),
 	}
 
 	// Skip if MDX already exists
 	if !fileInfo.ShouldScan {
-		slog.Debug("mdx already exists, skipping", "file", filename, "slug", slug)
+		w.logger.Debug("mdx already exists, skipping", "file", filename, "slug", slug)
 		return
 	}
 
-	slog.Info("processing new file", "file", filename, "title", title, "year", year)
+	w.logger.Info("processing new file", "file", filename, "title", title, "year", year)
 
 	// Call the handler
 	if err := w.handler(fileInfo); err != nil {
-		slog.Error("failed to process file", "file", filename, "error", err)
+		w.logger.Error("failed to process file", "file", filename, "error", err)
 	}
 }
 
 // IsValidMediaFile checks if a path is a valid media file for the configured extensions
 func (w *Watcher) IsValidMediaFile(path string) bool {
-	return w.scanner.IsMediaFile(filepath.Base(path))
+	return w.scanner.IsMediaFileIn(w.sourceDir(path), filepath.Base(path))
+}
+
+// sourceDir returns the watched root directory containing path (the deepest
+// one when roots are nested), or "" when path is outside all of them
+func (w *Watcher) sourceDir(path string) string {
+	var root string
+	for _, dir := range w.directories {
+		dir = filepath.Clean(dir)
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(dir) > len(root) {
+			root = dir
+		}
+	}
+	return root
 }
 
 // cancelPending cancels any pending processing for a file path (US-023)
@@ -338,7 +409,7 @@ func (w *Watcher) cancelPending(path string) {
 		timer.Stop()
 		delete(w.pendingTimers, path)
 		delete(w.pendingFiles, path)
-		slog.Debug("cancelled pending processing", "path", path)
+		w.logger.Debug("cancelled pending processing", "path", path)
 	}
 }
 
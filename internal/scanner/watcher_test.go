@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatcherProcessFile_BoundsConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 10; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("Movie %d (2000).mkv", i))
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	var running, maxRunning, handled int32
+	handler := func(file FileInfo) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&handled, 1)
+		return nil
+	}
+
+	w, err := NewWatcher(WatcherConfig{
+		Directories:   []string{dir},
+		Extensions:    []string{".mkv"},
+		MDXDir:        t.TempDir(),
+		MaxConcurrent: 3,
+	}, handler)
+	if err != nil {
+		t.Fatalf("NewWatcher() error: %v", err)
+	}
+	defer w.watcher.Close()
+
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			w.processFile(path)
+		}(path)
+	}
+	wg.Wait()
+
+	if handled != int32(len(paths)) {
+		t.Errorf("handled %d files, want %d", handled, len(paths))
+	}
+	if maxRunning > 3 {
+		t.Errorf("max concurrent handlers = %d, want at most 3", maxRunning)
+	}
+}
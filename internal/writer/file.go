@@ -0,0 +1,34 @@
+package writer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a uniquely named temporary file next to path
+// and renames it into place, so readers never see a partially written file and
+// concurrent writers of the same path never share a temporary file. The file
+// gets mode 0644, so the website can serve it.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
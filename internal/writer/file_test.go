@@ -0,0 +1,56 @@
+package writer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "heat-1995.mdx")
+
+	// Concurrent writers each rename a complete file into place
+	contents := [][]byte{bytes.Repeat([]byte("a"), 1<<16), bytes.Repeat([]byte("b"), 1<<16)}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(data []byte) {
+			defer wg.Done()
+			if err := WriteFileAtomic(path, data); err != nil {
+				t.Errorf("WriteFileAtomic failed: %v", err)
+			}
+		}(contents[i%2])
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, contents[0]) && !bytes.Equal(data, contents[1]) {
+		t.Error("file content is a mix of two writes")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	// The directory is not created
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "x.mdx"), []byte("x")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
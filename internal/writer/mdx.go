@@ -1,30 +1,142 @@
 package writer
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/marco/movieVault/internal/diskspace"
+	"github.com/marco/movieVault/internal/retry"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrMDXExists is returned by WriteMDXFile when the target file already exists
+// and the writer is configured to never overwrite existing MDX files
+var ErrMDXExists = errors.New("mdx file already exists")
+
+// DefaultDateFormat is the Go time layout used for dates in the MDX body
+const DefaultDateFormat = "January 2, 2006"
+
+// Cover image layouts for MDXWriterOptions.CoversLayout
+const (
+	CoversLayoutFlat    = "flat"    // covers/the-matrix-1999.jpg
+	CoversLayoutSharded = "sharded" // covers/th/the-matrix-1999.jpg, by first two slug characters
+)
+
 // MDXWriter handles writing movie data to MDX files
 type MDXWriter struct {
-	mdxDir     string
-	coversDir  string
+	mdxDir         string
+	coversDir      string
+	neverOverwrite bool
+	dateFormat     string
+	coversLayout   string
+	storeImageURLs bool
+	jsonDir        string
+	minFreeSpaceMB int
+	fsWriteRetries int
+	sortArticles   []string
+	logger         *slog.Logger
+}
+
+// MDXWriterOptions holds optional MDX writer behavior
+type MDXWriterOptions struct {
+	NeverOverwrite bool   // Skip writing when the MDX file already exists
+	DateFormat     string // Go time layout for dates in the MDX body (default: DefaultDateFormat)
+	CoversLayout   string // CoversLayoutFlat (default) or CoversLayoutSharded
+	StoreImageURLs bool   // Add remote posterUrl/backdropUrl to the frontmatter
+	JSONDir        string // Also write the movie as <slug>.json into this directory ("" = no sidecar)
+	MinFreeSpaceMB int    // Refuse to write when the MDX disk has less free space (0 = no check)
+	FSWriteRetries int    // Extra attempts for failed MDX and JSON writes (0 = no retry)
+	// SortArticles enables sortTitle in the frontmatter, moving these leading
+	// articles to the end of the title (nil = no sortTitle)
+	SortArticles []string
+	// Logger receives the writer's log messages (default: slog.Default())
+	Logger *slog.Logger
 }
 
 // NewMDXWriter creates a new MDX writer
 func NewMDXWriter(mdxDir, coversDir string) *MDXWriter {
+	return NewMDXWriterWithOptions(mdxDir, coversDir, MDXWriterOptions{})
+}
+
+// NewMDXWriterWithOptions creates a new MDX writer with optional behavior
+func NewMDXWriterWithOptions(mdxDir, coversDir string, opts MDXWriterOptions) *MDXWriter {
+	if opts.DateFormat == "" {
+		opts.DateFormat = DefaultDateFormat
+	}
+	if opts.CoversLayout == "" {
+		opts.CoversLayout = CoversLayoutFlat
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
 	return &MDXWriter{
-		mdxDir:    mdxDir,
-		coversDir: coversDir,
+		mdxDir:         mdxDir,
+		coversDir:      coversDir,
+		neverOverwrite: opts.NeverOverwrite,
+		dateFormat:     opts.DateFormat,
+		coversLayout:   opts.CoversLayout,
+		storeImageURLs: opts.StoreImageURLs,
+		jsonDir:        opts.JSONDir,
+		minFreeSpaceMB: opts.MinFreeSpaceMB,
+		fsWriteRetries: opts.FSWriteRetries,
+		sortArticles:   opts.SortArticles,
+		logger:         opts.Logger,
+	}
+}
+
+// MDXExists reports whether an MDX file already exists for the given slug
+func (w *MDXWriter) MDXExists(slug string) bool {
+	_, err := os.Stat(w.GetMDXPath(slug))
+	return err == nil
+}
+
+// GetMDXPath returns the file path of the MDX file for the given slug
+func (w *MDXWriter) GetMDXPath(slug string) string {
+	return filepath.Join(w.mdxDir, slug+".mdx")
+}
+
+// GetJSONPath returns the file path of the JSON sidecar for the given slug, or
+// "" when sidecars are disabled
+func (w *MDXWriter) GetJSONPath(slug string) string {
+	if w.jsonDir == "" {
+		return ""
 	}
+	return filepath.Join(w.jsonDir, slug+".json")
+}
+
+// RemoveMDX deletes the MDX file for slug along with its JSON sidecar, if any
+func (w *MDXWriter) RemoveMDX(slug string) error {
+	if err := os.Remove(w.GetMDXPath(slug)); err != nil {
+		return err
+	}
+	if w.jsonDir == "" {
+		return nil
+	}
+	if err := os.Remove(w.GetJSONPath(slug)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove JSON sidecar: %w", err)
+	}
+	return nil
 }
 
-// WriteMDXFile writes a movie to an MDX file
+// WriteMDXFile writes a movie to an MDX file, and to its JSON sidecar when enabled.
+// Returns ErrMDXExists without writing when never-overwrite is enabled and the file exists.
 func (w *MDXWriter) WriteMDXFile(movie *Movie) error {
+	if w.neverOverwrite && w.MDXExists(movie.Slug) {
+		return ErrMDXExists
+	}
+
+	// Recompute the sort title, so a renamed movie or a disabled option never
+	// keeps the one read from an existing MDX
+	movie.SortTitle = ""
+	if w.sortArticles != nil {
+		movie.SortTitle = SortTitle(movie.Title, w.sortArticles)
+	}
+
 	// Generate MDX content
 	content, err := w.GenerateMDX(movie)
 	if err != nil {
@@ -35,13 +147,32 @@ func (w *MDXWriter) WriteMDXFile(movie *Movie) error {
 	if err := os.MkdirAll(w.mdxDir, 0755); err != nil {
 		return fmt.Errorf("failed to create MDX directory: %w", err)
 	}
+	if err := diskspace.Check(w.mdxDir, w.minFreeSpaceMB); err != nil {
+		return fmt.Errorf("refusing to write MDX file: %w", err)
+	}
 
-	// Write to file
+	// Write to a temporary file and rename it into place, so a full disk
+	// never leaves a truncated MDX behind
 	filePath := filepath.Join(w.mdxDir, movie.Slug+".mdx")
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	err = retry.RetryLocalWithLogger(func() error {
+		return WriteFileAtomic(filePath, []byte(content))
+	}, w.fsWriteRetries, w.logger)
+	if err != nil {
 		return fmt.Errorf("failed to write MDX file: %w", err)
 	}
 
+	if w.jsonDir != "" {
+		err := retry.RetryLocalWithLogger(func() error {
+			if err := os.MkdirAll(w.jsonDir, 0755); err != nil {
+				return err
+			}
+			return writeJSONFile(w.GetJSONPath(movie.Slug), movie)
+		}, w.fsWriteRetries, w.logger)
+		if err != nil {
+			return fmt.Errorf("failed to write JSON sidecar: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -60,6 +191,10 @@ func (w *MDXWriter) GenerateMDX(movie *Movie) (string, error) {
 		return "", fmt.Errorf("failed to marshal movie to YAML: %w", err)
 	}
 	forceQuotedFields(&docNode, "filePath", "fileName")
+	if w.storeImageURLs {
+		appendStringField(&docNode, "posterUrl", remoteURL(movie.PosterURL))
+		appendStringField(&docNode, "backdropUrl", remoteURL(movie.BackdropURL))
+	}
 	yamlData, err := yaml.Marshal(&docNode)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal movie to YAML: %w", err)
@@ -89,6 +224,14 @@ func (w *MDXWriter) GenerateMDX(movie *Movie) (string, error) {
 		sb.WriteString(fmt.Sprintf("- **Rating**: %.1f/10\n", movie.Rating))
 	}
 
+	if movie.IMDbRating > 0 {
+		sb.WriteString(fmt.Sprintf("- **IMDb Rating**: %.1f/10\n", movie.IMDbRating))
+	}
+
+	if movie.RottenTomatoes > 0 {
+		sb.WriteString(fmt.Sprintf("- **Rotten Tomatoes**: %d%%\n", movie.RottenTomatoes))
+	}
+
 	if movie.Runtime > 0 {
 		sb.WriteString(fmt.Sprintf("- **Runtime**: %d minutes\n", movie.Runtime))
 	}
@@ -105,18 +248,59 @@ func (w *MDXWriter) GenerateMDX(movie *Movie) (string, error) {
 		sb.WriteString(fmt.Sprintf("- **Cast**: %s\n", strings.Join(movie.Cast, ", ")))
 	}
 
+	if movie.Budget > 0 {
+		sb.WriteString(fmt.Sprintf("- **Budget**: %s\n", FormatUSD(movie.Budget)))
+	}
+
+	if movie.Revenue > 0 {
+		sb.WriteString(fmt.Sprintf("- **Revenue**: %s\n", FormatUSD(movie.Revenue)))
+	}
+
 	sb.WriteString("\n")
 
+	// Technical section, from ffprobe or the filename
+	if movie.Resolution != "" || movie.VideoCodec != "" || movie.AudioCodec != "" || movie.Duration > 0 {
+		sb.WriteString("## Technical\n\n")
+
+		if movie.Resolution != "" {
+			sb.WriteString(fmt.Sprintf("- **Resolution**: %s\n", movie.Resolution))
+		}
+
+		if movie.VideoCodec != "" {
+			sb.WriteString(fmt.Sprintf("- **Video**: %s\n", strings.ToUpper(movie.VideoCodec)))
+		}
+
+		if movie.AudioCodec != "" {
+			audio := strings.ToUpper(movie.AudioCodec)
+			if movie.Channels > 0 {
+				audio += " " + FormatChannels(movie.Channels)
+			}
+			sb.WriteString(fmt.Sprintf("- **Audio**: %s\n", audio))
+		}
+
+		if movie.Duration > 0 {
+			sb.WriteString(fmt.Sprintf("- **Duration**: %s\n", FormatDuration(movie.Duration)))
+		}
+
+		sb.WriteString("\n")
+	}
+
 	// File information section
 	sb.WriteString("## File Information\n\n")
 	sb.WriteString(fmt.Sprintf("- **Location**: `%s`\n", movie.FilePath))
 	sb.WriteString(fmt.Sprintf("- **Filename**: `%s`\n", movie.FileName))
 
 	if movie.FileSize > 0 {
-		sb.WriteString(fmt.Sprintf("- **Size**: %s\n", formatFileSize(movie.FileSize)))
+		sb.WriteString(fmt.Sprintf("- **Size**: %s\n", FormatFileSize(movie.FileSize)))
+	}
+
+	if len(movie.Subtitles) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Subtitles**: %s\n", strings.Join(movie.Subtitles, ", ")))
+	} else {
+		sb.WriteString("- **Subtitles**: None\n")
 	}
 
-	sb.WriteString(fmt.Sprintf("- **Last Scanned**: %s\n", movie.ScannedAt.Format("January 2, 2006")))
+	sb.WriteString(fmt.Sprintf("- **Last Scanned**: %s\n", movie.ScannedAt.Format(w.dateFormat)))
 
 	// Links section
 	if movie.TMDBID > 0 || movie.IMDbID != "" {
@@ -134,24 +318,67 @@ func (w *MDXWriter) GenerateMDX(movie *Movie) (string, error) {
 	return sb.String(), nil
 }
 
+// ReadMDXFile parses the YAML frontmatter of an existing MDX file back into a Movie.
+// Fields tagged yaml:"-" (e.g. image URLs, even when written as posterUrl) are not recovered.
+func ReadMDXFile(path string) (*Movie, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MDX file: %w", err)
+	}
+
+	// Extract YAML frontmatter between --- markers
+	contentStr := string(content)
+	if !strings.HasPrefix(contentStr, "---") {
+		return nil, fmt.Errorf("no frontmatter found in %s", path)
+	}
+	endIndex := strings.Index(contentStr[3:], "\n---")
+	if endIndex == -1 {
+		return nil, fmt.Errorf("frontmatter not properly closed in %s", path)
+	}
+
+	var movie Movie
+	if err := yaml.Unmarshal([]byte(contentStr[3:endIndex+3]), &movie); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter in %s: %w", path, err)
+	}
+
+	return &movie, nil
+}
+
 // GetCoverPath returns the relative path for a cover image
 func (w *MDXWriter) GetCoverPath(slug string) string {
-	return fmt.Sprintf("/covers/%s.jpg", slug)
+	return "/covers/" + w.imageRelPath(slug, slug+".jpg")
 }
 
 // GetBackdropPath returns the relative path for a backdrop image
 func (w *MDXWriter) GetBackdropPath(slug string) string {
-	return fmt.Sprintf("/covers/%s-backdrop.jpg", slug)
+	return "/covers/" + w.imageRelPath(slug, slug+"-backdrop.jpg")
 }
 
 // GetAbsoluteCoverPath returns the absolute file system path for a cover image
 func (w *MDXWriter) GetAbsoluteCoverPath(slug string) string {
-	return filepath.Join(w.coversDir, slug+".jpg")
+	return filepath.Join(w.coversDir, filepath.FromSlash(w.imageRelPath(slug, slug+".jpg")))
 }
 
 // GetAbsoluteBackdropPath returns the absolute file system path for a backdrop image
 func (w *MDXWriter) GetAbsoluteBackdropPath(slug string) string {
-	return filepath.Join(w.coversDir, slug+"-backdrop.jpg")
+	return filepath.Join(w.coversDir, filepath.FromSlash(w.imageRelPath(slug, slug+"-backdrop.jpg")))
+}
+
+// imageRelPath returns fileName's slash-separated path relative to the covers
+// directory, prefixed with the slug's shard directory in the sharded layout
+func (w *MDXWriter) imageRelPath(slug, fileName string) string {
+	if w.coversLayout != CoversLayoutSharded {
+		return fileName
+	}
+	return coverShard(slug) + "/" + fileName
+}
+
+// coverShard returns the shard directory for slug: its first two characters
+func coverShard(slug string) string {
+	if len(slug) < 2 {
+		return slug + "_"
+	}
+	return slug[:2]
 }
 
 // forceQuotedFields sets DoubleQuotedStyle on the named scalar fields inside a
@@ -177,8 +404,36 @@ func forceQuotedFields(doc *yaml.Node, keys ...string) {
 	}
 }
 
-// formatFileSize formats a file size in bytes to a human-readable string
-func formatFileSize(bytes int64) string {
+// appendStringField adds key: value to the top-level mapping of doc (a mapping
+// or a document holding one), unless value is empty
+func appendStringField(doc *yaml.Node, key, value string) {
+	if value == "" {
+		return
+	}
+	mapping := doc
+	if mapping.Kind == yaml.DocumentNode && len(mapping.Content) > 0 {
+		mapping = mapping.Content[0]
+	}
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle},
+	)
+}
+
+// remoteURL returns url if it is an http(s) URL; NFO local image paths are
+// meaningless to the website
+func remoteURL(url string) string {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+	return ""
+}
+
+// FormatFileSize formats a file size in bytes to a human-readable string
+func FormatFileSize(bytes int64) string {
 	const (
 		KB = 1024
 		MB = KB * 1024
@@ -199,3 +454,47 @@ func formatFileSize(bytes int64) string {
 		return fmt.Sprintf("%d bytes", bytes)
 	}
 }
+
+// FormatChannels formats an audio channel count as a layout ("5.1" for 6,
+// "stereo" for 2), or "N channels" for uncommon counts
+func FormatChannels(channels int) string {
+	switch channels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	case 6:
+		return "5.1"
+	case 8:
+		return "7.1"
+	}
+	return fmt.Sprintf("%d channels", channels)
+}
+
+// FormatDuration formats seconds as hours and minutes, e.g. "2h 16m"
+func FormatDuration(seconds int) string {
+	minutes := (seconds + 30) / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// FormatUSD formats a whole-dollar amount with thousands separators, e.g. "$160,000,000"
+func FormatUSD(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	return sign + "$" + sb.String()
+}
@@ -0,0 +1,251 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverPaths_Layouts(t *testing.T) {
+	testCases := []struct {
+		layout           string
+		expectedCover    string
+		expectedBackdrop string
+		expectedAbsCover string
+	}{
+		{CoversLayoutFlat, "/covers/the-matrix-1999.jpg", "/covers/the-matrix-1999-backdrop.jpg", "covers/the-matrix-1999.jpg"},
+		{CoversLayoutSharded, "/covers/th/the-matrix-1999.jpg", "/covers/th/the-matrix-1999-backdrop.jpg", "covers/th/the-matrix-1999.jpg"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.layout, func(t *testing.T) {
+			w := NewMDXWriterWithOptions("movies", "covers", MDXWriterOptions{CoversLayout: tc.layout})
+
+			if got := w.GetCoverPath("the-matrix-1999"); got != tc.expectedCover {
+				t.Errorf("GetCoverPath() = %q, want %q", got, tc.expectedCover)
+			}
+			if got := w.GetBackdropPath("the-matrix-1999"); got != tc.expectedBackdrop {
+				t.Errorf("GetBackdropPath() = %q, want %q", got, tc.expectedBackdrop)
+			}
+			if got := w.GetAbsoluteCoverPath("the-matrix-1999"); got != filepath.FromSlash(tc.expectedAbsCover) {
+				t.Errorf("GetAbsoluteCoverPath() = %q, want %q", got, tc.expectedAbsCover)
+			}
+
+			// The site URL under /covers must resolve to the file on disk
+			abs := w.GetAbsoluteBackdropPath("the-matrix-1999")
+			if filepath.ToSlash(abs) != "covers"+tc.expectedBackdrop[len("/covers"):] {
+				t.Errorf("GetAbsoluteBackdropPath() = %q does not match %q", abs, tc.expectedBackdrop)
+			}
+		})
+	}
+}
+
+func TestGenerateMDX_StoreImageURLs(t *testing.T) {
+	movie := &Movie{
+		Title:       "The Matrix",
+		Slug:        "the-matrix-1999",
+		PosterURL:   "https://image.tmdb.org/t/p/w500/poster.jpg",
+		BackdropURL: "/mnt/media/The Matrix/fanart.jpg",
+	}
+
+	testCases := []struct {
+		store        bool
+		wantPoster   bool
+		wantBackdrop bool
+	}{
+		{false, false, false},
+		{true, true, false}, // local NFO paths are never written
+	}
+
+	for _, tc := range testCases {
+		w := NewMDXWriterWithOptions("movies", "covers", MDXWriterOptions{StoreImageURLs: tc.store})
+		content, err := w.GenerateMDX(movie)
+		if err != nil {
+			t.Fatalf("GenerateMDX() error: %v", err)
+		}
+		if got := strings.Contains(content, `posterUrl: "https://image.tmdb.org/t/p/w500/poster.jpg"`); got != tc.wantPoster {
+			t.Errorf("store=%v: posterUrl present = %v, want %v", tc.store, got, tc.wantPoster)
+		}
+		if got := strings.Contains(content, "backdropUrl:"); got != tc.wantBackdrop {
+			t.Errorf("store=%v: backdropUrl present = %v, want %v", tc.store, got, tc.wantBackdrop)
+		}
+	}
+}
+
+func TestWriteMDXFile_JSONSidecar(t *testing.T) {
+	dir := t.TempDir()
+	w := NewMDXWriterWithOptions(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"), MDXWriterOptions{JSONDir: filepath.Join(dir, "json")})
+	movie := &Movie{
+		Title:       "The Matrix",
+		Slug:        "the-matrix-1999",
+		ReleaseYear: 1999,
+		Genres:      []string{"Action", "Science Fiction"},
+		PosterURL:   "https://image.tmdb.org/t/p/w500/poster.jpg",
+		Locked:      true,
+	}
+	if err := w.WriteMDXFile(movie); err != nil {
+		t.Fatalf("WriteMDXFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(w.GetJSONPath(movie.Slug))
+	if err != nil {
+		t.Fatalf("sidecar not written: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("sidecar is not valid JSON: %v", err)
+	}
+	if fields["title"] != "The Matrix" || fields["slug"] != "the-matrix-1999" || fields["posterUrl"] != movie.PosterURL {
+		t.Errorf("sidecar fields = %v", fields)
+	}
+	if _, ok := fields["Locked"]; ok {
+		t.Error("sidecar includes internal Locked field")
+	}
+	if filepath.Dir(w.GetJSONPath(movie.Slug)) == filepath.Dir(w.GetMDXPath(movie.Slug)) {
+		t.Error("sidecar written into the MDX directory")
+	}
+
+	if err := w.RemoveMDX(movie.Slug); err != nil {
+		t.Fatalf("RemoveMDX failed: %v", err)
+	}
+	for _, path := range []string{w.GetMDXPath(movie.Slug), w.GetJSONPath(movie.Slug)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after RemoveMDX", filepath.Base(path))
+		}
+	}
+}
+
+func TestWriteMDXFile_SortTitle(t *testing.T) {
+	dir := t.TempDir()
+	movie := &Movie{Title: "The Matrix", Slug: "the-matrix-1999", ReleaseYear: 1999}
+
+	w := NewMDXWriterWithOptions(dir, filepath.Join(dir, "covers"), MDXWriterOptions{SortArticles: []string{"The"}})
+	if err := w.WriteMDXFile(movie); err != nil {
+		t.Fatalf("WriteMDXFile failed: %v", err)
+	}
+	data, err := os.ReadFile(w.GetMDXPath(movie.Slug))
+	if err != nil {
+		t.Fatalf("MDX not written: %v", err)
+	}
+	if !strings.Contains(string(data), "sortTitle: Matrix, The\n") {
+		t.Errorf("MDX is missing sortTitle:\n%s", data)
+	}
+
+	// Without the option, a sortTitle read from an old MDX is dropped
+	w = NewMDXWriter(dir, filepath.Join(dir, "covers"))
+	if err := w.WriteMDXFile(movie); err != nil {
+		t.Fatalf("WriteMDXFile failed: %v", err)
+	}
+	data, _ = os.ReadFile(w.GetMDXPath(movie.Slug))
+	if strings.Contains(string(data), "sortTitle") {
+		t.Errorf("sortTitle written with the option disabled:\n%s", data)
+	}
+}
+
+func TestFormatUSD(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		expected string
+	}{
+		{0, "$0"},
+		{999, "$999"},
+		{1000, "$1,000"},
+		{160000000, "$160,000,000"},
+		{2923706026, "$2,923,706,026"},
+		{-1500, "-$1,500"},
+	}
+
+	for _, tc := range testCases {
+		if got := FormatUSD(tc.amount); got != tc.expected {
+			t.Errorf("FormatUSD(%d) = %q, want %q", tc.amount, got, tc.expected)
+		}
+	}
+}
+
+func TestGenerateMDX_Financials(t *testing.T) {
+	w := NewMDXWriter("movies", "covers")
+
+	content, err := w.GenerateMDX(&Movie{Title: "Inception", Slug: "inception-2010", Budget: 160000000, Revenue: 839030630})
+	if err != nil {
+		t.Fatalf("GenerateMDX() error: %v", err)
+	}
+	for _, want := range []string{"budget: 160000000\n", "revenue: 839030630\n", "- **Budget**: $160,000,000\n", "- **Revenue**: $839,030,630\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("MDX missing %q:\n%s", want, content)
+		}
+	}
+
+	content, err = w.GenerateMDX(&Movie{Title: "Heat", Slug: "heat-1995"})
+	if err != nil {
+		t.Fatalf("GenerateMDX() error: %v", err)
+	}
+	if strings.Contains(content, "budget") || strings.Contains(content, "Revenue") {
+		t.Errorf("zero financials written:\n%s", content)
+	}
+}
+
+func TestGenerateMDX_Technical(t *testing.T) {
+	w := NewMDXWriter("movies", "covers")
+
+	content, err := w.GenerateMDX(&Movie{
+		Title:      "Dune",
+		Slug:       "dune-2021",
+		Resolution: "2160p",
+		VideoCodec: "hevc",
+		AudioCodec: "truehd",
+		Channels:   8,
+		Duration:   9333,
+	})
+	if err != nil {
+		t.Fatalf("GenerateMDX() error: %v", err)
+	}
+	for _, want := range []string{
+		"resolution: 2160p\n", "videoCodec: hevc\n", "channels: 8\n", "duration: 9333\n",
+		"## Technical\n", "- **Resolution**: 2160p\n", "- **Video**: HEVC\n", "- **Audio**: TRUEHD 7.1\n", "- **Duration**: 2h 36m\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("MDX missing %q:\n%s", want, content)
+		}
+	}
+
+	content, err = w.GenerateMDX(&Movie{Title: "Heat", Slug: "heat-1995"})
+	if err != nil {
+		t.Fatalf("GenerateMDX() error: %v", err)
+	}
+	if strings.Contains(content, "Technical") || strings.Contains(content, "resolution") {
+		t.Errorf("empty technical fields written:\n%s", content)
+	}
+}
+
+func TestWriteMDXFile_NeverOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	w := NewMDXWriterWithOptions(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"), MDXWriterOptions{
+		NeverOverwrite: true,
+		JSONDir:        filepath.Join(dir, "json"),
+	})
+	if err := w.WriteMDXFile(&Movie{Title: "Heat", Slug: "heat-1995", ReleaseYear: 1995}); err != nil {
+		t.Fatalf("first WriteMDXFile failed: %v", err)
+	}
+	mdxBefore, err := os.ReadFile(w.GetMDXPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBefore, err := os.ReadFile(w.GetJSONPath("heat-1995"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.WriteMDXFile(&Movie{Title: "Heat (Director's Definitive Edition)", Slug: "heat-1995", ReleaseYear: 1995})
+	if !errors.Is(err, ErrMDXExists) {
+		t.Fatalf("second WriteMDXFile error = %v, want ErrMDXExists", err)
+	}
+	for path, before := range map[string][]byte{w.GetMDXPath("heat-1995"): mdxBefore, w.GetJSONPath("heat-1995"): jsonBefore} {
+		if after, err := os.ReadFile(path); err != nil || !bytes.Equal(after, before) {
+			t.Errorf("%s changed by a refused write (err: %v)", filepath.Base(path), err)
+		}
+	}
+}
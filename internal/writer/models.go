@@ -1,31 +1,127 @@
 package writer
 
 import (
+	"math"
+	"strings"
 	"time"
 )
 
+// maxRuntimeMinutes is the longest runtime accepted as real; larger values are source errors
+const maxRuntimeMinutes = 1000
+
 // Movie represents a movie with all its metadata
 type Movie struct {
-	Title         string    `yaml:"title"`
-	Slug          string    `yaml:"slug"`
-	Description   string    `yaml:"description"`
-	CoverImage    string    `yaml:"coverImage"`
-	BackdropImage string    `yaml:"backdropImage"`
-	FilePath      string    `yaml:"filePath"`
-	FileName      string    `yaml:"fileName"`
-	SourceDir     string    `yaml:"sourceDir,omitempty"`
-	Rating        float64   `yaml:"rating"`
-	ReleaseYear   int       `yaml:"releaseYear"`
-	ReleaseDate   string    `yaml:"releaseDate"`
-	Runtime       int       `yaml:"runtime"`
-	Genres        []string  `yaml:"genres"`
-	Director      string    `yaml:"director"`
-	Cast          []string  `yaml:"cast"`
-	TMDBID        int       `yaml:"tmdbId"`
-	IMDbID        string    `yaml:"imdbId,omitempty"`
-	ScannedAt     time.Time `yaml:"scannedAt"`
-	FileSize      int64     `yaml:"fileSize"`
-	// NFO image URLs (US-018) - used for NFO-based image downloads
-	PosterURL   string `yaml:"-"` // Not persisted to MDX, used during processing
-	BackdropURL string `yaml:"-"` // Not persisted to MDX, used during processing
+	Title          string    `yaml:"title" json:"title"`
+	SortTitle      string    `yaml:"sortTitle,omitempty" json:"sortTitle,omitempty"` // Title with a leading article moved to the end, when enabled
+	Slug           string    `yaml:"slug" json:"slug"`
+	Description    string    `yaml:"description" json:"description"`
+	CoverImage     string    `yaml:"coverImage" json:"coverImage"`
+	BackdropImage  string    `yaml:"backdropImage" json:"backdropImage"`
+	FilePath       string    `yaml:"filePath" json:"filePath"`
+	FileName       string    `yaml:"fileName" json:"fileName"`
+	SourceDir      string    `yaml:"sourceDir,omitempty" json:"sourceDir,omitempty"`
+	Rating         float64   `yaml:"rating" json:"rating"`
+	IMDbRating     float64   `yaml:"imdbRating,omitempty" json:"imdbRating,omitempty"`         // From OMDb, when enabled
+	RottenTomatoes int       `yaml:"rottenTomatoes,omitempty" json:"rottenTomatoes,omitempty"` // Tomatometer percent from OMDb, when enabled
+	ReleaseYear    int       `yaml:"releaseYear" json:"releaseYear"`
+	ReleaseDate    string    `yaml:"releaseDate" json:"releaseDate"`
+	Runtime        int       `yaml:"runtime" json:"runtime"`
+	Budget         int64     `yaml:"budget,omitempty" json:"budget,omitempty"`   // USD from TMDB, when options.include_financials is enabled
+	Revenue        int64     `yaml:"revenue,omitempty" json:"revenue,omitempty"` // USD from TMDB, when options.include_financials is enabled
+	Genres         []string  `yaml:"genres" json:"genres"`
+	Director       string    `yaml:"director" json:"director"`
+	Cast           []string  `yaml:"cast" json:"cast"`
+	Studios        []string  `yaml:"studios,omitempty" json:"studios,omitempty"`     // Production companies from TMDB
+	Countries      []string  `yaml:"countries,omitempty" json:"countries,omitempty"` // Production countries from TMDB
+	TMDBID         int       `yaml:"tmdbId" json:"tmdbId"`
+	IMDbID         string    `yaml:"imdbId,omitempty" json:"imdbId,omitempty"`
+	ScannedAt      time.Time `yaml:"scannedAt" json:"scannedAt"`
+	FileSize       int64     `yaml:"fileSize" json:"fileSize"`
+	FileHash       string    `yaml:"fileHash,omitempty" json:"fileHash,omitempty"`             // Fingerprint of the video (size, first and last 64KB), when options.compute_file_hash is enabled
+	Resolution     string    `yaml:"resolution,omitempty" json:"resolution,omitempty"`         // e.g. "1080p"; from ffprobe, or the filename, when options.use_ffprobe is enabled
+	VideoCodec     string    `yaml:"videoCodec,omitempty" json:"videoCodec,omitempty"`         // ffprobe codec name, e.g. "hevc"
+	AudioCodec     string    `yaml:"audioCodec,omitempty" json:"audioCodec,omitempty"`         // ffprobe codec name of the first audio stream, e.g. "truehd"
+	Channels       int       `yaml:"channels,omitempty" json:"channels,omitempty"`             // Audio channels of the first audio stream, e.g. 6 for 5.1
+	Duration       int       `yaml:"duration,omitempty" json:"duration,omitempty"`             // Actual playing time of the file in seconds
+	Subtitles      []string  `yaml:"subtitles,omitempty" json:"subtitles,omitempty"`           // Languages of external subtitle files
+	Keywords       []string  `yaml:"keywords,omitempty" json:"keywords,omitempty"`             // TMDB keywords, when options.fetch_keywords is enabled
+	Collection     string    `yaml:"collection,omitempty" json:"collection,omitempty"`         // Franchise name from the NFO <set> or TMDB belongs_to_collection
+	CollectionSlug string    `yaml:"collectionSlug,omitempty" json:"collectionSlug,omitempty"` // Slug of Collection, shared by every movie in the franchise
+	Unmatched      bool      `yaml:"unmatched,omitempty" json:"unmatched,omitempty"`           // Placeholder written when no metadata was found
+	LowConfidence  bool      `yaml:"lowConfidence,omitempty" json:"lowConfidence,omitempty"`   // TMDB match looks unlike the filename title or year
+	NFOHash        string    `yaml:"nfoHash,omitempty" json:"-"`                               // SHA-256 of the .nfo the metadata came from, for change detection
+	// Remote image URLs from the NFO (US-018) or TMDB - used for NFO-based image
+	// downloads, and written as posterUrl/backdropUrl when the MDX writer stores image URLs
+	PosterURL   string `yaml:"-" json:"posterUrl,omitempty"`
+	BackdropURL string `yaml:"-" json:"backdropUrl,omitempty"`
+	Locked      bool   `yaml:"-" json:"-"` // NFO has <lockdata>; TMDB must not fill or override its fields
+}
+
+// NormalizeRating rounds a rating to one decimal so the frontmatter matches the
+// rendered body and float noise (e.g. 7.1999998) doesn't churn diffs across scans
+func NormalizeRating(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
+
+// NormalizeRuntime returns minutes, or 0 (unknown) for implausible runtimes
+// of zero, negative, or more than 1000 minutes
+func NormalizeRuntime(minutes int) int {
+	if minutes <= 0 || minutes > maxRuntimeMinutes {
+		return 0
+	}
+	return minutes
+}
+
+// NormalizeGenres renames genres via aliases (matched case-insensitively; an
+// empty alias drops the genre), removes duplicates the renaming creates, and
+// keeps at most max genres (0 = no limit). Source order is preserved.
+func NormalizeGenres(genres []string, aliases map[string]string, max int) []string {
+	if len(aliases) == 0 && (max <= 0 || len(genres) <= max) {
+		return genres
+	}
+
+	lookup := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		lookup[strings.ToLower(strings.TrimSpace(from))] = strings.TrimSpace(to)
+	}
+
+	result := make([]string, 0, len(genres))
+	seen := make(map[string]bool, len(genres))
+	for _, genre := range genres {
+		if alias, ok := lookup[strings.ToLower(strings.TrimSpace(genre))]; ok {
+			genre = alias
+		}
+		key := strings.ToLower(genre)
+		if genre == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, genre)
+		if max > 0 && len(result) == max {
+			break
+		}
+	}
+	return result
+}
+
+// SortTitle returns title with a leading article moved to the end for
+// alphabetical sorting ("The Matrix" becomes "Matrix, The"). Articles match
+// case-insensitively and must be followed by a space, except ones ending in an
+// apostrophe ("L'Avventura" becomes "Avventura, L'"). A title with no leading
+// article, or nothing after it, is returned unchanged.
+func SortTitle(title string, articles []string) string {
+	for _, article := range articles {
+		article = strings.TrimSpace(article)
+		if article == "" || len(title) <= len(article) || !strings.EqualFold(title[:len(article)], article) {
+			continue
+		}
+		rest := title[len(article):]
+		if !strings.HasSuffix(article, "'") && rest[0] != ' ' {
+			continue
+		}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return rest + ", " + title[:len(article)]
+		}
+	}
+	return title
 }
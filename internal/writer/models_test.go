@@ -0,0 +1,62 @@
+package writer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeGenres(t *testing.T) {
+	aliases := map[string]string{
+		"Science Fiction":  "Sci-Fi",
+		"sci-fi & fantasy": "Sci-Fi",
+		"TV Movie":         "",
+	}
+
+	testCases := []struct {
+		name    string
+		genres  []string
+		aliases map[string]string
+		max     int
+		want    []string
+	}{
+		{"unchanged", []string{"Drama", "Crime"}, nil, 0, []string{"Drama", "Crime"}},
+		{"alias", []string{"Science Fiction", "Action"}, aliases, 0, []string{"Sci-Fi", "Action"}},
+		{"case insensitive", []string{"science fiction"}, aliases, 0, []string{"Sci-Fi"}},
+		{"merged duplicates", []string{"Science Fiction", "Sci-Fi & Fantasy", "Drama"}, aliases, 0, []string{"Sci-Fi", "Drama"}},
+		{"dropped", []string{"TV Movie", "Comedy"}, aliases, 0, []string{"Comedy"}},
+		{"max", []string{"Action", "Adventure", "Thriller"}, nil, 2, []string{"Action", "Adventure"}},
+		{"max after aliases", []string{"TV Movie", "Science Fiction", "Action", "Drama"}, aliases, 2, []string{"Sci-Fi", "Action"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NormalizeGenres(tc.genres, tc.aliases, tc.max)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("NormalizeGenres(%v) = %v, want %v", tc.genres, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSortTitle(t *testing.T) {
+	articles := []string{"The", "A", "An", "L'"}
+
+	testCases := []struct {
+		title string
+		want  string
+	}{
+		{"The Matrix", "Matrix, The"},
+		{"A Bug's Life", "Bug's Life, A"},
+		{"an American Tail", "American Tail, an"},
+		{"L'Avventura", "Avventura, L'"},
+		{"Theodora Goes Wild", "Theodora Goes Wild"},
+		{"Heat", "Heat"},
+		{"The", "The"},
+	}
+
+	for _, tc := range testCases {
+		if got := SortTitle(tc.title, articles); got != tc.want {
+			t.Errorf("SortTitle(%q) = %q, want %q", tc.title, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Taxonomy file names written by WriteTaxonomy
+const (
+	GenresTaxonomyFile    = "genres.json"
+	DirectorsTaxonomyFile = "directors.json"
+)
+
+// TaxonomyEntry is one facet value (a genre or director) with the movies that have it
+type TaxonomyEntry struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Slugs []string `json:"slugs"`
+}
+
+// BuildTaxonomy groups movies by the values returned by keys, sorted by name.
+// Slugs within an entry are sorted and listed once per movie.
+func BuildTaxonomy(movies []*Movie, keys func(*Movie) []string) []TaxonomyEntry {
+	bySlug := make(map[string]map[string]bool)
+	for _, movie := range movies {
+		for _, key := range keys(movie) {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if bySlug[key] == nil {
+				bySlug[key] = make(map[string]bool)
+			}
+			bySlug[key][movie.Slug] = true
+		}
+	}
+
+	entries := make([]TaxonomyEntry, 0, len(bySlug))
+	for name, slugSet := range bySlug {
+		slugs := make([]string, 0, len(slugSet))
+		for slug := range slugSet {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+		entries = append(entries, TaxonomyEntry{Name: name, Count: len(slugs), Slugs: slugs})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// movieGenres returns a movie's genres
+func movieGenres(movie *Movie) []string {
+	return movie.Genres
+}
+
+// movieDirectors splits a movie's comma-joined director field
+func movieDirectors(movie *Movie) []string {
+	return strings.Split(movie.Director, ",")
+}
+
+// WriteTaxonomy reads every MDX file in mdxDir and writes genres.json and
+// directors.json to taxonomyDir, so the website can build facet pages without
+// parsing each MDX file. Unreadable MDX files are skipped.
+// Returns the number of movies included
+func WriteTaxonomy(mdxDir, taxonomyDir string) (int, error) {
+	mdxFiles, err := filepath.Glob(filepath.Join(mdxDir, "*.mdx"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list MDX files: %w", err)
+	}
+
+	var movies []*Movie
+	for _, mdxPath := range mdxFiles {
+		movie, err := ReadMDXFile(mdxPath)
+		if err != nil {
+			continue
+		}
+		if movie.Slug == "" {
+			movie.Slug = strings.TrimSuffix(filepath.Base(mdxPath), ".mdx")
+		}
+		movies = append(movies, movie)
+	}
+
+	if err := os.MkdirAll(taxonomyDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create taxonomy directory: %w", err)
+	}
+
+	for fileName, keys := range map[string]func(*Movie) []string{
+		GenresTaxonomyFile:    movieGenres,
+		DirectorsTaxonomyFile: movieDirectors,
+	} {
+		if err := writeJSONFile(filepath.Join(taxonomyDir, fileName), BuildTaxonomy(movies, keys)); err != nil {
+			return 0, err
+		}
+	}
+	return len(movies), nil
+}
+
+// writeJSONFile writes v as indented JSON, replacing path atomically so the
+// website never reads a partially written file
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	if err := WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package writer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteTaxonomy(t *testing.T) {
+	dir := t.TempDir()
+	w := NewMDXWriter(filepath.Join(dir, "movies"), filepath.Join(dir, "covers"))
+	for _, movie := range []*Movie{
+		{Title: "Heat", Slug: "heat-1995", Genres: []string{"Crime", "Drama"}, Director: "Michael Mann"},
+		{Title: "Collateral", Slug: "collateral-2004", Genres: []string{"Crime"}, Director: "Michael Mann"},
+		{Title: "The Matrix", Slug: "the-matrix-1999", Genres: []string{"Action"}, Director: "Lana Wachowski, Lilly Wachowski"},
+	} {
+		if err := w.WriteMDXFile(movie); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taxonomyDir := filepath.Join(dir, "taxonomy")
+	count, err := WriteTaxonomy(filepath.Join(dir, "movies"), taxonomyDir)
+	if err != nil || count != 3 {
+		t.Fatalf("WriteTaxonomy() = %d, %v; want 3, nil", count, err)
+	}
+
+	testCases := []struct {
+		file string
+		want []TaxonomyEntry
+	}{
+		{GenresTaxonomyFile, []TaxonomyEntry{
+			{Name: "Action", Count: 1, Slugs: []string{"the-matrix-1999"}},
+			{Name: "Crime", Count: 2, Slugs: []string{"collateral-2004", "heat-1995"}},
+			{Name: "Drama", Count: 1, Slugs: []string{"heat-1995"}},
+		}},
+		{DirectorsTaxonomyFile, []TaxonomyEntry{
+			{Name: "Lana Wachowski", Count: 1, Slugs: []string{"the-matrix-1999"}},
+			{Name: "Lilly Wachowski", Count: 1, Slugs: []string{"the-matrix-1999"}},
+			{Name: "Michael Mann", Count: 2, Slugs: []string{"collateral-2004", "heat-1995"}},
+		}},
+	}
+
+	for _, tc := range testCases {
+		data, err := os.ReadFile(filepath.Join(taxonomyDir, tc.file))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", tc.file, err)
+		}
+		var got []TaxonomyEntry
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("failed to decode %s: %v", tc.file, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s = %+v, want %+v", tc.file, got, tc.want)
+		}
+	}
+}
@@ -4,22 +4,44 @@ const moviesCollection = defineCollection({
   type: 'content',
   schema: z.object({
     title: z.string(),
+    sortTitle: z.string().optional(),
     description: z.string(),
     coverImage: z.string(),
     backdropImage: z.string().optional(),
+    posterUrl: z.string().optional(),
+    backdropUrl: z.string().optional(),
     filePath: z.preprocess((v) => (typeof v === 'string' ? v : ''), z.string()),
     fileName: z.preprocess((v) => (typeof v === 'string' ? v : ''), z.string()),
     rating: z.number(),
+    imdbRating: z.number().optional(),
+    rottenTomatoes: z.number().optional(),
     releaseYear: z.number(),
     releaseDate: z.string(),
     runtime: z.number(),
+    budget: z.number().optional(),
+    revenue: z.number().optional(),
     genres: z.array(z.string()),
     director: z.string(),
     cast: z.array(z.string()),
+    studios: z.array(z.string()).optional(),
+    countries: z.array(z.string()).optional(),
     tmdbId: z.number(),
     imdbId: z.string().optional(),
     scannedAt: z.coerce.date(),
+    unmatched: z.boolean().optional(),
+    lowConfidence: z.boolean().optional(),
+    nfoHash: z.string().optional(),
     fileSize: z.number(),
+    fileHash: z.string().optional(),
+    resolution: z.string().optional(),
+    videoCodec: z.string().optional(),
+    audioCodec: z.string().optional(),
+    channels: z.number().optional(),
+    duration: z.number().optional(),
+    subtitles: z.array(z.string()).optional(),
+    keywords: z.array(z.string()).optional(),
+    collection: z.string().optional(),
+    collectionSlug: z.string().optional(),
     sourceDir: z.string().optional(),
   }),
 });